@@ -44,6 +44,8 @@ x86_64-w64-mingw32-windres -o main-res.syso $HACKED_BASE/_build/win_temp/hacked.
 echo "Building executables..."
 go build -gcflags "-d=checkptr=0" -ldflags "-X main.version=$VERSION" -a -o $HACKED_BASE/_build/linux/$FOLDER_NAME/hacked -trimpath $(pwd) .
 GOOS=windows GOARCH=amd64 CGO_ENABLED=1 CXX=x86_64-w64-mingw32-g++ CC=x86_64-w64-mingw32-gcc go build -gcflags "-d=checkptr=0" -ldflags "-X main.version=$VERSION -H=windowsgui" -a -o $HACKED_BASE/_build/win/$FOLDER_NAME/hacked.exe -trimpath $(pwd) .
+go build -ldflags "-X main.version=$VERSION" -o $HACKED_BASE/_build/linux/$FOLDER_NAME/hacked-cli -trimpath ./cmd/hacked-cli
+GOOS=windows GOARCH=amd64 CGO_ENABLED=0 go build -ldflags "-X main.version=$VERSION" -o $HACKED_BASE/_build/win/$FOLDER_NAME/hacked-cli.exe -trimpath ./cmd/hacked-cli
 
 echo "Copying distribution resources..."
 
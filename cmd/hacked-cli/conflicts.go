@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+)
+
+const (
+	errConflicting ss1.StringError = "mods are conflicting"
+	errMissingBase ss1.StringError = "missing base, the world the mods are based on is required"
+)
+
+func runConflicts(args []string) error {
+	flags := newFlagSet("conflicts", "-base <world-dir> <mod-dir> <mod-dir>")
+	baseDir := flags.String("base", "", "directory of the world the mods are based on, such as the data directory of the game")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errMissingArgument
+	}
+	if len(*baseDir) == 0 {
+		flags.Usage()
+		return errMissingBase
+	}
+	base, err := loadConflictBase(*baseDir)
+	if err != nil {
+		return err
+	}
+	first := world.LoadFiles(false, []string{flags.Arg(0)})
+	second := world.LoadFiles(false, []string{flags.Arg(1)})
+	conflicts := world.FindModConflicts(base, first, second)
+	printConflicts(os.Stdout, conflicts)
+	if !conflicts.IsEmpty() {
+		return errConflicting
+	}
+	return nil
+}
+
+// loadConflictBase loads the world from given directory, against which the changes of the mods are determined.
+func loadConflictBase(dir string) (*world.Manifest, error) {
+	entry, err := world.NewManifestEntryFrom([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+	manifest := world.NewManifest(func([]resource.ID, []resource.ID) {})
+	err = manifest.InsertEntry(0, entry)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func printConflicts(out io.Writer, conflicts world.ModConflicts) {
+	for _, res := range conflicts.Identical {
+		_, _ = fmt.Fprintf(out, "identical resource %v [%v]%s: blocks %v\n", res.ID, res.Language, describedAs(res.ID), res.Blocks)
+	}
+	for _, res := range conflicts.Resources {
+		_, _ = fmt.Fprintf(out, "resource %v [%v]%s: blocks %v\n", res.ID, res.Language, describedAs(res.ID), res.Blocks)
+	}
+	for _, lvl := range conflicts.Levels {
+		for _, pos := range lvl.Tiles {
+			_, _ = fmt.Fprintf(out, "level %d: tile %d/%d\n", lvl.Level, pos.X, pos.Y)
+		}
+		for _, id := range lvl.Objects {
+			_, _ = fmt.Fprintf(out, "level %d: object %d\n", lvl.Level, id)
+		}
+	}
+	for _, triple := range conflicts.ObjectProperties {
+		_, _ = fmt.Fprintf(out, "object properties %v\n", triple)
+	}
+	for _, index := range conflicts.TextureProperties {
+		_, _ = fmt.Fprintf(out, "texture properties %d\n", index)
+	}
+	_, _ = fmt.Fprintf(out, "%d conflicting resources, %d object properties, %d texture properties; %d identical resources\n",
+		len(conflicts.Resources), len(conflicts.ObjectProperties), len(conflicts.TextureProperties), len(conflicts.Identical))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/diff"
+)
+
+func runDiff(args []string) error {
+	flags := newFlagSet("diff", "<old.res> <new.res>")
+	maxRanges := flags.Int("ranges", 8, "maximum number of byte ranges to list per block")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errMissingArgument
+	}
+	from, err := openResources(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := openResources(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	report, err := diff.Resources(from, to)
+	if err != nil {
+		return err
+	}
+	printReport(os.Stdout, report, *maxRanges)
+	return nil
+}
+
+func printReport(out io.Writer, report diff.Report, maxRanges int) {
+	for _, id := range report.Added {
+		_, _ = fmt.Fprintf(out, "+ %v%s\n", id, describedAs(id))
+	}
+	for _, id := range report.Removed {
+		_, _ = fmt.Fprintf(out, "- %v%s\n", id, describedAs(id))
+	}
+	for _, change := range report.Changed {
+		_, _ = fmt.Fprintf(out, "~ %v%s\n", change.ID, describedAs(change.ID))
+		if change.CompoundChanged() {
+			_, _ = fmt.Fprintf(out, "    compound: %s -> %s\n", yesNo(change.From.Compound), yesNo(change.To.Compound))
+		}
+		if change.ContentTypeChanged() {
+			_, _ = fmt.Fprintf(out, "    content type: %v -> %v\n", change.From.ContentType, change.To.ContentType)
+		}
+		if change.CompressionChanged() {
+			_, _ = fmt.Fprintf(out, "    compressed: %s -> %s\n", yesNo(change.From.Compressed), yesNo(change.To.Compressed))
+		}
+		if change.FromBlockCount != change.ToBlockCount {
+			_, _ = fmt.Fprintf(out, "    blocks: %d -> %d\n", change.FromBlockCount, change.ToBlockCount)
+		}
+		for _, block := range change.Blocks {
+			printBlockChange(out, block, maxRanges)
+		}
+	}
+	_, _ = fmt.Fprintf(out, "%d added, %d removed, %d changed\n", len(report.Added), len(report.Removed), len(report.Changed))
+}
+
+func printBlockChange(out io.Writer, block diff.BlockChange, maxRanges int) {
+	switch {
+	case block.Added():
+		_, _ = fmt.Fprintf(out, "    [%d] added, %d bytes\n", block.Index, block.ToLength)
+	case block.Removed():
+		_, _ = fmt.Fprintf(out, "    [%d] removed, %d bytes\n", block.Index, block.FromLength)
+	default:
+		ranges := make([]string, 0, len(block.Ranges))
+		for index, r := range block.Ranges {
+			if (maxRanges >= 0) && (index >= maxRanges) {
+				ranges = append(ranges, "...")
+				break
+			}
+			ranges = append(ranges, fmt.Sprintf("%04X-%04X", r.Start, r.End))
+		}
+		_, _ = fmt.Fprintf(out, "    [%d] %d -> %d bytes, %d bytes in %d range(s) differ: %s\n",
+			block.Index, block.FromLength, block.ToLength, block.DifferingBytes(), len(block.Ranges), strings.Join(ranges, " "))
+	}
+}
+
+func describedAs(id resource.ID) string {
+	description := diff.Describe(id)
+	if len(description) == 0 {
+		return ""
+	}
+	return " (" + description + ")"
+}
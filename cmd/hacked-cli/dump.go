@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/content/preview"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/serial"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+const errUnreadableBlocks ss1.StringError = "could not be read, no files were written for them"
+
+func runDump(args []string) error {
+	flags := newFlagSet("dump", "<file.res> [ID[:block] ...]")
+	outDir := flags.String("o", ".", "directory to write the files into")
+	paletteFile := flags.String("palette", "", "resource file with the game palette (gamepal.res), used for bitmaps")
+	codepageFile := flags.String("codepage", "", "codepage table file for texts, instead of the default codepage")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errMissingArgument
+	}
+	viewer, err := openResources(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var palette *bitmap.Palette
+	if len(*paletteFile) > 0 {
+		palette, err = loadGamePalette(*paletteFile)
+		if err != nil {
+			return fmt.Errorf("%v: %w", *paletteFile, err)
+		}
+	}
+	cp := text.DefaultCodepage()
+	if len(*codepageFile) > 0 {
+		cp, err = loadCodepage(*codepageFile)
+		if err != nil {
+			return fmt.Errorf("%v: %w", *codepageFile, err)
+		}
+	}
+	registry := preview.DefaultRegistry(cp, palette)
+	err = os.MkdirAll(*outDir, 0750)
+	if err != nil {
+		return err
+	}
+
+	skipped := 0
+	if flags.NArg() == 1 {
+		for _, id := range viewer.IDs() {
+			blocksSkipped, err := dumpBlocks(registry, *outDir, viewer, id, -1)
+			if err != nil {
+				return err
+			}
+			skipped += blocksSkipped
+		}
+	}
+	for _, ref := range flags.Args()[1:] {
+		id, blockIndex, refErr := parseBlockRef(ref)
+		if refErr != nil {
+			return fmt.Errorf("%v: %w", ref, refErr)
+		}
+		blocksSkipped, err := dumpBlocks(registry, *outDir, viewer, id, blockIndex)
+		if err != nil {
+			return err
+		}
+		skipped += blocksSkipped
+	}
+	if skipped > 0 {
+		return fmt.Errorf("%d blocks: %w", skipped, errUnreadableBlocks)
+	}
+	return nil
+}
+
+// dumpBlocks writes the previews of the resource into files. A negative index dumps all blocks.
+// Blocks that can not be decoded are written as binary files, after reporting the error.
+// Blocks that can not be read are skipped, after reporting the error. Their number is returned.
+func dumpBlocks(registry *preview.Registry, dir string, viewer resource.Viewer, id resource.ID, index int) (int, error) {
+	view, err := viewer.View(id)
+	if err != nil {
+		return 0, err
+	}
+	from, to := index, index+1
+	if index < 0 {
+		from, to = 0, view.BlockCount()
+	}
+	skipped := 0
+	for blockIndex := from; blockIndex < to; blockIndex++ {
+		data, readErr := readBlock(view, blockIndex)
+		if readErr != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v:%d: %v\n", id, blockIndex, readErr)
+			skipped++
+			continue
+		}
+		blockPreview, decodeErr := registry.DecodeData(id, view.ContentType(), data)
+		if decodeErr != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v:%d: %v\n", id, blockIndex, decodeErr)
+		}
+		err = writePreview(filepath.Join(dir, fmt.Sprintf("%v-%04d%s", id, blockIndex, blockPreview.Kind.FileExtension())), blockPreview)
+		if err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+func readBlock(view resource.View, index int) ([]byte, error) {
+	reader, err := view.Block(index)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(reader)
+}
+
+func writePreview(filename string, blockPreview preview.Preview) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = blockPreview.Export(file)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func loadGamePalette(filename string) (*bitmap.Palette, error) {
+	viewer, err := openResources(filename)
+	if err != nil {
+		return nil, err
+	}
+	view, err := viewer.View(ids.GamePalettesStart)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := view.Block(0)
+	if err != nil {
+		return nil, err
+	}
+	var palette bitmap.Palette
+	decoder := serial.NewDecoder(reader)
+	decoder.Code(&palette)
+	return &palette, decoder.FirstError()
+}
+
+func loadCodepage(filename string) (text.Codepage, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return text.LoadCodepage(file)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/unpacked"
+)
+
+func runExtract(args []string) error {
+	flags := newFlagSet("extract", "<file.res> [ID[:block] ...]")
+	outDir := flags.String("o", ".", "directory to extract into")
+	salvage := flags.Bool("salvage", false, "extract only the intact resources of a damaged file")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errMissingArgument
+	}
+	openFile := openResourceFile
+	if *salvage {
+		openFile = salvageResourceFile
+	}
+	reader, err := openFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 1 {
+		return unpacked.Write(*outDir, reader)
+	}
+
+	var selected resource.Store
+	for _, ref := range flags.Args()[1:] {
+		id, blockIndex, refErr := parseBlockRef(ref)
+		if refErr != nil {
+			return fmt.Errorf("%v: %w", ref, refErr)
+		}
+		view, viewErr := reader.View(id)
+		if viewErr != nil {
+			return viewErr
+		}
+		if blockIndex < 0 {
+			err = selected.Put(id, view)
+		} else {
+			err = extractBlock(*outDir, id, blockIndex, view)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(selected.IDs()) > 0 {
+		return unpacked.Write(*outDir, selected)
+	}
+	return nil
+}
+
+func extractBlock(dir string, id resource.ID, index int, view resource.View) error {
+	blockReader, err := view.Block(index)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(blockReader)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0750)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%v-%04d.bin", id, index)), data, 0640)
+}
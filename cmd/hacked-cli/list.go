@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+)
+
+func runList(args []string) error {
+	flags := newFlagSet("list", "<file.res>")
+	showBlocks := flags.Bool("blocks", false, "also list the size of each block")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errMissingArgument
+	}
+	reader, err := openResourceFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(out, "ID\tType\tCompound\tCompressed\tBlocks\tSize\tStored\t")
+	for _, id := range reader.IDs() {
+		view, viewErr := reader.View(id)
+		if viewErr != nil {
+			_, _ = fmt.Fprintf(out, "%v\t(error: %v)\n", id, viewErr)
+			continue
+		}
+		blockSizes := make([]int64, view.BlockCount())
+		var totalSize int64
+		for index := range blockSizes {
+			blockSizes[index] = blockSize(view.Block(index))
+			totalSize += blockSizes[index]
+		}
+		stored, _ := reader.StoredLength(id)
+		_, _ = fmt.Fprintf(out, "%v\t%v\t%v\t%v\t%d\t%d\t%d\t\n",
+			id, view.ContentType(), yesNo(view.Compound()), yesNo(view.Compressed()), len(blockSizes), totalSize, stored)
+		if *showBlocks {
+			for index, size := range blockSizes {
+				_, _ = fmt.Fprintf(out, "\t\t\t\t[%d]\t%d\t\t\n", index, size)
+			}
+		}
+	}
+	return out.Flush()
+}
+
+func blockSize(reader io.Reader, err error) int64 {
+	if err != nil {
+		return 0
+	}
+	size, _ := io.Copy(ioutil.Discard, reader)
+	return size
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+var version string
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{name: "list", summary: "list the directory of a resource file", run: runList},
+		{name: "extract", summary: "extract resources or single blocks from a resource file", run: runExtract},
+		{name: "dump", summary: "write resources as text, image, or audio files", run: runDump},
+		{name: "pack", summary: "pack an unpacked directory into a resource file", run: runPack},
+		{name: "verify", summary: "check a resource file for inconsistencies", run: runVerify},
+		{name: "diff", summary: "report the differences between two resource files", run: runDiff},
+		{name: "conflicts", summary: "report where two mods change the same data", run: runConflicts},
+		{name: "patch", summary: "create a patch file with the changes of a mod", run: runPatch},
+		{name: "apply", summary: "create the files of a mod from a patch file", run: runApply},
+		{name: "revert", summary: "restore the original resources a patched mod replaces", run: runRevert},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if (name == "help") || (name == "-h") || (name == "--help") {
+		printUsage(os.Stdout)
+		return
+	}
+	if name == "version" {
+		fmt.Println("InkyBlackness - HackEd CLI - " + currentVersion())
+		return
+	}
+	for _, cmd := range commands() {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(os.Args[2:])
+		if err == flag.ErrHelp {
+			return
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+	printUsage(os.Stderr)
+	os.Exit(2)
+}
+
+func currentVersion() string {
+	if len(version) > 0 {
+		return version
+	}
+	return fmt.Sprintf("(manual build %v)", time.Now().Format("2006-01-02"))
+}
+
+func printUsage(out io.Writer) {
+	_, _ = fmt.Fprintln(out, "Usage: hacked-cli <command> [arguments]")
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Commands:")
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	_, _ = fmt.Fprintf(out, "  %-10s %s\n", "version", "print the version")
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Use \"hacked-cli <command> -h\" for details on a command.")
+}
+
+func newFlagSet(name string, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: hacked-cli %s [flags] %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/resource/unpacked"
+)
+
+func runPack(args []string) error {
+	flags := newFlagSet("pack", "-o <file.res> <directory>")
+	outFile := flags.String("o", "", "resource file to create")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if (flags.NArg() != 1) || (len(*outFile) == 0) {
+		flags.Usage()
+		return errMissingArgument
+	}
+	store, err := unpacked.Read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(*outFile)
+	if err != nil {
+		return err
+	}
+	err = lgres.Write(file, store)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
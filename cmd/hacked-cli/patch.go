@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/patch"
+)
+
+const errPropertiesNotPatchable ss1.StringError = "patches can not contain object or texture properties (objprop.dat, textprop.dat)"
+
+func runPatch(args []string) error {
+	flags := newFlagSet("patch", "-o <file.hpatch> <world-dir> <mod-dir>")
+	outFile := flags.String("o", "", "patch file to create")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if (flags.NArg() != 2) || (len(*outFile) == 0) {
+		flags.Usage()
+		return errMissingArgument
+	}
+	sources, err := loadPatchFiles(flags.Arg(0), false)
+	if err != nil {
+		return err
+	}
+	mod, err := loadPatchFiles(flags.Arg(1), true)
+	if err != nil {
+		return err
+	}
+	created, err := patch.Create(sources, mod)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(*outFile)
+	if err != nil {
+		return err
+	}
+	err = created.Encode(file)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func runApply(args []string) error {
+	flags := newFlagSet("apply", "-o <out-dir> <file.hpatch> <world-dir>")
+	outDir := flags.String("o", "", "directory to write the mod files to")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if (flags.NArg() != 2) || (len(*outDir) == 0) {
+		flags.Usage()
+		return errMissingArgument
+	}
+	loaded, err := loadPatch(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	sources, err := loadPatchFiles(flags.Arg(1), false)
+	if err != nil {
+		return err
+	}
+	result, err := loaded.Apply(sources)
+	if err != nil {
+		return err
+	}
+	return writePatchResult(*outDir, result)
+}
+
+func runRevert(args []string) error {
+	flags := newFlagSet("revert", "-o <out-dir> <file.hpatch> <world-dir> <mod-dir>")
+	outDir := flags.String("o", "", "directory to write the original files to")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if (flags.NArg() != 3) || (len(*outDir) == 0) {
+		flags.Usage()
+		return errMissingArgument
+	}
+	loaded, err := loadPatch(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	sources, err := loadPatchFiles(flags.Arg(1), false)
+	if err != nil {
+		return err
+	}
+	mod, err := loadPatchFiles(flags.Arg(2), true)
+	if err != nil {
+		return err
+	}
+	result, err := loaded.Revert(sources, mod)
+	if err != nil {
+		return err
+	}
+	return writePatchResult(*outDir, result)
+}
+
+func loadPatch(filename string) (patch.Patch, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return patch.Patch{}, err
+	}
+	defer func() { _ = file.Close() }()
+	return patch.Decode(file)
+}
+
+func writePatchResult(outDir string, result map[string]*resource.Store) error {
+	err := os.MkdirAll(outDir, 0750)
+	if err != nil {
+		return err
+	}
+	for filename, store := range result {
+		err = writeResourceFile(filepath.Join(outDir, filename), store)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d resources\n", filename, len(store.IDs()))
+	}
+	return nil
+}
+
+// loadPatchFiles loads the known resource files of given directory, keyed by their lower case name.
+// Patches cover resource files only. For a mod, property files are rejected, as their changes
+// would otherwise be lost. Files that could not be loaded are rejected as well, as the patch would
+// otherwise silently miss them.
+func loadPatchFiles(dir string, isMod bool) (patch.Files, error) {
+	loaded := world.LoadFiles(false, []string{dir})
+	if (loaded.FailedFiles > 0) || (len(loaded.Failures) > 0) {
+		return nil, fmt.Errorf("%v: %w", dir, world.FileProblemsError{Problems: loaded.Problems()})
+	}
+	if isMod && ((loaded.ObjectProperties != nil) || (loaded.TextureProperties != nil)) {
+		return nil, fmt.Errorf("%v: %w", dir, errPropertiesNotPatchable)
+	}
+	files := make(patch.Files)
+	for location, viewer := range loaded.Resources {
+		files[strings.ToLower(location.Name)] = viewer
+	}
+	return files, nil
+}
+
+func writeResourceFile(filename string, viewer resource.Viewer) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = lgres.Write(file, viewer)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
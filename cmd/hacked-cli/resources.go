@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/resource/unpacked"
+)
+
+const (
+	errMissingArgument ss1.StringError = "missing argument"
+	errInvalidID       ss1.StringError = "invalid resource ID"
+	errInvalidBlock    ss1.StringError = "invalid block index"
+)
+
+func openResourceFile(filename string) (*lgres.Reader, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return lgres.ReaderFrom(bytes.NewReader(data))
+}
+
+// salvageResourceFile opens a damaged resource file, providing only the intact resources.
+func salvageResourceFile(filename string) (*lgres.Reader, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	reader, inconsistencies, err := lgres.SalvageReaderFrom(bytes.NewReader(data), int64(len(data)))
+	for _, inconsistency := range inconsistencies {
+		_, _ = fmt.Fprintf(os.Stderr, "skipping: %v\n", inconsistency)
+	}
+	return reader, err
+}
+
+// openResources opens either a resource file, or an unpacked directory.
+func openResources(path string) (resource.Viewer, error) {
+	if unpacked.IsUnpacked(path) {
+		return unpacked.Read(path)
+	}
+	return openResourceFile(path)
+}
+
+// parseID parses a hexadecimal resource identifier, with optional "0x" prefix.
+func parseID(text string) (resource.ID, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(text, "0x"), "0X")
+	value, err := strconv.ParseUint(trimmed, 16, 16)
+	if err != nil {
+		return 0, errInvalidID
+	}
+	return resource.ID(value), nil
+}
+
+// parseBlockRef parses a reference in the form "ID" or "ID:index".
+// The returned index is -1 if no block was specified.
+func parseBlockRef(text string) (resource.ID, int, error) {
+	parts := strings.SplitN(text, ":", 2)
+	id, err := parseID(parts[0])
+	if err != nil {
+		return 0, -1, err
+	}
+	if len(parts) < 2 {
+		return id, -1, nil
+	}
+	index, err := strconv.Atoi(parts[1])
+	if (err != nil) || (index < 0) {
+		return 0, -1, errInvalidBlock
+	}
+	return id, index, nil
+}
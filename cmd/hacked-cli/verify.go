@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+)
+
+const errInconsistent ss1.StringError = "file is inconsistent"
+
+func runVerify(args []string) error {
+	flags := newFlagSet("verify", "<file.res>")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errMissingArgument
+	}
+	data, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	source := bytes.NewReader(data)
+	inconsistencies := lgres.Verify(source, source.Size())
+	for _, inconsistency := range inconsistencies {
+		fmt.Println(inconsistency)
+	}
+	reader, _, err := lgres.SalvageReaderFrom(source, source.Size())
+	if err != nil {
+		return err
+	}
+	if len(inconsistencies) == 0 {
+		fmt.Printf("%d resources, no inconsistencies found\n", len(reader.IDs()))
+		return nil
+	}
+	fmt.Printf("%d inconsistencies found, %d resources intact\n", len(inconsistencies), len(reader.IDs()))
+	return errInconsistent
+}
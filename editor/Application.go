@@ -14,6 +14,7 @@ import (
 	"github.com/inkyblackness/hacked/editor/archives"
 	"github.com/inkyblackness/hacked/editor/bitmaps"
 	"github.com/inkyblackness/hacked/editor/external"
+	"github.com/inkyblackness/hacked/editor/fonts"
 	"github.com/inkyblackness/hacked/editor/graphics"
 	"github.com/inkyblackness/hacked/editor/levels"
 	"github.com/inkyblackness/hacked/editor/messages"
@@ -28,6 +29,7 @@ import (
 	"github.com/inkyblackness/hacked/ss1/content/archive/level"
 	"github.com/inkyblackness/hacked/ss1/content/bitmap"
 	"github.com/inkyblackness/hacked/ss1/content/movie"
+	"github.com/inkyblackness/hacked/ss1/content/object"
 	"github.com/inkyblackness/hacked/ss1/content/sound"
 	"github.com/inkyblackness/hacked/ss1/content/text"
 	"github.com/inkyblackness/hacked/ss1/edit"
@@ -47,11 +49,13 @@ const (
 	errCouldNotReadFile ss1.StringError = "could not read file"
 )
 
+// projectState is the content of a project file.
 type projectState struct {
-	ProjectSettings   *edit.ProjectSettings   `json:",omitempty"`
-	GameStateSettings *edit.GameStateSettings `json:",omitempty"`
-	OpenWindows       []string                `json:",omitempty"`
-	ActiveLevelIndex  *int                    `json:",omitempty"`
+	edit.ProjectFile
+}
+
+func newProjectState() projectState {
+	return projectState{ProjectFile: edit.NewProjectFile()}
 }
 
 func projectStateFromFile(filename string) (projectState, error) {
@@ -59,12 +63,11 @@ func projectStateFromFile(filename string) (projectState, error) {
 	if err != nil {
 		return projectState{}, errCouldNotOpenFile
 	}
-	var state projectState
-	err = json.Unmarshal(data, &state)
+	file, err := edit.ProjectFileFrom(data)
 	if err != nil {
-		return projectState{}, errCouldNotReadFile
+		return projectState{}, fmt.Errorf("%w: %v", errCouldNotReadFile, err)
 	}
-	return state, nil
+	return projectState{ProjectFile: file}, nil
 }
 
 // SaveTo stores the state in a file with given filename.
@@ -86,6 +89,10 @@ func lastProjectStateFromFile(filename string) lastProjectState {
 	if err != nil {
 		return lastProjectState{}
 	}
+	data, err = edit.MigrateProjectFile(data)
+	if err != nil {
+		return lastProjectState{}
+	}
 	var state lastProjectState
 	err = json.Unmarshal(data, &state)
 	if err != nil {
@@ -103,6 +110,55 @@ func (state lastProjectState) SaveTo(filename string) error {
 	return ioutil.WriteFile(filename, bytes, 0640)
 }
 
+// pathVariablesFromFile returns the path variables of this machine. They are not part of a project,
+// as their values are meant to differ between machines.
+func pathVariablesFromFile(filename string) edit.PathVariables {
+	vars := make(edit.PathVariables)
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return vars
+	}
+	_ = json.Unmarshal(data, &vars)
+	return vars
+}
+
+func savePathVariablesTo(vars edit.PathVariables, filename string) error {
+	bytes, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, bytes, 0640)
+}
+
+// modalStateMachine is the machine for the modal dialogs of the application.
+// It remembers the recently used folders for the current project.
+type modalStateMachine struct {
+	gui.ModalStateWrapper
+
+	lastImportDir string
+	lastExportDir string
+}
+
+// LastImportDir returns the folder of the most recently imported file.
+func (machine *modalStateMachine) LastImportDir() string {
+	return machine.lastImportDir
+}
+
+// SetLastImportDir updates the folder of the most recently imported file.
+func (machine *modalStateMachine) SetLastImportDir(dirname string) {
+	machine.lastImportDir = dirname
+}
+
+// LastExportDir returns the folder of the most recent export.
+func (machine *modalStateMachine) LastExportDir() string {
+	return machine.lastExportDir
+}
+
+// SetLastExportDir updates the folder of the most recent export.
+func (machine *modalStateMachine) SetLastExportDir(dirname string) {
+	machine.lastExportDir = dirname
+}
+
 // Application is the root object of the graphical editor.
 // It is set up by the main method.
 type Application struct {
@@ -131,7 +187,7 @@ type Application struct {
 	txnBuilder       cmd.TransactionBuilder
 	cmdStack         *cmd.Stack
 	mod              *world.Mod
-	cp               text.Codepage
+	cp               *text.SwitchableCodepage
 	textLineCache    *text.Cache
 	textPageCache    *text.Cache
 	messagesCache    *text.ElectronicMessageCache
@@ -159,16 +215,20 @@ type Application struct {
 	levelObjectsView *levels.ObjectsView
 	messagesView     *messages.View
 	textsView        *texts.View
+	textFitView      *texts.FitView
+	translationView  *texts.TranslationView
+	textSearchView   *texts.SearchView
 	bitmapsView      *bitmaps.View
 	texturesView     *textures.View
 	animationsView   *animations.View
 	moviesView       *movies.View
+	fontsView        *fonts.View
 	soundEffectsView *sounds.View
 	objectsView      *objects.View
 	aboutView        *about.View
 	licensesView     *about.LicensesView
 
-	modalState gui.ModalStateWrapper
+	modalState modalStateMachine
 
 	failureMessage string
 	failurePending bool
@@ -244,6 +304,7 @@ func (app *Application) render() {
 
 	app.projectView.Render()
 	app.updateAutoSave()
+	app.projectService.ReloadChangedFiles()
 
 	app.archiveView.Render()
 	app.levelControlView.Render()
@@ -251,10 +312,14 @@ func (app *Application) render() {
 	app.levelObjectsView.Render()
 	app.messagesView.Render()
 	app.textsView.Render()
+	app.textFitView.Render()
+	app.translationView.Render()
+	app.textSearchView.Render()
 	app.bitmapsView.Render()
 	app.texturesView.Render()
 	app.animationsView.Render()
 	app.moviesView.Render()
+	app.fontsView.Render()
 	app.soundEffectsView.Render()
 	app.objectsView.Render()
 	app.aboutView.Render()
@@ -574,7 +639,7 @@ func (app *Application) initSignalling() {
 func (app *Application) initModel() {
 	app.mod = world.NewMod(app.resourcesChanged, app.modReset)
 
-	app.cp = text.DefaultCodepage()
+	app.cp = text.NewSwitchableCodepage(text.DefaultCodepage())
 	app.textLineCache = text.NewLineCache(app.cp, app.mod)
 	app.textPageCache = text.NewPageCache(app.cp, app.mod)
 	app.messagesCache = text.NewElectronicMessageCache(app.cp, app.mod)
@@ -621,9 +686,17 @@ func (app *Application) initView() {
 	soundEffectSetter := media.NewSoundSetterService()
 	soundEffectService := undoable.NewSoundEffectService(edit.NewSoundEffectService(soundEffectViewer, soundEffectSetter), app)
 	augmentedTextService := undoable.NewAugmentedTextService(edit.NewAugmentedTextService(textViewer, textSetter, audioViewer, audioSetter), app)
-	movieService := undoable.NewMovieService(edit.NewMovieService(app.cp, movieViewer, movieSetter), app)
+	plainMovieService := edit.NewMovieService(app.cp, movieViewer, movieSetter)
+	movieService := undoable.NewMovieService(plainMovieService, app)
+	translationService := undoable.NewTranslationService(
+		edit.NewTranslationService(app.cp, app.mod, textViewer, textSetter, app.messagesCache, plainMovieService), app)
+	textSearchService := undoable.NewTextSearchService(
+		edit.NewTextSearchService(app.cp, app.mod, textViewer, textSetter, app.messagesCache, plainMovieService), app)
+	freeResourceService := edit.NewFreeResourceService(app.mod)
 
 	app.projectService = edit.NewProjectService(&app.txnBuilder, app.mod)
+	app.projectService.SetUndoHistory(app)
+	app.projectService.SetCodepageUser(app)
 	app.gameStateService = edit.NewGameStateService(&app.txnBuilder)
 
 	app.projectView = project.NewView(app.projectService, &app.modalState, app.GuiScale, &app.txnBuilder)
@@ -632,27 +705,78 @@ func (app *Application) initView() {
 	app.levelTilesView = levels.NewTilesView(app.levelEditorService, app.GuiScale, app.textLineCache, app.textureCache, &app.txnBuilder)
 	app.levelObjectsView = levels.NewObjectsView(app.gameObjectsService, app.levelEditorService, app.levelSelection, app.gameStateService, app.GuiScale, app.textLineCache, app.textureCache, &app.txnBuilder, app.gl)
 	app.messagesView = messages.NewMessagesView(app.mod, app.messagesCache, app.cp, app.movieCache, app.textureCache, &app.modalState, app.clipboard, app.GuiScale, app)
-	app.textsView = texts.NewTextsView(augmentedTextService, &app.modalState, app.clipboard, app.GuiScale)
-	app.bitmapsView = bitmaps.NewBitmapsView(app.mod, app.textureCache, app.paletteCache, &app.modalState, app.clipboard, app.GuiScale, app)
+	app.textsView = texts.NewTextsView(app.mod, freeResourceService, augmentedTextService, &app.modalState, app.clipboard, app.GuiScale)
+	app.textFitView = texts.NewFitView(app.mod, app.cp, app.projectService, app.GuiScale)
+	app.translationView = texts.NewTranslationView(translationService, &app.modalState, app.GuiScale)
+	app.textSearchView = texts.NewSearchView(textSearchService, app.showTextSearchResult, app.GuiScale)
+	app.bitmapsView = bitmaps.NewBitmapsView(app.mod, freeResourceService, app.textureCache, app.paletteCache, &app.modalState, app.clipboard, app.GuiScale, app)
 	app.texturesView = textures.NewTexturesView(app.mod, app.textLineCache, app.cp, app.textureCache, app.paletteCache, &app.modalState, app.clipboard, app.GuiScale, app)
 	app.animationsView = animations.NewAnimationsView(app.mod, app.textureCache, app.paletteCache, app.animationCache, &app.modalState, app.GuiScale, app)
 	app.moviesView = movies.NewMoviesView(app.mod, app.frameCache, movieService, &app.modalState, app.GuiScale, app)
+	app.fontsView = fonts.NewFontsView(app.mod, app.paletteCache, app.frameCache, &app.modalState, app.GuiScale, app)
 	app.soundEffectsView = sounds.NewSoundEffectsView(soundEffectService, &app.modalState, app.GuiScale)
 	app.objectsView = objects.NewView(app.mod, app.textLineCache, app.cp, app.textureCache, app.paletteCache, &app.modalState, app.clipboard, app.GuiScale, app)
 	app.aboutView = about.NewView(app.clipboard, app.GuiScale, app.Version)
 	app.licensesView = about.NewLicensesView(app.GuiScale)
 }
 
+// showTextSearchResult presents the text of a search result in the view that edits it.
+func (app *Application) showTextSearchResult(result edit.TextSearchResult) {
+	key := result.Key
+	switch {
+	case result.IsSubtitle():
+		app.moviesView.ShowSubtitles(key.ID, key.Lang)
+	case len(result.Field) > 0:
+		app.messagesView.ShowMessage(key, result.Field != "terse")
+	case (key.ID == ids.ObjectLongNames) || (key.ID == ids.ObjectShortNames):
+		linearIndex := 0
+		app.mod.ObjectProperties().Iterate(func(triple object.Triple, _ *object.Properties) bool {
+			if linearIndex == key.Index {
+				app.objectsView.ShowObject(triple, key.Lang)
+				return false
+			}
+			linearIndex++
+			return true
+		})
+	case (key.ID == ids.TextureNames) || (key.ID == ids.TextureUsages):
+		app.texturesView.ShowTexture(key.Index, key.Lang)
+	default:
+		app.textsView.ShowText(key)
+	}
+}
+
 // Queue requests to perform the given command.
+// The command is recorded with the change it causes, so that it can be stored with the undo history.
 func (app *Application) Queue(command cmd.Command) {
-	err := app.projectService.ModifyModWith(func(modder world.Modder) error {
-		return app.cmdStack.Perform(command, modder)
+	recorded := &cmd.RecordedCommand{Command: command}
+	change, err := app.projectService.ModifyModRecorded(func(modder world.Modder) error {
+		return app.cmdStack.Perform(recorded, modder)
 	})
+	recorded.Change = change
 	if err != nil {
 		app.onFailure("command", "", err)
 	}
 }
 
+// RecordedChanges returns the changes of the commands that can be undone and redone.
+func (app *Application) RecordedChanges() (undo []world.ModChange, redo []world.ModChange) {
+	return app.cmdStack.RecordedChanges()
+}
+
+// RestoreRecordedChanges replaces the commands that can be undone and redone with given changes.
+func (app *Application) RestoreRecordedChanges(undo []world.ModChange, redo []world.ModChange) {
+	app.cmdStack.RestoreRecordedChanges(undo, redo)
+}
+
+// UseCodepage switches the codepage of all texts. Texts that were decoded with the previous codepage are dropped.
+func (app *Application) UseCodepage(cp text.Codepage) {
+	app.cp.Switch(cp)
+	app.textLineCache.InvalidateAll()
+	app.textPageCache.InvalidateAll()
+	app.messagesCache.InvalidateAll()
+	app.movieCache.InvalidateAll()
+}
+
 func (app *Application) onFailure(source string, details string, err error) {
 	app.failurePending = true
 	app.failureMessage = fmt.Sprintf("Source: %v\nDetails: %v\nError: %v", source, details, err)
@@ -703,10 +827,14 @@ func (app *Application) renderMainMenu() {
 			windowEntry("Level Objects", "F4", app.levelObjectsView.WindowOpen())
 			windowEntry("Messages", "F5", app.messagesView.WindowOpen())
 			windowEntry("Texts", "", app.textsView.WindowOpen())
+			windowEntry("Text Fit", "", app.textFitView.WindowOpen())
+			windowEntry("Translation", "", app.translationView.WindowOpen())
+			windowEntry("Text Search", "", app.textSearchView.WindowOpen())
 			windowEntry("Bitmaps", "", app.bitmapsView.WindowOpen())
 			windowEntry("Textures", "", app.texturesView.WindowOpen())
 			windowEntry("Animations", "", app.animationsView.WindowOpen())
 			windowEntry("Movies", "", app.moviesView.WindowOpen())
+			windowEntry("Fonts", "", app.fontsView.WindowOpen())
 			windowEntry("Sound Effects", "", app.soundEffectsView.WindowOpen())
 			windowEntry("Game Objects", "", app.objectsView.WindowOpen())
 			imgui.EndMenu()
@@ -773,7 +901,7 @@ func projectFileTypes() []external.TypeInfo {
 
 func (app *Application) newProject() {
 	app.saveStoredProject()
-	app.restoreProjectState(projectState{}, "")
+	app.restoreProjectState(newProjectState(), "")
 }
 
 func (app *Application) loadProject() {
@@ -830,12 +958,16 @@ func (app *Application) currentProjectState() projectState {
 		}
 	}
 	activeLevelIndex := app.levelSelection.CurrentLevelID()
-	return projectState{
-		ProjectSettings:   &projectSettings,
-		GameStateSettings: &gameStateSettings,
-		OpenWindows:       openWindows,
-		ActiveLevelIndex:  &activeLevelIndex,
+	state := newProjectState()
+	state.ProjectSettings = &projectSettings
+	state.GameStateSettings = &gameStateSettings
+	state.Editor = &edit.EditorSettings{
+		OpenWindows:      openWindows,
+		ActiveLevelIndex: &activeLevelIndex,
+		LastImportDir:    app.modalState.LastImportDir(),
+		LastExportDir:    app.modalState.LastExportDir(),
 	}
+	return state
 }
 
 func (app *Application) saveWorkspace() {
@@ -846,7 +978,7 @@ func (app *Application) saveWorkspace() {
 		if err != nil {
 			currentProjectStateFilename = ""
 		} else {
-			currentProjectState = projectState{}
+			currentProjectState = newProjectState()
 		}
 	}
 
@@ -855,9 +987,12 @@ func (app *Application) saveWorkspace() {
 		LastProject:  currentProjectStateFilename,
 	}
 	_ = lastProjectState.SaveTo(app.lastProjectStateConfigFilename())
+	_ = savePathVariablesTo(app.projectService.PathVariables(), app.pathVariablesConfigFilename())
 }
 
 func (app *Application) restoreWorkspace() {
+	app.projectService.SetPathVariables(pathVariablesFromFile(app.pathVariablesConfigFilename()))
+
 	lastProjectState := lastProjectStateFromFile(app.lastProjectStateConfigFilename())
 
 	projectStateToRestore := lastProjectState.projectState
@@ -879,32 +1014,47 @@ func (app *Application) restoreProjectState(state projectState, filename string)
 	if state.ProjectSettings != nil {
 		projectSettings = *state.ProjectSettings
 	}
-	app.projectService.RestoreProject(projectSettings, filename)
+	err := app.projectService.RestoreProject(projectSettings, filename)
+	if _, unresolved := err.(edit.UnresolvedPathsError); unresolved {
+		app.onFailure("Project", "Not all static world data or mods could be loaded. "+
+			"Check the path variables in the project window.", err)
+	} else if err != nil {
+		app.onFailure("Project", "The codepage of the project could not be loaded. The default codepage is used.", err)
+	} else if problems := app.projectService.ModLoadProblems(); len(problems) > 0 {
+		app.onFailure("Project", "Not all files of the mod could be loaded. Saving the mod loses what could not be loaded.",
+			world.FileProblemsError{Problems: problems})
+	}
 	var gameStateSettings edit.GameStateSettings
 	if state.GameStateSettings != nil {
 		gameStateSettings = *state.GameStateSettings
 	}
 	app.gameStateService.RestoreSettings(gameStateSettings)
+	var editorSettings edit.EditorSettings
+	if state.Editor != nil {
+		editorSettings = *state.Editor
+	}
 
 	windowOpenByName := app.windowOpenByName()
 	for _, open := range windowOpenByName {
 		*open = false
 	}
-	for _, key := range state.OpenWindows {
+	for _, key := range editorSettings.OpenWindows {
 		open := windowOpenByName[key]
 		if open != nil {
 			*open = true
 		}
 	}
-	if len(state.OpenWindows) == 0 {
+	if len(editorSettings.OpenWindows) == 0 {
 		*app.projectView.WindowOpen() = true
 	}
 
 	activeLevel := world.StartingLevel
-	if (state.ActiveLevelIndex != nil) && app.levels.IsLevelAvailable(*state.ActiveLevelIndex) {
-		activeLevel = *state.ActiveLevelIndex
+	if (editorSettings.ActiveLevelIndex != nil) && app.levels.IsLevelAvailable(*editorSettings.ActiveLevelIndex) {
+		activeLevel = *editorSettings.ActiveLevelIndex
 	}
 	app.levelSelection.SetCurrentLevelID(activeLevel)
+	app.modalState.SetLastImportDir(editorSettings.LastImportDir)
+	app.modalState.SetLastExportDir(editorSettings.LastExportDir)
 
 	app.window.SetTitleSuffix(filename)
 }
@@ -926,6 +1076,10 @@ func (app *Application) lastProjectStateConfigFilename() string {
 	return filepath.Join(app.ConfigDir, "LastProjectState.json")
 }
 
+func (app *Application) pathVariablesConfigFilename() string {
+	return filepath.Join(app.ConfigDir, "PathVariables.json")
+}
+
 func (app *Application) windowOpenByName() map[string]*bool {
 	return map[string]*bool{
 		"project":      app.projectView.WindowOpen(),
@@ -935,10 +1089,14 @@ func (app *Application) windowOpenByName() map[string]*bool {
 		"levelObjects": app.levelObjectsView.WindowOpen(),
 		"messages":     app.messagesView.WindowOpen(),
 		"texts":        app.textsView.WindowOpen(),
+		"textFit":      app.textFitView.WindowOpen(),
+		"translation":  app.translationView.WindowOpen(),
+		"textSearch":   app.textSearchView.WindowOpen(),
 		"bitmaps":      app.bitmapsView.WindowOpen(),
 		"textures":     app.texturesView.WindowOpen(),
 		"animations":   app.animationsView.WindowOpen(),
 		"movies":       app.moviesView.WindowOpen(),
+		"fonts":        app.fontsView.WindowOpen(),
 		"soundEffects": app.soundEffectsView.WindowOpen(),
 		"gameObjects":  app.objectsView.WindowOpen(),
 	}
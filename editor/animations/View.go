@@ -112,6 +112,8 @@ func (view *View) renderContent() {
 		imgui.Separator()
 
 		view.renderProperties()
+		render.ResourceSources(view.mod,
+			resource.KeyOf(view.model.currentKey.ID.Plus(view.model.currentKey.Index), resource.LangAny, 0))
 
 		imgui.PopItemWidth()
 	}
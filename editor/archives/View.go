@@ -270,7 +270,7 @@ func (view *View) requestClearLevel(id int) {
 		}
 
 		if !view.hasGameStateInMod() {
-			command.newData[ids.ArchiveName] = text.DefaultCodepage().Encode("Starting Game | by InkyBlackness HackEd")
+			command.newData[ids.ArchiveName] = view.cp.Encode("Starting Game | by InkyBlackness HackEd")
 			command.newData[ids.GameState] = archive.ZeroGameStateData()
 		}
 
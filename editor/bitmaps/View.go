@@ -9,6 +9,7 @@ import (
 	"github.com/inkyblackness/hacked/editor/graphics"
 	"github.com/inkyblackness/hacked/editor/render"
 	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/edit"
 	"github.com/inkyblackness/hacked/ss1/edit/undoable/cmd"
 	"github.com/inkyblackness/hacked/ss1/resource"
 	"github.com/inkyblackness/hacked/ss1/world"
@@ -43,9 +44,10 @@ var knownBitmapTypesOrder = []resource.ID{
 
 // View provides edit controls for bitmaps.
 type View struct {
-	mod          *world.Mod
-	imageCache   *graphics.TextureCache
-	paletteCache *graphics.PaletteCache
+	mod           *world.Mod
+	freeResources edit.FreeResourceService
+	imageCache    *graphics.TextureCache
+	paletteCache  *graphics.PaletteCache
 
 	modalStateMachine gui.ModalStateMachine
 	clipboard         external.Clipboard
@@ -56,13 +58,15 @@ type View struct {
 }
 
 // NewBitmapsView returns a new instance.
-func NewBitmapsView(mod *world.Mod, imageCache *graphics.TextureCache, paletteCache *graphics.PaletteCache,
+func NewBitmapsView(mod *world.Mod, freeResources edit.FreeResourceService,
+	imageCache *graphics.TextureCache, paletteCache *graphics.PaletteCache,
 	modalStateMachine gui.ModalStateMachine, clipboard external.Clipboard,
 	guiScale float32, commander cmd.Commander) *View {
 	view := &View{
-		mod:          mod,
-		imageCache:   imageCache,
-		paletteCache: paletteCache,
+		mod:           mod,
+		freeResources: freeResources,
+		imageCache:    imageCache,
+		paletteCache:  paletteCache,
 
 		modalStateMachine: modalStateMachine,
 		clipboard:         clipboard,
@@ -136,6 +140,13 @@ func (view *View) renderContent() {
 
 		tex, err := view.imageCache.Texture(view.currentResourceKey())
 
+		if imgui.Button("Add New") {
+			view.selectFreeIndex(info)
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Selects the first index that is not used by any language.")
+		}
+		imgui.SameLine()
 		if imgui.Button("Clear") {
 			view.requestClear(selectedType)
 		}
@@ -159,6 +170,7 @@ func (view *View) renderContent() {
 			imgui.LabelText("Width", fmt.Sprintf("%d", int(width)))
 			imgui.LabelText("Height", fmt.Sprintf("%d", int(height)))
 		}
+		render.ResourceSources(view.mod, view.currentResourceKey())
 
 		imgui.PopItemWidth()
 	}
@@ -183,6 +195,13 @@ func (view *View) indexedResourceKey(index int) resource.Key {
 	return key
 }
 
+func (view *View) selectFreeIndex(info ids.ResourceInfo) {
+	index, found := view.freeResources.FirstFreeIndex(info)
+	if found {
+		view.model.currentKey.Index = index
+	}
+}
+
 func (view *View) hasModCurrentBitmap() bool {
 	key := view.currentResourceKey()
 	return len(view.mod.ModifiedBlock(key.Lang, key.ID, key.Index)) > 0
@@ -45,6 +45,15 @@ where to save the data into the editor window.
 				state.HandleFiles([]string{filename})
 			}
 		}
+		if lastDir := lastExportDir(state.machine); len(lastDir) > 0 {
+			imgui.SameLine()
+			if imgui.Button("Use Last Folder") {
+				state.HandleFiles([]string{lastDir})
+			}
+			if imgui.IsItemHovered() {
+				imgui.SetTooltip(lastDir)
+			}
+		}
 		imgui.SameLine()
 		if imgui.Button("Cancel") {
 			state.machine.SetState(nil)
@@ -59,6 +68,7 @@ where to save the data into the editor window.
 func (state *exportWaitingState) HandleFiles(names []string) {
 	dirPath, ok := state.verifyDir(names)
 	if ok {
+		rememberExportDir(state.machine, dirPath)
 		state.machine.SetState(nil)
 		state.callback(dirPath)
 	} else {
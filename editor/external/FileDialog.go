@@ -1,6 +1,8 @@
 package external
 
 import (
+	"path/filepath"
+
 	"github.com/inkyblackness/imgui-go/v3"
 	"github.com/sqweek/dialog"
 
@@ -12,6 +14,25 @@ type SingleFileHandler func(string) error
 
 type singleFileRunner func(*dialog.FileBuilder) (string, error)
 
+// recentFileDir provides the folder a file dialog starts in, and remembers the folder of the selected file.
+type recentFileDir struct {
+	last     func(machine gui.ModalStateMachine) string
+	remember func(machine gui.ModalStateMachine, filename string)
+}
+
+var (
+	recentSaveDir = recentFileDir{
+		last: lastExportDir,
+		remember: func(machine gui.ModalStateMachine, filename string) {
+			rememberExportDir(machine, filepath.Dir(filename))
+		},
+	}
+	recentLoadDir = recentFileDir{
+		last:     lastImportDir,
+		remember: rememberImportFile,
+	}
+)
+
 // SaveFile starts a dialog series to save a file.
 func SaveFile(machine gui.ModalStateMachine, types []TypeInfo, callback SingleFileHandler) {
 	machine.SetState(&fileStartState{
@@ -19,6 +40,7 @@ func SaveFile(machine gui.ModalStateMachine, types []TypeInfo, callback SingleFi
 		title:    "Save",
 		typeInfo: types,
 		runner:   func(builder *dialog.FileBuilder) (string, error) { return builder.Save() },
+		recent:   recentSaveDir,
 		callback: callback,
 	})
 }
@@ -30,6 +52,7 @@ func LoadFile(machine gui.ModalStateMachine, types []TypeInfo, callback SingleFi
 		title:    "Load",
 		typeInfo: types,
 		runner:   func(builder *dialog.FileBuilder) (string, error) { return builder.Load() },
+		recent:   recentLoadDir,
 		callback: callback,
 	})
 }
@@ -39,6 +62,7 @@ type fileStartState struct {
 	title    string
 	typeInfo []TypeInfo
 	runner   singleFileRunner
+	recent   recentFileDir
 	callback SingleFileHandler
 }
 
@@ -52,6 +76,7 @@ func (state fileStartState) Render() {
 		title:    state.title,
 		typeInfo: state.typeInfo,
 		runner:   state.runner,
+		recent:   state.recent,
 		callback: state.callback,
 	}
 	state.machine.SetState(nextState)
@@ -62,6 +87,7 @@ type fileWaitingState struct {
 	title    string
 	typeInfo []TypeInfo
 	runner   singleFileRunner
+	recent   recentFileDir
 	callback SingleFileHandler
 
 	renderCount      int
@@ -117,6 +143,9 @@ func (state *fileWaitingState) openDialog() {
 		dlgBuilder = dlgBuilder.Filter(info.Title, info.Extensions...)
 	}
 	dlgBuilder = dlgBuilder.Filter("All files (*.*)", "*")
+	if startDir := state.recent.last(state.machine); len(startDir) > 0 {
+		dlgBuilder = dlgBuilder.SetStartDir(startDir)
+	}
 
 	filename, err := state.runner(dlgBuilder)
 	state.machine.SetState(nil)
@@ -133,5 +162,7 @@ func (state *fileWaitingState) reportFile(filename string) {
 	if err != nil {
 		state.problem = "Previous attempt failed.\nPlease check and try again.\nReason:\n" + err.Error()
 		state.machine.SetState(state)
+		return
 	}
+	state.recent.remember(state.machine, filename)
 }
@@ -42,6 +42,9 @@ that shall be loaded into the editor window.
 				dlgBuilder = dlgBuilder.Filter(info.Title, info.Extensions...)
 			}
 			dlgBuilder = dlgBuilder.Filter("All files (*.*)", "*")
+			if startDir := lastImportDir(state.machine); len(startDir) > 0 {
+				dlgBuilder = dlgBuilder.SetStartDir(startDir)
+			}
 			filename, err := dlgBuilder.Load()
 			if err == nil {
 				state.HandleFiles([]string{filename})
@@ -61,6 +64,7 @@ that shall be loaded into the editor window.
 func (state *importWaitingState) HandleFiles(names []string) {
 	filename, ok := state.verifyFile(names)
 	if ok {
+		rememberImportFile(state.machine, filename)
 		state.machine.SetState(nil)
 		state.callback(filename)
 	} else {
@@ -0,0 +1,60 @@
+package external
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/inkyblackness/hacked/ui/gui"
+)
+
+// RecentDirectories is optionally implemented by modal state machines to remember
+// the folders that were most recently used for imports and exports.
+// Dialogs started with such a machine begin in these folders, and update them.
+type RecentDirectories interface {
+	// LastImportDir returns the folder of the most recently imported file.
+	LastImportDir() string
+	// SetLastImportDir updates the folder of the most recently imported file.
+	SetLastImportDir(dirname string)
+	// LastExportDir returns the folder of the most recent export.
+	LastExportDir() string
+	// SetLastExportDir updates the folder of the most recent export.
+	SetLastExportDir(dirname string)
+}
+
+func lastImportDir(machine gui.ModalStateMachine) string {
+	if recent, isRecent := machine.(RecentDirectories); isRecent {
+		return existingDir(recent.LastImportDir())
+	}
+	return ""
+}
+
+func rememberImportFile(machine gui.ModalStateMachine, filename string) {
+	if recent, isRecent := machine.(RecentDirectories); isRecent {
+		recent.SetLastImportDir(filepath.Dir(filename))
+	}
+}
+
+func lastExportDir(machine gui.ModalStateMachine) string {
+	if recent, isRecent := machine.(RecentDirectories); isRecent {
+		return existingDir(recent.LastExportDir())
+	}
+	return ""
+}
+
+func rememberExportDir(machine gui.ModalStateMachine, dirname string) {
+	if recent, isRecent := machine.(RecentDirectories); isRecent {
+		recent.SetLastExportDir(dirname)
+	}
+}
+
+// existingDir returns the given folder if it still exists, and an empty string otherwise.
+func existingDir(dirname string) string {
+	if len(dirname) == 0 {
+		return ""
+	}
+	info, err := os.Stat(dirname)
+	if (err != nil) || !info.IsDir() {
+		return ""
+	}
+	return dirname
+}
@@ -0,0 +1,30 @@
+package fonts
+
+import (
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+)
+
+type setFontCommand struct {
+	model *viewModel
+
+	key     resource.Key
+	oldData []byte
+	newData []byte
+}
+
+func (cmd setFontCommand) Do(modder world.Modder) error {
+	return cmd.perform(modder, cmd.newData)
+}
+
+func (cmd setFontCommand) Undo(modder world.Modder) error {
+	return cmd.perform(modder, cmd.oldData)
+}
+
+func (cmd setFontCommand) perform(modder world.Modder, data []byte) error {
+	modder.SetResourceBlock(cmd.key.Lang, cmd.key.ID, cmd.key.Index, data)
+
+	cmd.model.restoreFocus = true
+	cmd.model.currentKey = cmd.key
+	return nil
+}
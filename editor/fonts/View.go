@@ -0,0 +1,276 @@
+package fonts
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+
+	"github.com/inkyblackness/imgui-go/v3"
+
+	"github.com/inkyblackness/hacked/editor/external"
+	"github.com/inkyblackness/hacked/editor/graphics"
+	"github.com/inkyblackness/hacked/editor/render"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/content/font"
+	"github.com/inkyblackness/hacked/ss1/edit/undoable/cmd"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ui/gui"
+)
+
+// monochromeMarker is the palette index used for set pixels of monochrome fonts.
+const monochromeMarker = 0x01
+
+// View provides edit controls for fonts.
+type View struct {
+	mod          *world.Mod
+	paletteCache *graphics.PaletteCache
+
+	frameCache    *graphics.FrameCache
+	frameCacheKey graphics.FrameCacheKey
+
+	modalStateMachine gui.ModalStateMachine
+	guiScale          float32
+	commander         cmd.Commander
+
+	model viewModel
+}
+
+// NewFontsView returns a new instance.
+func NewFontsView(mod *world.Mod, paletteCache *graphics.PaletteCache, frameCache *graphics.FrameCache,
+	modalStateMachine gui.ModalStateMachine, guiScale float32, commander cmd.Commander) *View {
+	view := &View{
+		mod:          mod,
+		paletteCache: paletteCache,
+
+		frameCache:    frameCache,
+		frameCacheKey: frameCache.AllocateKey(),
+
+		modalStateMachine: modalStateMachine,
+		guiScale:          guiScale,
+		commander:         commander,
+
+		model: freshViewModel(),
+	}
+	return view
+}
+
+// WindowOpen returns the flag address, to be used with the main menu.
+func (view *View) WindowOpen() *bool {
+	return &view.model.windowOpen
+}
+
+// Render renders the view.
+func (view *View) Render() {
+	if view.model.restoreFocus {
+		imgui.SetNextWindowFocus()
+		view.model.restoreFocus = false
+		view.model.windowOpen = true
+	}
+	if view.model.windowOpen {
+		imgui.SetNextWindowSizeV(imgui.Vec2{X: 800 * view.guiScale, Y: 400 * view.guiScale}, imgui.ConditionFirstUseEver)
+		if imgui.BeginV("Fonts", view.WindowOpen(), imgui.WindowFlagsNoCollapse|imgui.WindowFlagsHorizontalScrollbar) {
+			view.renderContent()
+		}
+		imgui.End()
+	}
+}
+
+func (view *View) renderContent() {
+	view.updateFont()
+	if imgui.BeginChildV("Properties", imgui.Vec2{X: 350 * view.guiScale, Y: 0}, false, 0) {
+		imgui.PushItemWidth(-150 * view.guiScale)
+		if imgui.BeginCombo("Font", keyTitle(view.model.currentKey)) {
+			for _, key := range view.mod.ResourceKeysOfType(resource.Font) {
+				if imgui.SelectableV(keyTitle(key), key == view.model.currentKey, 0, imgui.Vec2{}) {
+					view.model.currentKey = key
+					view.model.importFailed = ""
+				}
+			}
+			imgui.EndCombo()
+		}
+		imgui.Separator()
+		fnt := view.model.font
+		if fnt != nil {
+			view.renderFontProperties(fnt)
+		} else if view.model.fontErr != nil {
+			imgui.LabelText("Font", "not available")
+		}
+		if len(view.model.importFailed) > 0 {
+			imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1, Y: 0, Z: 0, W: 1})
+			imgui.Text("Import failed: " + view.model.importFailed)
+			imgui.PopStyleColor()
+		}
+		render.ResourceSources(view.mod, view.model.currentKey)
+		imgui.PopItemWidth()
+	}
+	imgui.EndChild()
+	imgui.SameLine()
+	if view.model.font != nil {
+		width, height := view.sheetSize()
+		render.FrameImage("Glyphs", view.frameCache, view.frameCacheKey,
+			imgui.Vec2{X: width * 2 * view.guiScale, Y: height * 2 * view.guiScale})
+	}
+}
+
+func (view *View) renderFontProperties(fnt *font.Font) {
+	fontType := "Color"
+	if fnt.Type == font.TypeMonochrome {
+		fontType = "Monochrome"
+	}
+	imgui.LabelText("Type", fontType)
+	imgui.LabelText("Characters", fmt.Sprintf("%d - %d", fnt.FirstCharacter, fnt.LastCharacter()))
+	imgui.LabelText("Height", fmt.Sprintf("%d", fnt.Height))
+	gui.StepSliderInt("Character", &view.model.currentCharacter, 0, font.SheetColumns*font.SheetRows-1)
+	glyph := fnt.Glyph(view.model.currentCharacter)
+	if glyph.Width > 0 {
+		imgui.LabelText("Width", fmt.Sprintf("%d", glyph.Width))
+	} else {
+		imgui.LabelText("Width", "(not available)")
+	}
+
+	if imgui.Button("Import") {
+		view.requestImport(fnt.Type)
+	}
+	imgui.SameLine()
+	if imgui.Button("Export") {
+		view.requestExport(fnt)
+	}
+	if len(view.mod.ModifiedBlock(view.model.currentKey.Lang, view.model.currentKey.ID, 0)) > 0 {
+		imgui.SameLine()
+		if imgui.Button("Remove") {
+			view.requestSetFontData(nil)
+		}
+	}
+}
+
+func keyTitle(key resource.Key) string {
+	return fmt.Sprintf("%v (%v)", key.ID, key.Lang)
+}
+
+// updateFont decodes the current font, should its data have changed, and updates the texture of the glyph sheet.
+func (view *View) updateFont() {
+	data, err := view.currentFontData()
+	if (err == nil) && (view.model.font != nil) && bytes.Equal(data, view.model.fontData) {
+		return
+	}
+	view.model.fontData = data
+	view.model.font = nil
+	view.model.fontErr = err
+	if err != nil {
+		return
+	}
+	fnt, err := font.Decode(data)
+	if err != nil {
+		view.model.fontErr = err
+		return
+	}
+	palette, marker, err := view.paletteFor(fnt.Type)
+	if err != nil {
+		view.model.fontErr = err
+		return
+	}
+	view.model.font = fnt
+	sheet := fnt.Sheet(marker)
+	view.frameCache.SetTexture(view.frameCacheKey,
+		uint16(sheet.Header.Width), uint16(sheet.Header.Height), sheet.Pixels, &palette)
+}
+
+func (view *View) sheetSize() (width, height float32) {
+	texture := view.frameCache.Texture(view.frameCacheKey)
+	if texture == nil {
+		return 0, 0
+	}
+	return texture.Size()
+}
+
+func (view *View) currentFontData() ([]byte, error) {
+	key := view.model.currentKey
+	selected, err := view.mod.LocalizedResources(key.Lang).Select(key.ID)
+	if err != nil {
+		return nil, err
+	}
+	if selected.ContentType() != resource.Font {
+		return nil, resource.ErrWrongType(key, resource.Font)
+	}
+	reader, err := selected.Block(key.Index)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(reader)
+}
+
+// paletteFor returns the palette to display and exchange glyph sheets of given font type with, together with
+// the palette index that marks glyph widths.
+// Monochrome fonts use black and white. Color fonts use the game palette, with the color closest to white as marker.
+func (view *View) paletteFor(fontType font.Type) (bitmap.Palette, byte, error) {
+	if fontType == font.TypeMonochrome {
+		var palette bitmap.Palette
+		palette[monochromeMarker] = bitmap.RGB{Red: 0xFF, Green: 0xFF, Blue: 0xFF}
+		return palette, monochromeMarker, nil
+	}
+	gamePalette, err := view.paletteCache.Palette(0)
+	if err != nil {
+		return bitmap.Palette{}, 0, err
+	}
+	palette := gamePalette.Palette()
+	return palette, bitmap.NewBitmapper(&palette).MapColor(color.White), nil
+}
+
+func (view *View) requestExport(fnt *font.Font) {
+	palette, marker, err := view.paletteFor(fnt.Type)
+	if err != nil {
+		return
+	}
+	sheet := fnt.Sheet(marker)
+	sheet.Palette = &palette
+	key := view.model.currentKey
+	filename := fmt.Sprintf("font_%04X_%s.png", key.ID.Value(), key.Lang.String())
+	external.ExportImage(view.modalStateMachine, filename, sheet)
+}
+
+func (view *View) requestImport(fontType font.Type) {
+	key := view.model.currentKey
+	previous := view.model.font
+	paletteRetriever := func() (bitmap.Palette, error) {
+		palette, _, err := view.paletteFor(fontType)
+		return palette, err
+	}
+	external.ImportImage(view.modalStateMachine, paletteRetriever, func(sheet bitmap.Bitmap) {
+		_, marker, err := view.paletteFor(fontType)
+		if err != nil {
+			view.model.importFailed = err.Error()
+			return
+		}
+		fnt, err := font.FromSheet(sheet, fontType, marker)
+		if err != nil {
+			view.model.importFailed = err.Error()
+			return
+		}
+		if previous != nil {
+			fnt.Unknown0002 = previous.Unknown0002
+			fnt.Unknown0028 = previous.Unknown0028
+		}
+		data, err := font.Encode(*fnt)
+		if err != nil {
+			view.model.importFailed = err.Error()
+			return
+		}
+		view.model.importFailed = ""
+		view.model.currentKey = key
+		view.requestSetFontData(data)
+	})
+}
+
+func (view *View) requestSetFontData(newData []byte) {
+	key := view.model.currentKey
+	command := setFontCommand{
+		model: &view.model,
+
+		key:     key,
+		oldData: view.mod.ModifiedBlock(key.Lang, key.ID, key.Index),
+		newData: newData,
+	}
+	view.commander.Queue(command)
+}
@@ -0,0 +1,26 @@
+package fonts
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/font"
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+type viewModel struct {
+	windowOpen   bool
+	restoreFocus bool
+
+	currentKey       resource.Key
+	currentCharacter int
+
+	fontData     []byte
+	font         *font.Font
+	fontErr      error
+	importFailed string
+}
+
+func freshViewModel() viewModel {
+	return viewModel{
+		currentKey:       resource.KeyOf(resource.ID(0), resource.LangAny, 0),
+		currentCharacter: 'A',
+	}
+}
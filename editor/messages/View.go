@@ -85,6 +85,13 @@ func (view *View) WindowOpen() *bool {
 	return &view.model.windowOpen
 }
 
+// ShowMessage opens the window and selects the identified message, with either its verbose or terse text.
+func (view *View) ShowMessage(key resource.Key, verbose bool) {
+	view.model.currentKey = key
+	view.model.showVerboseText = verbose
+	view.model.restoreFocus = true
+}
+
 // Render renders the view.
 func (view *View) Render() {
 	if view.model.restoreFocus {
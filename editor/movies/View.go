@@ -81,6 +81,19 @@ func (view *View) WindowOpen() *bool {
 	return &view.model.windowOpen
 }
 
+// ShowSubtitles opens the window and selects the identified movie, with its subtitles in given language.
+func (view *View) ShowSubtitles(id resource.ID, lang resource.Language) {
+	view.model.currentKey = resource.KeyOf(id, resource.LangDefault, 0)
+	if knownMovies[id].multilang {
+		view.model.currentKey.Lang = lang
+	}
+	view.model.currentSubtitleLang = lang
+	view.model.currentScene = 0
+	view.model.currentFrame = 0
+	view.model.frameTimeFraction = -1
+	view.model.restoreFocus = true
+}
+
 // Render renders the view.
 func (view *View) Render() {
 	if view.model.restoreFocus {
@@ -130,6 +143,7 @@ func (view *View) renderContent() {
 		imgui.Separator()
 
 		view.renderProperties()
+		render.ResourceSources(view.mod, view.model.currentKey)
 
 		imgui.PopItemWidth()
 	}
@@ -65,6 +65,13 @@ func (view *View) WindowOpen() *bool {
 	return &view.model.windowOpen
 }
 
+// ShowObject opens the window and selects the given object type, with its texts in given language.
+func (view *View) ShowObject(triple object.Triple, lang resource.Language) {
+	view.model.currentObject = triple
+	view.model.currentLang = lang
+	view.model.restoreFocus = true
+}
+
 // Render renders the view.
 func (view *View) Render() {
 	if view.model.restoreFocus {
@@ -1,8 +1,6 @@
 package project
 
 import (
-	"time"
-
 	"github.com/inkyblackness/imgui-go/v3"
 	"github.com/sqweek/dialog"
 
@@ -10,23 +8,16 @@ import (
 )
 
 type addManifestEntryWaitingState struct {
-	machine     gui.ModalStateMachine
-	view        *View
-	failureTime time.Time
+	machine gui.ModalStateMachine
+	view    *View
+	failure error
 }
 
 func (state *addManifestEntryWaitingState) Render() {
 	if imgui.BeginPopupModalV("Add static world data", nil,
 		imgui.WindowFlagsNoResize|imgui.WindowFlagsNoMove|imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsAlwaysAutoResize) {
 		imgui.Text("Waiting for folders/files.")
-		if !state.failureTime.IsZero() {
-			imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1, Y: 0, Z: 0, W: 1})
-			imgui.Text("Previous attempt failed, no usable data detected.\nPlease check and try again.")
-			imgui.PopStyleColor()
-			if time.Since(state.failureTime).Seconds() > 5 {
-				state.failureTime = time.Time{}
-			}
-		}
+		renderLoadFailure(state.failure)
 		imgui.Text(`From your file browser drag'n'drop the folder (or files)
 of the static data you want to reference into the editor window.
 Typically, you would use the main "data" directory of the game
@@ -53,9 +44,8 @@ Typically, you would use the main "data" directory of the game
 
 func (state *addManifestEntryWaitingState) HandleFiles(names []string) {
 	err := state.view.tryAddManifestEntryFrom(names)
-	if err != nil {
-		state.failureTime = time.Now()
-	} else {
+	state.failure = err
+	if err == nil {
 		state.machine.SetState(nil)
 	}
 }
@@ -0,0 +1,23 @@
+package project
+
+import (
+	"github.com/inkyblackness/imgui-go/v3"
+
+	"github.com/inkyblackness/hacked/ui/gui"
+)
+
+type addParentModStartState struct {
+	machine gui.ModalStateMachine
+	view    *View
+}
+
+func (state addParentModStartState) Render() {
+	imgui.OpenPopup("Add parent mod")
+	state.machine.SetState(&addParentModWaitingState{
+		machine: state.machine,
+		view:    state.view,
+	})
+}
+
+func (state addParentModStartState) HandleFiles(names []string) {
+}
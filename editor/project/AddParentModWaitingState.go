@@ -0,0 +1,55 @@
+package project
+
+import (
+	"github.com/inkyblackness/imgui-go/v3"
+	"github.com/sqweek/dialog"
+
+	"github.com/inkyblackness/hacked/ui/gui"
+)
+
+type addParentModWaitingState struct {
+	machine gui.ModalStateMachine
+	view    *View
+	failure error
+}
+
+func (state *addParentModWaitingState) Render() {
+	if imgui.BeginPopupModalV("Add parent mod", nil,
+		imgui.WindowFlagsNoResize|imgui.WindowFlagsNoMove|imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsAlwaysAutoResize) {
+		imgui.Text("Waiting for folder.")
+		renderLoadFailure(state.failure)
+		imgui.Text(`From your file browser drag'n'drop the folder
+of the mod that the current mod shall be based on.
+A mod saved as a .zip archive can be dropped as well.
+`)
+		imgui.Text("The parent mod is only read, changes are stored\nin the current mod.")
+		imgui.Separator()
+		if imgui.Button("Browse...") {
+			dlgBuilder := dialog.Directory()
+			filename, err := dlgBuilder.Browse()
+			if err == nil {
+				state.HandleFiles([]string{filename})
+			}
+		}
+		imgui.SameLine()
+		if imgui.Button("Cancel") {
+			state.machine.SetState(nil)
+			imgui.CloseCurrentPopup()
+		}
+		imgui.EndPopup()
+	} else {
+		state.machine.SetState(nil)
+	}
+}
+
+func (state *addParentModWaitingState) HandleFiles(names []string) {
+	if len(names) != 1 {
+		state.failure = errSingleModExpected
+		return
+	}
+	err := state.view.service.AddParentModFrom(names[0])
+	state.failure = err
+	if err == nil {
+		state.machine.SetState(nil)
+	}
+}
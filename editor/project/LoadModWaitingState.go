@@ -1,8 +1,6 @@
 package project
 
 import (
-	"time"
-
 	"github.com/inkyblackness/imgui-go/v3"
 	"github.com/sqweek/dialog"
 
@@ -10,25 +8,19 @@ import (
 )
 
 type loadModWaitingState struct {
-	machine     gui.ModalStateMachine
-	view        *View
-	failureTime time.Time
+	machine gui.ModalStateMachine
+	view    *View
+	failure error
 }
 
 func (state *loadModWaitingState) Render() {
 	if imgui.BeginPopupModalV("Load mod", nil,
 		imgui.WindowFlagsNoResize|imgui.WindowFlagsNoMove|imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsAlwaysAutoResize) {
 		imgui.Text("Waiting for folder.")
-		if !state.failureTime.IsZero() {
-			imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1, Y: 0, Z: 0, W: 1})
-			imgui.Text("Previous attempt failed, no usable data detected.\nPlease check and try again.")
-			imgui.PopStyleColor()
-			if time.Since(state.failureTime).Seconds() > 5 {
-				state.failureTime = time.Time{}
-			}
-		}
+		renderLoadFailure(state.failure)
 		imgui.Text(`From your file browser drag'n'drop the folder
 of the mod you want to work on into the editor window.
+A mod saved as a .zip archive can be dropped as well.
 If you want to modify the main game files,
 use the main "data" directory of the game.
 `)
@@ -54,9 +46,8 @@ use the main "data" directory of the game.
 
 func (state *loadModWaitingState) HandleFiles(names []string) {
 	err := state.view.tryLoadModFrom(names)
-	if err != nil {
-		state.failureTime = time.Now()
-	} else {
+	state.failure = err
+	if err == nil {
 		state.machine.SetState(nil)
 	}
 }
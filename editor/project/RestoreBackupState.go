@@ -0,0 +1,90 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/inkyblackness/imgui-go/v3"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ui/gui"
+)
+
+type restoreBackupStartState struct {
+	machine gui.ModalStateMachine
+	view    *View
+}
+
+func (state restoreBackupStartState) Render() {
+	imgui.OpenPopup("Restore backup")
+	state.machine.SetState(&restoreBackupWaitingState{
+		machine:  state.machine,
+		view:     state.view,
+		backups:  state.view.service.ModBackups(),
+		selected: -1,
+	})
+}
+
+func (state restoreBackupStartState) HandleFiles(names []string) {
+}
+
+type restoreBackupWaitingState struct {
+	machine  gui.ModalStateMachine
+	view     *View
+	backups  []edit.FileBackup
+	selected int
+	problem  string
+}
+
+func (state *restoreBackupWaitingState) Render() {
+	if imgui.BeginPopupModalV("Restore backup", nil,
+		imgui.WindowFlagsNoResize|imgui.WindowFlagsNoMove|imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsAlwaysAutoResize) {
+		state.renderContent()
+		imgui.EndPopup()
+	} else {
+		state.machine.SetState(nil)
+	}
+}
+
+func (state *restoreBackupWaitingState) renderContent() {
+	if len(state.backups) == 0 {
+		imgui.Text("There are no backups of the files of the mod.")
+	} else {
+		imgui.Text("Select the backup to restore.\nThe current state of the file will be kept as a backup as well.")
+	}
+	if len(state.problem) > 0 {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1, Y: 0, Z: 0, W: 1})
+		imgui.Text("Previous attempt failed.\nReason:\n" + state.problem)
+		imgui.PopStyleColor()
+	}
+	imgui.BeginChildV("Backups", imgui.Vec2{X: 400 * state.view.guiScale, Y: imgui.TextLineHeightWithSpacing() * 8}, true, 0)
+	for index, backup := range state.backups {
+		label := fmt.Sprintf("%s - %s (#%d)", filepath.Base(backup.Filename),
+			backup.ModTime.Format("2006-01-02 15:04:05"), backup.Generation)
+		if imgui.SelectableV(label, state.selected == index, 0, imgui.Vec2{}) {
+			state.selected = index
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip(backup.Path)
+		}
+	}
+	imgui.EndChild()
+	imgui.Separator()
+	if (state.selected >= 0) && imgui.Button("Restore") {
+		err := state.view.service.RestoreModBackup(state.backups[state.selected])
+		if err != nil {
+			state.problem = err.Error()
+		} else {
+			state.machine.SetState(nil)
+			imgui.CloseCurrentPopup()
+		}
+	}
+	imgui.SameLine()
+	if imgui.Button("Cancel") {
+		state.machine.SetState(nil)
+		imgui.CloseCurrentPopup()
+	}
+}
+
+func (state *restoreBackupWaitingState) HandleFiles(names []string) {
+}
@@ -2,15 +2,23 @@ package project
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/inkyblackness/imgui-go/v3"
 
+	"github.com/inkyblackness/hacked/editor/external"
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/content/text"
 	"github.com/inkyblackness/hacked/ss1/edit"
 	"github.com/inkyblackness/hacked/ss1/edit/undoable/cmd"
 	"github.com/inkyblackness/hacked/ss1/world"
 	"github.com/inkyblackness/hacked/ui/gui"
 )
 
+const errSingleModExpected ss1.StringError = "only one mod can be added at a time"
+
 // View handles the project display.
 type View struct {
 	service *edit.ProjectService
@@ -67,10 +75,17 @@ func (view *View) Render() {
 func (view *View) renderContent() {
 	imgui.Text("Mod Location")
 	imgui.PushStyleVarVec2(imgui.StyleVarWindowPadding, imgui.Vec2{X: 1, Y: 0})
-	imgui.BeginChildV("ModLocation", imgui.Vec2{X: -200*view.guiScale - 10*view.guiScale, Y: imgui.TextLineHeight() * 1.5}, true,
+	imgui.BeginChildV("ModLocation", imgui.Vec2{X: -300*view.guiScale - 15*view.guiScale, Y: imgui.TextLineHeight() * 1.5}, true,
 		imgui.WindowFlagsNoScrollbar|imgui.WindowFlagsNoScrollWithMouse)
 	modPath := view.service.ModPath()
-	if len(modPath) > 0 {
+	if unresolvedMod, isUnresolved := view.service.UnresolvedMod(); isUnresolved {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 0.0, Z: 0.0, W: 1.0})
+		imgui.Text("(unresolved) " + strings.Join(unresolvedMod.Origin, ", "))
+		imgui.PopStyleColor()
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip(unresolvedMod.Err.Error())
+		}
+	} else if len(modPath) > 0 {
 		imgui.Text(modPath)
 	} else {
 		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 1.0, Z: 1.0, W: 0.5})
@@ -88,18 +103,119 @@ func (view *View) renderContent() {
 	if imgui.ButtonV("Load...", imgui.Vec2{X: 100 * view.guiScale, Y: 0}) {
 		view.startLoadingMod()
 	}
+	imgui.SameLine()
+	if imgui.ButtonV("Save as .zip...", imgui.Vec2{X: 100 * view.guiScale, Y: 0}) {
+		view.startSavingModAsZip()
+	}
 	imgui.EndGroup()
+	unpackedStorage := view.service.ModStorage() == edit.ModStorageUnpacked
+	if imgui.Checkbox("Store unpacked (for version control)", &unpackedStorage) {
+		if unpackedStorage {
+			view.service.SetModStorage(edit.ModStorageUnpacked)
+		} else {
+			view.service.SetModStorage(edit.ModStorageResourceFiles)
+		}
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Unpacked resources are stored as directories with one file per block.\n" +
+			"Such mods can be loaded by the editor, yet need to be saved as resource files for the game.")
+	}
+	backupCount := int32(view.service.BackupCount())
+	imgui.PushItemWidth(100 * view.guiScale)
+	if imgui.InputInt("Backups per file", &backupCount) {
+		view.service.SetBackupCount(int(backupCount))
+	}
+	imgui.PopItemWidth()
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Saving keeps this many previous states of each file in the folder " + edit.BackupDirName + ".")
+	}
+	imgui.SameLine()
+	if imgui.Button("Restore Backup...") {
+		view.startRestoringBackup()
+	}
+	salvage := view.service.SalvageDamagedFiles()
+	if imgui.Checkbox("Salvage damaged resource files", &salvage) {
+		view.service.SetSalvageDamagedFiles(salvage)
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Damaged resource files are loaded with their intact resources, instead of being rejected.\n" +
+			"The damaged resources are lost when such files are saved again. Applies to files loaded from now on.")
+	}
+	view.renderModLoadProblems()
+	if view.service.ModChangedOnDisk() {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 0.8, Z: 0.0, W: 1.0})
+		imgui.Text("(!) The files of the mod were changed on disk, yet not reloaded due to pending changes.")
+		imgui.PopStyleColor()
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Saving the mod overwrites the files on disk.\n" +
+				"To take over the changes on disk instead, load the mod again.")
+		}
+	}
+
+	imgui.Text("Parent Mods")
+	imgui.BeginChildV("ParentMods", imgui.Vec2{X: -100 * view.guiScale, Y: imgui.TextLineHeightWithSpacing() * 3.5}, true, 0)
+	layers := view.service.Mod().ParentLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		label := layers[i].Name
+		if len(layers[i].Problems) > 0 {
+			label += " (!)"
+		}
+		if imgui.SelectableV(label+"###"+layers[i].Name, view.model.selectedParentMod == i, 0, imgui.Vec2{}) {
+			view.model.selectedParentMod = i
+		}
+		if (len(layers[i].Problems) > 0) && imgui.IsItemHovered() {
+			imgui.SetTooltip(strings.Join(layers[i].Problems, "\n"))
+		}
+	}
+	imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 0.0, Z: 0.0, W: 1.0})
+	for _, parent := range view.service.UnresolvedParentMods() {
+		imgui.Text("(unresolved) " + strings.Join(parent.Origin, ", "))
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip(parent.Err.Error())
+		}
+	}
+	imgui.PopStyleColor()
+	imgui.EndChild()
+	imgui.SameLine()
+	imgui.BeginGroup()
+	if imgui.ButtonV("Add...##parent", imgui.Vec2{X: -1, Y: 0}) {
+		view.startAddingParentMod()
+	}
+	if imgui.ButtonV("Remove##parent", imgui.Vec2{X: -1, Y: 0}) {
+		view.service.RemoveParentMod(view.model.selectedParentMod)
+		view.model.selectedParentMod = -1
+	}
+	imgui.EndGroup()
+
+	view.renderPathSettings()
+	view.renderCodepageSettings()
 
 	imgui.Text("Static World Data")
-	imgui.BeginChildV("ManifestEntries", imgui.Vec2{X: -100 * view.guiScale, Y: 0}, true, 0)
 	manifest := view.service.Mod().World()
+	imgui.BeginChildV("ManifestEntries", imgui.Vec2{X: -100 * view.guiScale, Y: 0}, true, 0)
 	entries := manifest.EntryCount()
 	for i := entries - 1; i >= 0; i-- {
 		entry, _ := manifest.Entry(i)
-		if imgui.SelectableV(entry.ID, view.model.selectedManifestEntry == i, 0, imgui.Vec2{}) {
+		label := entry.ID
+		if len(entry.Problems) > 0 {
+			label += " (!)"
+		}
+		if imgui.SelectableV(label+"###"+entry.ID, view.model.selectedManifestEntry == i, 0, imgui.Vec2{}) {
 			view.model.selectedManifestEntry = i
 		}
+		if (len(entry.Problems) > 0) && imgui.IsItemHovered() {
+			imgui.SetTooltip(strings.Join(entry.Problems, "\n"))
+		}
+	}
+	unresolved := view.service.UnresolvedManifestEntries()
+	imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 0.0, Z: 0.0, W: 1.0})
+	for _, entry := range unresolved {
+		imgui.Text("(unresolved) " + strings.Join(entry.Origin, ", "))
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip(entry.Err.Error())
+		}
 	}
+	imgui.PopStyleColor()
 	imgui.EndChild()
 	imgui.SameLine()
 	imgui.BeginGroup()
@@ -115,9 +231,118 @@ func (view *View) renderContent() {
 	if imgui.ButtonV("Remove", imgui.Vec2{X: -1, Y: 0}) {
 		view.requestRemoveManifestEntry()
 	}
+	_, modUnresolved := view.service.UnresolvedMod()
+	anyUnresolved := (len(unresolved) > 0) || (len(view.service.UnresolvedParentMods()) > 0) || modUnresolved
+	if anyUnresolved && imgui.ButtonV("Retry", imgui.Vec2{X: -1, Y: 0}) {
+		_ = view.service.RetryUnresolvedPaths()
+	}
+	if anyUnresolved && imgui.IsItemHovered() {
+		imgui.SetTooltip("Try to load the unresolved entries and mods again,\nfor example after changing the path variables.")
+	}
 	imgui.EndGroup()
 }
 
+func (view *View) renderPathSettings() {
+	relativePaths := view.service.PathMode() == edit.ProjectPathsRelative
+	if imgui.Checkbox("Store paths relative to project (portable project)", &relativePaths) {
+		if relativePaths {
+			view.service.SetPathMode(edit.ProjectPathsRelative)
+		} else {
+			view.service.SetPathMode(edit.ProjectPathsAbsolute)
+		}
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("The paths of the static world data are stored relative to the project file.\n" +
+			"Paths within the folders of path variables are always stored with the variable, such as $GAME/data.")
+	}
+	if !imgui.TreeNode("Path Variables") {
+		return
+	}
+	vars := view.service.PathVariables()
+	changed := false
+	imgui.PushItemWidth(-100 * view.guiScale)
+	for _, name := range vars.Names() {
+		value := vars[name]
+		imgui.PushID(name)
+		if imgui.InputTextV("$"+name, &value, 0, nil) {
+			vars[name] = value
+			changed = true
+		}
+		imgui.SameLine()
+		if imgui.Button("Remove") {
+			delete(vars, name)
+			changed = true
+		}
+		imgui.PopID()
+	}
+	imgui.InputTextV("##newVariable", &view.model.newVariableName, 0, nil)
+	imgui.SameLine()
+	name := strings.TrimPrefix(strings.TrimSpace(view.model.newVariableName), "$")
+	if imgui.Button("Add Variable") && (len(name) > 0) && !strings.ContainsAny(name, `/\`) {
+		if _, exists := vars[name]; !exists {
+			vars[name] = ""
+			changed = true
+		}
+		view.model.newVariableName = ""
+	}
+	imgui.PopItemWidth()
+	if changed {
+		view.service.SetPathVariables(vars)
+	}
+	imgui.TreePop()
+}
+
+func (view *View) renderCodepageSettings() {
+	codepageFile := view.service.CodepageFile()
+	if unresolved := view.service.UnresolvedCodepage(); len(unresolved) > 0 {
+		codepageFile = "(default, not found: " + unresolved + ")"
+	} else if len(codepageFile) == 0 {
+		codepageFile = "(default)"
+	}
+	imgui.Text("Codepage: " + codepageFile)
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("The codepage maps the bytes of texts to characters. Translations with patched fonts\n" +
+			"need a table file with their mapping. Unlisted bytes keep the mapping of the default codepage.")
+	}
+	imgui.SameLine()
+	if imgui.Button("Load...##codepage") {
+		view.startLoadingCodepage()
+	}
+	imgui.SameLine()
+	if imgui.Button("Export...##codepage") {
+		view.startExportingCodepage()
+	}
+	if (len(view.service.CodepageFile()) > 0) || (len(view.service.UnresolvedCodepage()) > 0) {
+		imgui.SameLine()
+		if imgui.Button("Default##codepage") {
+			_ = view.service.SetCodepageFile("")
+		}
+	}
+}
+
+func (view *View) renderModLoadProblems() {
+	problems := view.service.ModLoadProblems()
+	if len(problems) == 0 {
+		return
+	}
+	imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 0.8, Z: 0.0, W: 1.0})
+	imgui.Text(fmt.Sprintf("(!) %d problem(s) loading the mod, saving loses what could not be loaded", len(problems)))
+	imgui.PopStyleColor()
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip(strings.Join(problems, "\n"))
+	}
+}
+
+// renderLoadFailure shows why the previous attempt to load files failed, if it did.
+func renderLoadFailure(failure error) {
+	if failure == nil {
+		return
+	}
+	imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1, Y: 0, Z: 0, W: 1})
+	imgui.Text("Previous attempt failed: " + failure.Error() + "\nPlease check and try again.")
+	imgui.PopStyleColor()
+}
+
 func (view *View) startLoadingMod() {
 	view.modalStateMachine.SetState(&loadModStartState{
 		machine: view.modalStateMachine,
@@ -141,6 +366,56 @@ func (view *View) StartSavingMod() {
 	}
 }
 
+func (view *View) startRestoringBackup() {
+	view.modalStateMachine.SetState(&restoreBackupStartState{
+		machine: view.modalStateMachine,
+		view:    view,
+	})
+}
+
+func (view *View) startAddingParentMod() {
+	view.modalStateMachine.SetState(&addParentModStartState{
+		machine: view.modalStateMachine,
+		view:    view,
+	})
+}
+
+func (view *View) startSavingModAsZip() {
+	types := []external.TypeInfo{{Title: "Zip archives (*.zip)", Extensions: []string{"zip"}}}
+	external.SaveFile(view.modalStateMachine, types, func(filename string) error {
+		if !strings.EqualFold(filepath.Ext(filename), ".zip") {
+			filename += ".zip"
+		}
+		return view.service.SaveModAsZip(filename)
+	})
+}
+
+func codepageFileTypes() []external.TypeInfo {
+	return []external.TypeInfo{{Title: "Codepage tables (*.txt)", Extensions: []string{"txt"}}}
+}
+
+func (view *View) startLoadingCodepage() {
+	external.LoadFile(view.modalStateMachine, codepageFileTypes(), view.service.SetCodepageFile)
+}
+
+func (view *View) startExportingCodepage() {
+	external.SaveFile(view.modalStateMachine, codepageFileTypes(), func(filename string) error {
+		if !strings.EqualFold(filepath.Ext(filename), ".txt") {
+			filename += ".txt"
+		}
+		file, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		err = text.SaveCodepage(file, view.service.Codepage())
+		closeErr := file.Close()
+		if err != nil {
+			return err
+		}
+		return closeErr
+	})
+}
+
 func (view *View) startAddingManifestEntry() {
 	view.modalStateMachine.SetState(&addManifestEntryStartState{
 		machine: view.modalStateMachine,
@@ -174,7 +449,7 @@ func (view *View) requestMoveManifestEntry(to, from int) {
 }
 
 func (view *View) tryAddManifestEntryFrom(names []string) error {
-	entry, err := world.NewManifestEntryFrom(names)
+	entry, err := view.service.NewManifestEntryFrom(names)
 	if err != nil {
 		return err
 	}
@@ -4,11 +4,14 @@ type viewModel struct {
 	restoreFocus          bool
 	windowOpen            bool
 	selectedManifestEntry int
+	selectedParentMod     int
+	newVariableName       string
 }
 
 func freshViewModel() viewModel {
 	return viewModel{
 		windowOpen:            false,
 		selectedManifestEntry: -1,
+		selectedParentMod:     -1,
 	}
 }
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/inkyblackness/imgui-go/v3"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+)
+
+// ResourceSources renders the chain of sources that define the resource identified by given key.
+// Sources are listed in override order, and the one providing the block of the key is marked.
+func ResourceSources(mod *world.Mod, key resource.Key) {
+	sources := mod.ResourceSources(key.Lang, key.ID)
+	if !imgui.TreeNodeV(fmt.Sprintf("Sources (%d)###Sources", len(sources)), imgui.TreeNodeFlagsFramed) {
+		return
+	}
+	if len(sources) == 0 {
+		imgui.Text("(not defined anywhere)")
+	}
+	// Block data is only read while the node is open, as it requires to decode the resources.
+	withData := make([]bool, len(sources))
+	effective := -1
+	for index, source := range sources {
+		withData[index] = hasBlockData(source.View, key.Index)
+		if withData[index] {
+			effective = index
+		}
+	}
+	for index, source := range sources {
+		text := fmt.Sprintf("%d: %s", index+1, sourceDescription(source))
+		switch {
+		case index == effective:
+			text += " (in effect)"
+		case !withData[index]:
+			text += " (no data)"
+		}
+		if index == effective {
+			imgui.Text(text)
+		} else {
+			imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 1.0, Z: 1.0, W: 0.5})
+			imgui.Text(text)
+			imgui.PopStyleColor()
+		}
+		if imgui.IsItemHovered() && (len(source.Origin) > 0) {
+			imgui.SetTooltip(strings.Join(source.Origin, "\n"))
+		}
+	}
+	imgui.TreePop()
+}
+
+func sourceDescription(source world.ResourceSource) string {
+	lang := source.Language.String()
+	switch {
+	case source.IsMod():
+		return fmt.Sprintf("This mod - %s [%s]", source.Filename, lang)
+	case source.Layer != nil:
+		return fmt.Sprintf("Parent mod %s - %s [%s]", source.Layer.Name, source.Filename, lang)
+	default:
+		return fmt.Sprintf("Manifest %s - %s [%s]", source.Entry.ID, source.Filename, lang)
+	}
+}
+
+func hasBlockData(view resource.View, index int) bool {
+	if (index < 0) || (index >= view.BlockCount()) {
+		return false
+	}
+	reader, err := view.Block(index)
+	if err != nil {
+		return false
+	}
+	var single [1]byte
+	read, _ := reader.Read(single[:])
+	return read > 0
+}
@@ -0,0 +1,165 @@
+package texts
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/imgui-go/v3"
+
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ui/gui"
+)
+
+// FitView lists the texts that do not fit into their display area.
+// The fonts and display areas of the categories are stored with the project.
+type FitView struct {
+	mod      *world.Mod
+	cp       text.Codepage
+	project  *edit.ProjectService
+	guiScale float32
+
+	model fitViewModel
+}
+
+// NewFitView returns a new instance.
+func NewFitView(mod *world.Mod, cp text.Codepage, project *edit.ProjectService, guiScale float32) *FitView {
+	view := &FitView{
+		mod:      mod,
+		cp:       cp,
+		project:  project,
+		guiScale: guiScale,
+
+		model: freshFitViewModel(),
+	}
+	return view
+}
+
+// WindowOpen returns the flag address, to be used with the main menu.
+func (view *FitView) WindowOpen() *bool {
+	return &view.model.windowOpen
+}
+
+// Render renders the view.
+func (view *FitView) Render() {
+	if view.model.windowOpen {
+		imgui.SetNextWindowSizeV(imgui.Vec2{X: 800 * view.guiScale, Y: 300 * view.guiScale}, imgui.ConditionFirstUseEver)
+		if imgui.BeginV("Text Fit", view.WindowOpen(), imgui.WindowFlagsNoCollapse) {
+			view.renderContent()
+		}
+		imgui.End()
+	}
+}
+
+func (view *FitView) renderContent() {
+	if imgui.BeginChildV("Categories", imgui.Vec2{X: 350 * view.guiScale, Y: 0}, false, 0) {
+		imgui.PushItemWidth(-150 * view.guiScale)
+		categories := view.project.TextFitCategories()
+		if view.model.currentCategory >= len(categories) {
+			view.model.currentCategory = 0
+		}
+		category := &categories[view.model.currentCategory]
+		if imgui.BeginCombo("Category", category.Title) {
+			for index, other := range categories {
+				if imgui.SelectableV(other.Title, index == view.model.currentCategory, 0, imgui.Vec2{}) {
+					view.model.currentCategory = index
+				}
+			}
+			imgui.EndCombo()
+		}
+		category = &categories[view.model.currentCategory]
+		changed := view.renderFontSelection(category)
+		if imgui.Button("Use Font for All") {
+			for index := range categories {
+				categories[index].Font = category.Font
+			}
+			changed = true
+		}
+		if gui.StepSliderInt("Width", &category.Area.Width, 1, 320) {
+			changed = true
+		}
+		if gui.StepSliderIntV("Lines", &category.Area.Lines, 0, 50, "%d (0 = unlimited)") {
+			changed = true
+		}
+		if imgui.Button("Reset to Defaults") {
+			categories = edit.DefaultTextFitCategories()
+			changed = true
+		}
+		if changed {
+			view.project.SetTextFitCategories(categories)
+		}
+		imgui.Separator()
+		if imgui.Button("Check") {
+			view.model.issues = edit.CheckTextFit(view.mod, view.cp, categories)
+			view.model.checked = true
+		}
+		imgui.PopItemWidth()
+	}
+	imgui.EndChild()
+	imgui.SameLine()
+	if imgui.BeginChildV("Issues", imgui.Vec2{X: 0, Y: 0}, true, 0) {
+		view.renderIssues()
+	}
+	imgui.EndChild()
+}
+
+func (view *FitView) renderFontSelection(category *edit.TextFitCategory) bool {
+	changed := false
+	fontTitle := func(id resource.ID) string {
+		if id == 0 {
+			return "(none)"
+		}
+		return id.String()
+	}
+	if imgui.BeginCombo("Font", fontTitle(category.Font)) {
+		if imgui.SelectableV(fontTitle(0), category.Font == 0, 0, imgui.Vec2{}) {
+			category.Font = 0
+			changed = true
+		}
+		var lastID resource.ID
+		for _, key := range view.mod.ResourceKeysOfType(resource.Font) {
+			if key.ID == lastID {
+				continue
+			}
+			lastID = key.ID
+			if imgui.SelectableV(fontTitle(key.ID), key.ID == category.Font, 0, imgui.Vec2{}) {
+				category.Font = key.ID
+				changed = true
+			}
+		}
+		imgui.EndCombo()
+	}
+	return changed
+}
+
+func (view *FitView) renderIssues() {
+	if !view.model.checked {
+		imgui.Text("Adapt the fonts and areas of the categories if needed, then check the texts.")
+		return
+	}
+	if len(view.model.issues) == 0 {
+		imgui.Text("All texts fit.")
+		return
+	}
+	imgui.Text(fmt.Sprintf("%d texts do not fit:", len(view.model.issues)))
+	imgui.ColumnsV(5, "issues", true)
+	for _, header := range []string{"Language", "Category", "Index", "Size", "Text"} {
+		imgui.Text(header)
+		imgui.NextColumn()
+	}
+	imgui.Separator()
+	for _, issue := range view.model.issues {
+		imgui.Text(issue.Key.Lang.String())
+		imgui.NextColumn()
+		imgui.Text(issue.Category)
+		imgui.NextColumn()
+		imgui.Text(fmt.Sprintf("%d", issue.Key.Index))
+		imgui.NextColumn()
+		imgui.Text(fmt.Sprintf("%dpx, %d lines", issue.Width, issue.Lines))
+		imgui.NextColumn()
+		imgui.Text(issue.Text)
+		imgui.NextColumn()
+	}
+	imgui.Columns()
+}
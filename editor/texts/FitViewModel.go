@@ -0,0 +1,18 @@
+package texts
+
+import (
+	"github.com/inkyblackness/hacked/ss1/edit"
+)
+
+type fitViewModel struct {
+	windowOpen bool
+
+	currentCategory int
+
+	checked bool
+	issues  []edit.TextFitIssue
+}
+
+func freshFitViewModel() fitViewModel {
+	return fitViewModel{}
+}
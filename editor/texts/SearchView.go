@@ -0,0 +1,171 @@
+package texts
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/inkyblackness/imgui-go/v3"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/edit/undoable"
+)
+
+// SearchView searches and replaces in all texts of the game.
+type SearchView struct {
+	service  undoable.TextSearchService
+	show     func(edit.TextSearchResult)
+	guiScale float32
+
+	model searchViewModel
+}
+
+// NewSearchView returns a new instance.
+// The show function is called to present a selected result in the view that edits it.
+func NewSearchView(service undoable.TextSearchService, show func(edit.TextSearchResult), guiScale float32) *SearchView {
+	view := &SearchView{
+		service:  service,
+		show:     show,
+		guiScale: guiScale,
+
+		model: freshSearchViewModel(),
+	}
+	return view
+}
+
+// WindowOpen returns the flag address, to be used with the main menu.
+func (view *SearchView) WindowOpen() *bool {
+	return &view.model.windowOpen
+}
+
+// Render renders the view.
+func (view *SearchView) Render() {
+	if view.model.restoreFocus {
+		imgui.SetNextWindowFocus()
+		view.model.restoreFocus = false
+		view.model.windowOpen = true
+	}
+	if view.model.windowOpen {
+		imgui.SetNextWindowSizeV(imgui.Vec2{X: 800 * view.guiScale, Y: 300 * view.guiScale}, imgui.ConditionFirstUseEver)
+		if imgui.BeginV("Text Search", view.WindowOpen(), imgui.WindowFlagsNoCollapse) {
+			view.renderContent()
+		}
+		imgui.End()
+	}
+}
+
+func (view *SearchView) renderContent() {
+	if view.model.searchPending {
+		view.model.searchPending = false
+		view.searchWith(view.model.searchedPattern)
+	}
+	if imgui.BeginChildV("Query", imgui.Vec2{X: 350 * view.guiScale, Y: 0}, false, 0) {
+		imgui.PushItemWidth(-150 * view.guiScale)
+		if imgui.InputTextV("Pattern", &view.model.pattern, imgui.InputTextFlagsEnterReturnsTrue, nil) {
+			view.search()
+		}
+		imgui.Checkbox("Ignore Case", &view.model.ignoreCase)
+		if imgui.Button("Search") {
+			view.search()
+		}
+		imgui.Separator()
+		imgui.InputText("Replacement", &view.model.replacement)
+		if (len(view.model.results) > 0) && imgui.Button("Replace All") {
+			view.requestReplace()
+		}
+		if len(view.model.patternErr) > 0 {
+			imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1, Y: 0, Z: 0, W: 1})
+			imgui.Text("Invalid pattern: " + view.model.patternErr)
+			imgui.PopStyleColor()
+		}
+		imgui.PopItemWidth()
+	}
+	imgui.EndChild()
+	imgui.SameLine()
+	if imgui.BeginChildV("Results", imgui.Vec2{X: 0, Y: 0}, true, 0) {
+		view.renderResults()
+	}
+	imgui.EndChild()
+}
+
+func (view *SearchView) renderResults() {
+	if !view.model.searched {
+		imgui.Text("Search with a regular expression, such as \"Rebecca\" or \"\\d{5}\".")
+		return
+	}
+	if len(view.model.results) == 0 {
+		imgui.Text("No texts found.")
+		return
+	}
+	imgui.Text(fmt.Sprintf("%d texts found for \"%s\". Select one to show it.",
+		len(view.model.results), view.model.searchedPattern))
+	imgui.ColumnsV(4, "results", true)
+	for _, header := range []string{"Language", "Location", "Matches", "Text"} {
+		imgui.Text(header)
+		imgui.NextColumn()
+	}
+	imgui.Separator()
+	for index, result := range view.model.results {
+		label := fmt.Sprintf("%v##%d", result.Key.Lang, index)
+		if imgui.SelectableV(label, false, imgui.SelectableFlagsSpanAllColumns, imgui.Vec2{}) {
+			view.show(result)
+		}
+		imgui.NextColumn()
+		imgui.Text(result.Title)
+		imgui.NextColumn()
+		imgui.Text(fmt.Sprintf("%d", result.Matches))
+		imgui.NextColumn()
+		imgui.Text(result.Text)
+		imgui.NextColumn()
+	}
+	imgui.Columns()
+}
+
+func (view *SearchView) compiledPattern() (*regexp.Regexp, bool) {
+	expression := view.model.pattern
+	if view.model.ignoreCase {
+		expression = "(?i)" + expression
+	}
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		view.model.patternErr = err.Error()
+		return nil, false
+	}
+	view.model.patternErr = ""
+	return pattern, len(view.model.pattern) > 0
+}
+
+func (view *SearchView) search() {
+	pattern, valid := view.compiledPattern()
+	if !valid {
+		pattern = nil
+	}
+	view.searchWith(pattern)
+}
+
+// searchWith searches with given pattern, which is kept to replace the found texts.
+func (view *SearchView) searchWith(pattern *regexp.Regexp) {
+	view.model.searchedPattern = pattern
+	view.model.results = nil
+	view.model.searched = pattern != nil
+	if pattern != nil {
+		view.model.results = view.service.Search(pattern)
+	}
+}
+
+// requestReplace replaces with the pattern that found the results, regardless of the current pattern input.
+func (view *SearchView) requestReplace() {
+	pattern := view.model.searchedPattern
+	if pattern == nil {
+		return
+	}
+	view.service.RequestReplace(pattern, view.model.replacement, view.restoreFunc())
+	view.model.searchPending = true
+}
+
+// restoreFunc lets the view search again once the change is done, as the results of the search are outdated.
+func (view *SearchView) restoreFunc() func() {
+	return func() {
+		view.model.restoreFocus = true
+		view.model.searchPending = true
+	}
+}
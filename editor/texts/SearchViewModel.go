@@ -0,0 +1,26 @@
+package texts
+
+import (
+	"regexp"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+)
+
+type searchViewModel struct {
+	restoreFocus bool
+	windowOpen   bool
+
+	pattern     string
+	ignoreCase  bool
+	replacement string
+
+	patternErr      string
+	searched        bool
+	searchPending   bool
+	searchedPattern *regexp.Regexp
+	results         []edit.TextSearchResult
+}
+
+func freshSearchViewModel() searchViewModel {
+	return searchViewModel{}
+}
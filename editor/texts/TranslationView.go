@@ -0,0 +1,174 @@
+package texts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inkyblackness/imgui-go/v3"
+
+	"github.com/inkyblackness/hacked/editor/external"
+	"github.com/inkyblackness/hacked/ss1/content/text/po"
+	"github.com/inkyblackness/hacked/ss1/edit/undoable"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ui/gui"
+)
+
+// TranslationView exchanges the texts of a language pair with PO files.
+type TranslationView struct {
+	service           undoable.TranslationService
+	modalStateMachine gui.ModalStateMachine
+	guiScale          float32
+
+	model translationViewModel
+}
+
+// NewTranslationView returns a new instance.
+func NewTranslationView(service undoable.TranslationService, modalStateMachine gui.ModalStateMachine,
+	guiScale float32) *TranslationView {
+	view := &TranslationView{
+		service:           service,
+		modalStateMachine: modalStateMachine,
+		guiScale:          guiScale,
+
+		model: freshTranslationViewModel(),
+	}
+	return view
+}
+
+// WindowOpen returns the flag address, to be used with the main menu.
+func (view *TranslationView) WindowOpen() *bool {
+	return &view.model.windowOpen
+}
+
+// Render renders the view.
+func (view *TranslationView) Render() {
+	if view.model.restoreFocus {
+		imgui.SetNextWindowFocus()
+		view.model.restoreFocus = false
+		view.model.windowOpen = true
+	}
+	if view.model.windowOpen {
+		imgui.SetNextWindowSizeV(imgui.Vec2{X: 600 * view.guiScale, Y: 300 * view.guiScale}, imgui.ConditionFirstUseEver)
+		if imgui.BeginV("Translation", view.WindowOpen(), imgui.WindowFlagsNoCollapse) {
+			view.renderContent()
+		}
+		imgui.End()
+	}
+}
+
+func (view *TranslationView) renderContent() {
+	if imgui.BeginChildV("Languages", imgui.Vec2{X: 300 * view.guiScale, Y: 0}, false, 0) {
+		imgui.PushItemWidth(-150 * view.guiScale)
+		view.renderLanguageSelection("Source Language", &view.model.sourceLang)
+		view.renderLanguageSelection("Target Language", &view.model.targetLang)
+		imgui.Separator()
+		if imgui.Button("Export PO...") {
+			view.startExport(false)
+		}
+		imgui.SameLine()
+		if imgui.Button("Export POT...") {
+			view.startExport(true)
+		}
+		if imgui.Button("Import PO...") {
+			view.startImport()
+		}
+		imgui.PopItemWidth()
+	}
+	imgui.EndChild()
+	imgui.SameLine()
+	if imgui.BeginChildV("Report", imgui.Vec2{X: 0, Y: 0}, true, 0) {
+		view.renderReport()
+	}
+	imgui.EndChild()
+}
+
+func (view *TranslationView) renderLanguageSelection(label string, lang *resource.Language) {
+	if imgui.BeginCombo(label, lang.String()) {
+		for _, other := range resource.Languages() {
+			if imgui.SelectableV(other.String(), other == *lang, 0, imgui.Vec2{}) {
+				*lang = other
+			}
+		}
+		imgui.EndCombo()
+	}
+}
+
+func (view *TranslationView) renderReport() {
+	if !view.model.imported {
+		imgui.Text("Export the texts for translation, then import the translated file.")
+		return
+	}
+	report := view.model.report
+	imgui.Text(fmt.Sprintf("%d translations applied.", len(report.Applied)))
+	renderEntries := func(title string, entries []string) {
+		if len(entries) == 0 {
+			return
+		}
+		if imgui.TreeNodeV(fmt.Sprintf("%s (%d)", title, len(entries)), 0) {
+			for _, entry := range entries {
+				imgui.Text(entry)
+			}
+			imgui.TreePop()
+		}
+	}
+	renderEntries("Fuzzy, skipped", report.Fuzzy)
+	renderEntries("Untranslated, skipped", report.Untranslated)
+	renderEntries("Unknown, skipped", report.Unknown)
+}
+
+func translationFileTypes(template bool) []external.TypeInfo {
+	if template {
+		return []external.TypeInfo{{Title: "Translation templates (*.pot)", Extensions: []string{"pot"}}}
+	}
+	return []external.TypeInfo{{Title: "Translations (*.po)", Extensions: []string{"po"}}}
+}
+
+func (view *TranslationView) startExport(template bool) {
+	catalog := view.service.Catalog(view.model.sourceLang, view.model.targetLang, template)
+	extension := ".po"
+	if template {
+		extension = ".pot"
+	}
+	external.SaveFile(view.modalStateMachine, translationFileTypes(template), func(filename string) error {
+		if !strings.EqualFold(filepath.Ext(filename), extension) {
+			filename += extension
+		}
+		file, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		err = po.Write(file, catalog)
+		closeErr := file.Close()
+		if err != nil {
+			return err
+		}
+		return closeErr
+	})
+}
+
+func (view *TranslationView) startImport() {
+	target := view.model.targetLang
+	external.LoadFile(view.modalStateMachine, translationFileTypes(false), func(filename string) error {
+		file, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		catalog, err := po.Read(file)
+		if err != nil {
+			return err
+		}
+		view.model.report = view.service.RequestTranslate(catalog, target, view.restoreFunc(target))
+		view.model.imported = true
+		return nil
+	})
+}
+
+func (view *TranslationView) restoreFunc(target resource.Language) func() {
+	return func() {
+		view.model.restoreFocus = true
+		view.model.targetLang = target
+	}
+}
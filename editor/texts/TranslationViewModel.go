@@ -0,0 +1,24 @@
+package texts
+
+import (
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+type translationViewModel struct {
+	restoreFocus bool
+	windowOpen   bool
+
+	sourceLang resource.Language
+	targetLang resource.Language
+
+	imported bool
+	report   edit.TranslationReport
+}
+
+func freshTranslationViewModel() translationViewModel {
+	return translationViewModel{
+		sourceLang: resource.LangDefault,
+		targetLang: resource.LangGerman,
+	}
+}
@@ -6,17 +6,21 @@ import (
 	"github.com/inkyblackness/imgui-go/v3"
 
 	"github.com/inkyblackness/hacked/editor/external"
+	"github.com/inkyblackness/hacked/editor/render"
 	"github.com/inkyblackness/hacked/ss1/content/audio"
 	"github.com/inkyblackness/hacked/ss1/edit"
 	"github.com/inkyblackness/hacked/ss1/edit/undoable"
 	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
 	"github.com/inkyblackness/hacked/ss1/world/ids"
 	"github.com/inkyblackness/hacked/ui/gui"
 )
 
 // View provides edit controls for texts.
 type View struct {
-	textService undoable.AugmentedTextService
+	mod           *world.Mod
+	freeResources edit.FreeResourceService
+	textService   undoable.AugmentedTextService
 
 	modalStateMachine gui.ModalStateMachine
 	clipboard         external.Clipboard
@@ -26,11 +30,13 @@ type View struct {
 }
 
 // NewTextsView returns a new instance.
-func NewTextsView(textService undoable.AugmentedTextService,
+func NewTextsView(mod *world.Mod, freeResources edit.FreeResourceService, textService undoable.AugmentedTextService,
 	modalStateMachine gui.ModalStateMachine, clipboard external.Clipboard,
 	guiScale float32) *View {
 	view := &View{
-		textService: textService,
+		mod:           mod,
+		freeResources: freeResources,
+		textService:   textService,
 
 		modalStateMachine: modalStateMachine,
 		clipboard:         clipboard,
@@ -46,6 +52,12 @@ func (view *View) WindowOpen() *bool {
 	return &view.model.windowOpen
 }
 
+// ShowText opens the window and selects the identified text.
+func (view *View) ShowText(key resource.Key) {
+	view.model.currentKey = key
+	view.model.restoreFocus = true
+}
+
 // Render renders the view.
 func (view *View) Render() {
 	if view.model.restoreFocus {
@@ -76,6 +88,15 @@ func (view *View) renderContent() {
 	}
 	info, _ := ids.Info(view.model.currentKey.ID)
 	gui.StepSliderInt("Index", &view.model.currentKey.Index, 0, info.MaxCount-1)
+	if imgui.Button("Add New") {
+		index, found := view.freeResources.FirstFreeIndex(info)
+		if found {
+			view.model.currentKey.Index = index
+		}
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Selects the first index that is not used by any language.")
+	}
 
 	if imgui.BeginCombo("Language", view.model.currentKey.Lang.String()) {
 		languages := resource.Languages()
@@ -103,6 +124,7 @@ func (view *View) renderContent() {
 			view.requestImportAudio()
 		}
 	}
+	render.ResourceSources(view.mod, view.currentResourceKey())
 	imgui.Separator()
 
 	imgui.PopItemWidth()
@@ -196,3 +218,12 @@ func (view *View) restoreFunc() func() {
 		view.model.currentKey = oldKey
 	}
 }
+
+func (view *View) currentResourceKey() resource.Key {
+	key := view.model.currentKey
+	info, _ := ids.Info(key.ID)
+	if info.List {
+		return key
+	}
+	return resource.KeyOf(key.ID.Plus(key.Index), key.Lang, 0)
+}
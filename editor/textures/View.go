@@ -63,6 +63,13 @@ func (view *View) WindowOpen() *bool {
 	return &view.model.windowOpen
 }
 
+// ShowTexture opens the window and selects the identified texture, with its texts in given language.
+func (view *View) ShowTexture(index int, lang resource.Language) {
+	view.model.currentIndex = index
+	view.model.currentLang = lang
+	view.model.restoreFocus = true
+}
+
 // Render renders the view.
 func (view *View) Render() {
 	if view.model.restoreFocus {
@@ -119,6 +126,9 @@ func (view *View) renderContent() {
 		imgui.Separator()
 		view.renderTextureProperties(readOnly)
 
+		imgui.Separator()
+		render.ResourceSources(view.mod, view.currentResourceKey())
+
 		imgui.PopItemWidth()
 	}
 	imgui.EndChild()
@@ -1,6 +1,8 @@
 package level
 
 import (
+	"fmt"
+
 	"github.com/inkyblackness/hacked/ss1/content/object"
 	"github.com/inkyblackness/hacked/ss1/serial"
 )
@@ -84,7 +86,7 @@ func (table ObjectClassTable) Reset() {
 // Code serializes the table with the provided coder.
 func (table ObjectClassTable) Code(coder serial.Coder) {
 	for i := 0; i < len(table); i++ {
-		table[i].Code(coder)
+		serial.CodeField(coder, fmt.Sprintf("Entry[%d]", i), &table[i])
 	}
 }
 
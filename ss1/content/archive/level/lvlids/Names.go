@@ -0,0 +1,49 @@
+package lvlids
+
+import "fmt"
+
+var names = map[int]string{
+	MapVersionNumber:    "MapVersionNumber",
+	ObjectVersionNumber: "ObjectVersionNumber",
+	Information:         "Information",
+
+	TileMap:             "TileMap",
+	Schedules:           "Schedules",
+	TextureAtlas:        "TextureAtlas",
+	ObjectMainTable:     "ObjectMainTable",
+	ObjectCrossRefTable: "ObjectCrossRefTable",
+
+	SavefileVersion: "SavefileVersion",
+	Unused41:        "Unused41",
+
+	TextureAnimations:      "TextureAnimations",
+	SurveillanceSources:    "SurveillanceSources",
+	SurveillanceSurrogates: "SurveillanceSurrogates",
+	Parameters:             "Parameters",
+	MapNotes:               "MapNotes",
+	MapNotesPointer:        "MapNotesPointer",
+
+	Unknown48: "Unknown48",
+	Unknown49: "Unknown49",
+	Unknown50: "Unknown50",
+
+	LoopConfiguration: "LoopConfiguration",
+
+	Unknown52:        "Unknown52",
+	HeightSemaphores: "HeightSemaphores",
+}
+
+// Name returns a textual representation of the given level resource identifier.
+// Identifier outside the used range are named by their number.
+func Name(id int) string {
+	if name, known := names[id]; known {
+		return name
+	}
+	if (id >= ObjectClassTablesStart) && (id < ObjectDefaultTablesStart) {
+		return fmt.Sprintf("ObjectClassTable%d", id-ObjectClassTablesStart)
+	}
+	if (id >= ObjectDefaultTablesStart) && (id < SavefileVersion) {
+		return fmt.Sprintf("ObjectDefaultTable%d", id-ObjectDefaultTablesStart)
+	}
+	return fmt.Sprintf("Resource%d", id)
+}
@@ -0,0 +1,201 @@
+package font
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/inkyblackness/hacked/ss1"
+)
+
+const (
+	errDataTooShort          ss1.StringError = "data too short for font"
+	errCharacterRangeInvalid ss1.StringError = "character range is invalid"
+	errOffsetsInvalid        ss1.StringError = "glyph offsets are invalid"
+	errGlyphSizeInvalid      ss1.StringError = "glyph size does not match font height"
+)
+
+// Type describes how the pixels of a font are stored.
+type Type uint16
+
+// Type constants are listed below.
+const (
+	// TypeColor fonts store one palette index per pixel.
+	TypeColor Type = 0x0000
+	// TypeMonochrome fonts store one bit per pixel. Set pixels are drawn in the current color.
+	TypeMonochrome Type = 0xCCCC
+)
+
+// HeaderSize is the size of the Header structure, in bytes.
+const HeaderSize = 84
+
+// Header contains the meta information for a font.
+type Header struct {
+	Type           Type
+	Unknown0002    [34]byte
+	FirstCharacter int16
+	LastCharacter  int16
+	Unknown0028    [32]byte
+	// OffsetTableOffset is the position of the table of horizontal glyph offsets, relative to the start of the font.
+	OffsetTableOffset int32
+	// BitmapOffset is the position of the strike, relative to the start of the font.
+	BitmapOffset int32
+	// Stride is the number of bytes per row of the strike.
+	Stride int16
+	// Height is the number of rows of the strike, which is the height of all glyphs.
+	Height int16
+}
+
+// Glyph is the image of a single character.
+type Glyph struct {
+	// Width is the number of columns of the glyph. Characters that are not available have a width of zero.
+	Width int
+	// Pixels contain the rows of the glyph, from top to bottom. Monochrome glyphs use 0 and 1.
+	Pixels []byte
+}
+
+// Font describes a set of glyphs of equal height.
+type Font struct {
+	Type           Type
+	FirstCharacter int
+	Height         int
+	// Glyphs are the characters starting with FirstCharacter.
+	Glyphs []Glyph
+
+	// Unknown0002 and Unknown0028 are the raw bytes of the header areas of which the meaning is unknown.
+	// They are kept as they were decoded, and written back when encoding. New fonts have them zeroed.
+	Unknown0002 [34]byte
+	Unknown0028 [32]byte
+}
+
+// LastCharacter returns the code of the last character that has a glyph.
+func (font Font) LastCharacter() int {
+	return font.FirstCharacter + len(font.Glyphs) - 1
+}
+
+// Glyph returns the glyph of given character code. Characters outside the font return an empty glyph.
+func (font Font) Glyph(character int) Glyph {
+	index := character - font.FirstCharacter
+	if (index < 0) || (index >= len(font.Glyphs)) {
+		return Glyph{}
+	}
+	return font.Glyphs[index]
+}
+
+// Decode reads a font from given data.
+func Decode(data []byte) (*Font, error) {
+	var header Header
+	err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header)
+	if err != nil {
+		return nil, errDataTooShort
+	}
+	glyphCount := int(header.LastCharacter) - int(header.FirstCharacter) + 1
+	if (header.FirstCharacter < 0) || (glyphCount < 0) || (header.Height < 0) || (header.Stride < 0) {
+		return nil, errCharacterRangeInvalid
+	}
+	offsets := make([]int16, glyphCount+1)
+	if (header.OffsetTableOffset < 0) || (int(header.OffsetTableOffset)+len(offsets)*2 > len(data)) {
+		return nil, errDataTooShort
+	}
+	_ = binary.Read(bytes.NewReader(data[header.OffsetTableOffset:]), binary.LittleEndian, offsets)
+	height := int(header.Height)
+	stride := int(header.Stride)
+	strikeEnd := int(header.BitmapOffset) + stride*height
+	if (header.BitmapOffset < 0) || (strikeEnd > len(data)) {
+		return nil, errDataTooShort
+	}
+	strike := data[header.BitmapOffset:strikeEnd]
+	strikeWidth := stride
+	if header.Type == TypeMonochrome {
+		strikeWidth *= 8
+	}
+
+	font := &Font{
+		Type:           header.Type,
+		FirstCharacter: int(header.FirstCharacter),
+		Height:         height,
+		Glyphs:         make([]Glyph, glyphCount),
+		Unknown0002:    header.Unknown0002,
+		Unknown0028:    header.Unknown0028,
+	}
+	for index := range font.Glyphs {
+		left, right := int(offsets[index]), int(offsets[index+1])
+		if (left < 0) || (right < left) || (right > strikeWidth) {
+			return nil, errOffsetsInvalid
+		}
+		glyph := Glyph{Width: right - left, Pixels: make([]byte, (right-left)*height)}
+		for y := 0; y < height; y++ {
+			row := strike[y*stride : (y+1)*stride]
+			for x := 0; x < glyph.Width; x++ {
+				glyph.Pixels[y*glyph.Width+x] = strikePixel(header.Type, row, left+x)
+			}
+		}
+		font.Glyphs[index] = glyph
+	}
+	return font, nil
+}
+
+// Encode serializes the font.
+func Encode(font Font) ([]byte, error) {
+	if (font.FirstCharacter < 0) || (font.LastCharacter() > 0x7FFF) {
+		return nil, errCharacterRangeInvalid
+	}
+	offsets := make([]int16, len(font.Glyphs)+1)
+	strikeWidth := 0
+	for index, glyph := range font.Glyphs {
+		if (glyph.Width < 0) || (len(glyph.Pixels) != glyph.Width*font.Height) {
+			return nil, errGlyphSizeInvalid
+		}
+		offsets[index] = int16(strikeWidth)
+		strikeWidth += glyph.Width
+		if strikeWidth > 0x7FFF {
+			return nil, errOffsetsInvalid
+		}
+	}
+	offsets[len(font.Glyphs)] = int16(strikeWidth)
+	stride := strikeWidth
+	if font.Type == TypeMonochrome {
+		stride = (strikeWidth + 7) / 8
+	}
+
+	header := Header{
+		Type:              font.Type,
+		Unknown0002:       font.Unknown0002,
+		FirstCharacter:    int16(font.FirstCharacter),
+		LastCharacter:     int16(font.LastCharacter()),
+		Unknown0028:       font.Unknown0028,
+		OffsetTableOffset: HeaderSize,
+		BitmapOffset:      int32(HeaderSize + len(offsets)*2),
+		Stride:            int16(stride),
+		Height:            int16(font.Height),
+	}
+	strike := make([]byte, stride*font.Height)
+	for index, glyph := range font.Glyphs {
+		for y := 0; y < font.Height; y++ {
+			row := strike[y*stride : (y+1)*stride]
+			for x := 0; x < glyph.Width; x++ {
+				setStrikePixel(font.Type, row, int(offsets[index])+x, glyph.Pixels[y*glyph.Width+x])
+			}
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	_ = binary.Write(buf, binary.LittleEndian, &header)
+	_ = binary.Write(buf, binary.LittleEndian, offsets)
+	_ = binary.Write(buf, binary.LittleEndian, strike)
+	return buf.Bytes(), nil
+}
+
+func strikePixel(fontType Type, row []byte, x int) byte {
+	if fontType != TypeMonochrome {
+		return row[x]
+	}
+	return (row[x/8] >> (7 - uint(x%8))) & 0x01
+}
+
+func setStrikePixel(fontType Type, row []byte, x int, value byte) {
+	if fontType != TypeMonochrome {
+		row[x] = value
+	} else if value != 0 {
+		row[x/8] |= 0x80 >> uint(x%8)
+	}
+}
@@ -0,0 +1,122 @@
+package font_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/font"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeReturnsErrorOnShortData(t *testing.T) {
+	_, err := font.Decode([]byte{0x00, 0x00})
+
+	assert.Error(t, err, "error expected")
+}
+
+func TestEncodeMonochromeFont(t *testing.T) {
+	data, err := font.Encode(aMonochromeFont())
+	require.Nil(t, err, "no error expected")
+
+	require.Equal(t, font.HeaderSize+3*2+2, len(data), "size mismatch")
+	assert.Equal(t, []byte{0xCC, 0xCC}, data[0:2], "type mismatch")
+	assert.Equal(t, []byte{0x41, 0x00, 0x42, 0x00}, data[0x24:0x28], "character range mismatch")
+	assert.Equal(t, []byte{0x01, 0x00, 0x02, 0x00}, data[0x50:0x54], "stride and height mismatch")
+	assert.Equal(t, []byte{0x00, 0x00, 0x02, 0x00, 0x03, 0x00}, data[0x54:0x5A], "offsets mismatch")
+	assert.Equal(t, []byte{0xA0, 0x60}, data[0x5A:], "strike mismatch")
+}
+
+func TestDecodeEncodedFonts(t *testing.T) {
+	fonts := []font.Font{
+		aMonochromeFont(),
+		{
+			Type:           font.TypeColor,
+			FirstCharacter: 0x20,
+			Height:         1,
+			Glyphs: []font.Glyph{
+				{Width: 0, Pixels: []byte{}},
+				{Width: 3, Pixels: []byte{0x10, 0x20, 0x30}},
+			},
+		},
+	}
+	for _, expected := range fonts {
+		data, err := font.Encode(expected)
+		require.Nil(t, err, "no error expected encoding")
+		decoded, err := font.Decode(data)
+		require.Nil(t, err, "no error expected decoding")
+		assert.Equal(t, expected, *decoded, "font should be restored")
+	}
+}
+
+func TestEncodeKeepsUnknownHeaderAreas(t *testing.T) {
+	original := aMonochromeFont()
+	original.Unknown0002[0] = 0x12
+	original.Unknown0028[31] = 0x34
+	data, err := font.Encode(original)
+	require.Nil(t, err, "no error expected encoding")
+
+	assert.Equal(t, byte(0x12), data[0x02], "first area mismatch")
+	assert.Equal(t, byte(0x34), data[0x47], "second area mismatch")
+	decoded, err := font.Decode(data)
+	require.Nil(t, err, "no error expected decoding")
+	assert.Equal(t, original, *decoded, "font should be restored")
+}
+
+func TestEncodeReturnsErrorForWrongGlyphSize(t *testing.T) {
+	invalid := aMonochromeFont()
+	invalid.Glyphs[0].Pixels = []byte{0x01}
+
+	_, err := font.Encode(invalid)
+
+	assert.Error(t, err, "error expected")
+}
+
+func TestSheetCanBeConvertedBack(t *testing.T) {
+	original := aMonochromeFont()
+	sheet := original.Sheet(0xFF)
+
+	require.Equal(t, int16(3*font.SheetColumns), sheet.Header.Width, "cells should be at least as wide as high")
+	require.Equal(t, int16(3*font.SheetRows), sheet.Header.Height, "cells should have a marker row")
+	restored, err := font.FromSheet(sheet, font.TypeMonochrome, 0xFF)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, original, *restored, "font should be restored")
+}
+
+func TestFromSheetTakesAddedGlyphs(t *testing.T) {
+	original := aMonochromeFont()
+	sheet := original.Sheet(0xFF)
+	width := int(sheet.Header.Width)
+	top := (0x44 / font.SheetColumns) * 3
+	left := (0x44 % font.SheetColumns) * 3
+	sheet.Pixels[top*width+left] = 0xFF
+	sheet.Pixels[(top+2)*width+left] = 0xFF
+
+	restored, err := font.FromSheet(sheet, font.TypeMonochrome, 0xFF)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, 0x41, restored.FirstCharacter, "first character should remain")
+	assert.Equal(t, 0x44, restored.LastCharacter(), "last character should be extended")
+	assert.Equal(t, font.Glyph{}.Width, restored.Glyph(0x43).Width, "gap should have no width")
+	assert.Equal(t, font.Glyph{Width: 1, Pixels: []byte{0, 1}}, restored.Glyph(0x44), "new glyph expected")
+}
+
+func TestFromSheetReturnsErrorForInvalidSize(t *testing.T) {
+	sheet := aMonochromeFont().Sheet(0xFF)
+	sheet.Header.Width--
+
+	_, err := font.FromSheet(sheet, font.TypeMonochrome, 0xFF)
+
+	assert.Error(t, err, "error expected")
+}
+
+func aMonochromeFont() font.Font {
+	return font.Font{
+		Type:           font.TypeMonochrome,
+		FirstCharacter: 0x41,
+		Height:         2,
+		Glyphs: []font.Glyph{
+			{Width: 2, Pixels: []byte{1, 0, 0, 1}},
+			{Width: 1, Pixels: []byte{1, 1}},
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package font
+
+// Width returns the number of pixels the given characters take up in one line.
+// Characters without a glyph have no width.
+func (font Font) Width(characters []byte) int {
+	width := 0
+	for _, character := range characters {
+		width += font.Glyph(int(character)).Width
+	}
+	return width
+}
+
+// Wrap splits the given characters into lines that fit the given width.
+// Lines are broken at line feeds and between words. A word that is wider than the width on its own
+// is kept in one line, which then exceeds the width. Trailing line feeds do not start new lines.
+func (font Font) Wrap(characters []byte, width int) [][]byte {
+	var lines [][]byte
+	for (len(characters) > 0) && (characters[len(characters)-1] == '\n') {
+		characters = characters[:len(characters)-1]
+	}
+	for _, paragraph := range splitBytes(characters, '\n') {
+		var line []byte
+		for _, word := range splitBytes(paragraph, ' ') {
+			if len(line) == 0 {
+				line = word
+				continue
+			}
+			extended := append(append(append([]byte{}, line...), ' '), word...)
+			if font.Width(extended) > width {
+				lines = append(lines, line)
+				line = word
+			} else {
+				line = extended
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func splitBytes(characters []byte, separator byte) [][]byte {
+	var parts [][]byte
+	start := 0
+	for index, character := range characters {
+		if character == separator {
+			parts = append(parts, characters[start:index])
+			start = index + 1
+		}
+	}
+	return append(parts, characters[start:])
+}
@@ -0,0 +1,47 @@
+package font_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/font"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWidthSumsGlyphWidths(t *testing.T) {
+	fnt := aWrappingFont()
+
+	assert.Equal(t, 5, fnt.Width([]byte("aa b")), "width mismatch")
+	assert.Equal(t, 0, fnt.Width([]byte("zz")), "characters without glyph should have no width")
+}
+
+func TestWrapBreaksBetweenWords(t *testing.T) {
+	fnt := aWrappingFont()
+
+	lines := fnt.Wrap([]byte("aa b aa\nb\n"), 5)
+
+	assert.Equal(t, [][]byte{[]byte("aa b"), []byte("aa"), []byte("b")}, lines)
+}
+
+func TestWrapKeepsWideWordsInOneLine(t *testing.T) {
+	fnt := aWrappingFont()
+
+	lines := fnt.Wrap([]byte("b aaaa"), 3)
+
+	assert.Equal(t, [][]byte{[]byte("b"), []byte("aaaa")}, lines)
+}
+
+// aWrappingFont returns a font with a space of width 1, 'a' of width 1, and 'b' of width 2.
+func aWrappingFont() font.Font {
+	glyph := func(width int) font.Glyph {
+		return font.Glyph{Width: width, Pixels: make([]byte, width)}
+	}
+	glyphs := make([]font.Glyph, 'b'-' '+1)
+	for index := range glyphs {
+		glyphs[index] = glyph(0)
+	}
+	glyphs[0] = glyph(1)
+	glyphs['a'-' '] = glyph(1)
+	glyphs['b'-' '] = glyph(2)
+	return font.Font{Type: font.TypeMonochrome, FirstCharacter: ' ', Height: 1, Glyphs: glyphs}
+}
@@ -0,0 +1,124 @@
+package font
+
+import (
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+)
+
+const (
+	errSheetSizeInvalid ss1.StringError = "sheet size is not a grid of 16 by 16 cells"
+	errSheetEmpty       ss1.StringError = "sheet does not contain any glyph"
+)
+
+// SheetColumns is the number of cells per row of a glyph sheet.
+const SheetColumns = 16
+
+// SheetRows is the number of cell rows of a glyph sheet.
+const SheetRows = 16
+
+// Sheet returns a bitmap with the glyphs of all 256 character codes, arranged in a grid of cells.
+// The character code of a cell is its row times SheetColumns, plus its column.
+//
+// The top row of each cell marks the width of the glyph: the leading pixels of this row are set with
+// the marker palette index, the remaining ones are zero. The glyph itself is placed below, at the left side
+// of the cell. Pixels of monochrome fonts are set with the marker as well.
+// Cells are at least as wide as they are high, leaving room to draw wider glyphs.
+func (font Font) Sheet(marker byte) bitmap.Bitmap {
+	cellWidth := font.Height
+	for _, glyph := range font.Glyphs {
+		if glyph.Width > cellWidth {
+			cellWidth = glyph.Width
+		}
+	}
+	cellWidth++
+	cellHeight := font.Height + 1
+	width := cellWidth * SheetColumns
+	height := cellHeight * SheetRows
+	bmp := bitmap.Bitmap{
+		Header: bitmap.Header{
+			Type:   bitmap.TypeFlat8Bit,
+			Width:  int16(width),
+			Height: int16(height),
+			Stride: uint16(width),
+		},
+		Pixels: make([]byte, width*height),
+	}
+	for character := 0; character < SheetColumns*SheetRows; character++ {
+		glyph := font.Glyph(character)
+		left := (character % SheetColumns) * cellWidth
+		top := (character / SheetColumns) * cellHeight
+		for x := 0; x < glyph.Width; x++ {
+			bmp.Pixels[top*width+left+x] = marker
+		}
+		for y := 0; y < font.Height; y++ {
+			for x := 0; x < glyph.Width; x++ {
+				value := glyph.Pixels[y*glyph.Width+x]
+				if (font.Type == TypeMonochrome) && (value != 0) {
+					value = marker
+				}
+				bmp.Pixels[(top+1+y)*width+left+x] = value
+			}
+		}
+	}
+	return bmp
+}
+
+// FromSheet creates a font from a glyph sheet, as created by Sheet().
+// The character range of the font covers all cells that have a glyph width. The height of the font is
+// determined by the height of the cells.
+// For monochrome fonts, only pixels with the marker palette index are set.
+func FromSheet(sheet bitmap.Bitmap, fontType Type, marker byte) (*Font, error) {
+	width := int(sheet.Header.Width)
+	height := int(sheet.Header.Height)
+	if (width%SheetColumns != 0) || (height%SheetRows != 0) || (height < SheetRows*2) ||
+		(len(sheet.Pixels) < width*height) {
+		return nil, errSheetSizeInvalid
+	}
+	cellWidth := width / SheetColumns
+	cellHeight := height / SheetRows
+	fontHeight := cellHeight - 1
+	pixel := func(x, y int) byte {
+		return sheet.Pixels[y*width+x]
+	}
+
+	var glyphs [SheetColumns * SheetRows]Glyph
+	first, last := -1, -1
+	for character := range glyphs {
+		left := (character % SheetColumns) * cellWidth
+		top := (character / SheetColumns) * cellHeight
+		glyphWidth := 0
+		for (glyphWidth < cellWidth) && (pixel(left+glyphWidth, top) == marker) {
+			glyphWidth++
+		}
+		glyph := Glyph{Width: glyphWidth, Pixels: make([]byte, glyphWidth*fontHeight)}
+		for y := 0; y < fontHeight; y++ {
+			for x := 0; x < glyphWidth; x++ {
+				value := pixel(left+x, top+1+y)
+				if fontType == TypeMonochrome {
+					if value == marker {
+						value = 1
+					} else {
+						value = 0
+					}
+				}
+				glyph.Pixels[y*glyphWidth+x] = value
+			}
+		}
+		glyphs[character] = glyph
+		if glyphWidth > 0 {
+			if first < 0 {
+				first = character
+			}
+			last = character
+		}
+	}
+	if first < 0 {
+		return nil, errSheetEmpty
+	}
+	return &Font{
+		Type:           fontType,
+		FirstCharacter: first,
+		Height:         fontHeight,
+		Glyphs:         append([]Glyph{}, glyphs[first:last+1]...),
+	}, nil
+}
@@ -0,0 +1,9 @@
+// Package font handles the fonts of the game.
+//
+// A font is stored as a header, followed by a table of horizontal offsets and a bitmap strike.
+// The strike is one bitmap that contains all glyphs next to each other. The offset table marks where each
+// glyph starts within the strike, which also determines the width of each glyph.
+//
+// To edit fonts with external tools, a font can be converted to a glyph sheet: a bitmap with a grid of
+// cells, one for each character code.
+package font
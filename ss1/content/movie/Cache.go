@@ -56,6 +56,11 @@ func (cache *Cache) InvalidateResources(ids []resource.ID) {
 	}
 }
 
+// InvalidateAll lets the cache remove all movies, for example after the codepage changed.
+func (cache *Cache) InvalidateAll() {
+	cache.movies = make(map[resource.Key]*cachedMovie)
+}
+
 func (cache *Cache) cached(key resource.Key) (*cachedMovie, error) {
 	value, existing := cache.movies[key]
 	if existing {
@@ -1,6 +1,8 @@
 package object
 
 import (
+	"fmt"
+
 	"github.com/inkyblackness/hacked/ss1"
 	"github.com/inkyblackness/hacked/ss1/serial"
 )
@@ -81,25 +83,26 @@ func (table PropertiesTable) ForObject(triple Triple) (*Properties, error) {
 }
 
 // Code serializes the table with given coder.
+// The fields are reported with the class, subclass, and type of the object they belong to.
 func (table PropertiesTable) Code(coder serial.Coder) {
 	version := propertiesFileVersion
-	coder.Code(&version)
-	for _, subclasses := range table {
-		for _, types := range subclasses {
-			for _, prop := range types {
-				coder.Code(prop.Generic)
+	serial.CodeField(coder, "Version", &version)
+	for class, subclasses := range table {
+		for subclass, types := range subclasses {
+			for objType, prop := range types {
+				serial.CodeField(coder, fmt.Sprintf("Generic[%d/%d/%d]", class, subclass, objType), prop.Generic)
 			}
 		}
-		for _, types := range subclasses {
-			for _, prop := range types {
-				coder.Code(prop.Specific)
+		for subclass, types := range subclasses {
+			for objType, prop := range types {
+				serial.CodeField(coder, fmt.Sprintf("Specific[%d/%d/%d]", class, subclass, objType), prop.Specific)
 			}
 		}
 	}
-	for _, subclasses := range table {
-		for _, types := range subclasses {
+	for class, subclasses := range table {
+		for subclass, types := range subclasses {
 			for objType := 0; objType < len(types); objType++ {
-				coder.Code(&types[objType].Common)
+				serial.CodeField(coder, fmt.Sprintf("Common[%d/%d/%d]", class, subclass, objType), &types[objType].Common)
 			}
 		}
 	}
@@ -0,0 +1,183 @@
+package preview
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+
+	"github.com/inkyblackness/hacked/ss1"
+	"github.com/inkyblackness/hacked/ss1/content/audio/voc"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/serial"
+)
+
+const (
+	errWrongKind        ss1.StringError = "preview is of wrong kind"
+	errPaletteSizeWrong ss1.StringError = "palette must have 256 colors"
+)
+
+// Codec converts between the serialized data of a resource block and its preview.
+type Codec interface {
+	// Decode returns the preview of given block data.
+	Decode(data []byte) (Preview, error)
+	// Encode returns the block data for given preview.
+	Encode(preview Preview) ([]byte, error)
+}
+
+// BinaryCodec provides the raw data as preview.
+type BinaryCodec struct{}
+
+// Decode returns a binary preview.
+func (codec BinaryCodec) Decode(data []byte) (Preview, error) {
+	return Preview{Kind: KindBinary, Data: data}, nil
+}
+
+// Encode returns the data of a binary preview.
+func (codec BinaryCodec) Encode(preview Preview) ([]byte, error) {
+	if preview.Kind != KindBinary {
+		return nil, errWrongKind
+	}
+	return preview.Data, nil
+}
+
+// TextCodec handles texts, serialized with a codepage.
+type TextCodec struct {
+	Codepage text.Codepage
+}
+
+// Decode returns a text preview.
+func (codec TextCodec) Decode(data []byte) (Preview, error) {
+	return Preview{Kind: KindText, Text: codec.Codepage.Decode(data)}, nil
+}
+
+// Encode returns the serialized text.
+func (codec TextCodec) Encode(preview Preview) ([]byte, error) {
+	if preview.Kind != KindText {
+		return nil, errWrongKind
+	}
+	return codec.Codepage.Encode(preview.Text), nil
+}
+
+// BitmapCodec handles bitmaps.
+// Decoded images use the palette of the bitmap, if it has one. Otherwise the palette of the codec is used.
+// Without either, a grayscale palette is used.
+type BitmapCodec struct {
+	Palette *bitmap.Palette
+}
+
+// Decode returns an image preview.
+func (codec BitmapCodec) Decode(data []byte) (Preview, error) {
+	bmp, err := bitmap.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Preview{}, err
+	}
+	palette := bmp.Palette
+	if palette == nil {
+		palette = codec.Palette
+	}
+	transparent := (bmp.Header.Flags & bitmap.FlagTransparent) != 0
+	img := &image.Paletted{
+		Pix:     bmp.Pixels,
+		Stride:  int(bmp.Header.Stride),
+		Rect:    image.Rect(0, 0, int(bmp.Header.Width), int(bmp.Header.Height)),
+		Palette: colorPaletteOf(palette, transparent),
+	}
+	return Preview{Kind: KindImage, Image: img}, nil
+}
+
+// Encode returns the serialized bitmap, without palette, and uncompressed.
+// The flags of the original bitmap, such as transparency, are not part of the preview and are not restored.
+func (codec BitmapCodec) Encode(preview Preview) ([]byte, error) {
+	if (preview.Kind != KindImage) || (preview.Image == nil) {
+		return nil, errWrongKind
+	}
+	size := preview.Image.Rect.Size()
+	bmp := bitmap.Bitmap{
+		Header: bitmap.Header{
+			Type:   bitmap.TypeFlat8Bit,
+			Width:  int16(size.X),
+			Height: int16(size.Y),
+			Stride: uint16(size.X),
+		},
+		Pixels: make([]byte, 0, size.X*size.Y),
+	}
+	for y := preview.Image.Rect.Min.Y; y < preview.Image.Rect.Max.Y; y++ {
+		start := preview.Image.PixOffset(preview.Image.Rect.Min.X, y)
+		bmp.Pixels = append(bmp.Pixels, preview.Image.Pix[start:start+size.X]...)
+	}
+	return bitmap.Encode(&bmp, 0), nil
+}
+
+// PaletteCodec handles palettes. A palette is previewed as an image of 16x16 pixels, showing each color.
+type PaletteCodec struct{}
+
+// Decode returns an image preview.
+func (codec PaletteCodec) Decode(data []byte) (Preview, error) {
+	var palette bitmap.Palette
+	decoder := serial.NewDecoder(bytes.NewReader(data))
+	decoder.Code(&palette)
+	if decoder.FirstError() != nil {
+		return Preview{}, decoder.FirstError()
+	}
+	img := image.NewPaletted(image.Rect(0, 0, 16, 16), palette.ColorPalette(false))
+	for index := range img.Pix {
+		img.Pix[index] = byte(index)
+	}
+	return Preview{Kind: KindImage, Image: img}, nil
+}
+
+// Encode returns the palette of the image.
+func (codec PaletteCodec) Encode(preview Preview) ([]byte, error) {
+	if (preview.Kind != KindImage) || (preview.Image == nil) {
+		return nil, errWrongKind
+	}
+	if len(preview.Image.Palette) != bitmap.PaletteSize {
+		return nil, errPaletteSizeWrong
+	}
+	data := make([]byte, 0, bitmap.PaletteSize*3)
+	for _, clr := range preview.Image.Palette {
+		rgba := color.RGBAModel.Convert(clr).(color.RGBA)
+		data = append(data, rgba.R, rgba.G, rgba.B)
+	}
+	return data, nil
+}
+
+// SoundCodec handles sound samples, serialized in VOC format.
+type SoundCodec struct{}
+
+// Decode returns an audio preview.
+func (codec SoundCodec) Decode(data []byte) (Preview, error) {
+	sound, err := voc.Load(bytes.NewReader(data))
+	if err != nil {
+		return Preview{}, err
+	}
+	return Preview{Kind: KindAudio, Audio: sound}, nil
+}
+
+// Encode returns the sound in VOC format.
+func (codec SoundCodec) Encode(preview Preview) ([]byte, error) {
+	if preview.Kind != KindAudio {
+		return nil, errWrongKind
+	}
+	buf := bytes.NewBuffer(nil)
+	err := voc.Save(buf, preview.Audio.SampleRate, preview.Audio.Samples)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func colorPaletteOf(palette *bitmap.Palette, firstIndexTransparent bool) color.Palette {
+	if palette != nil {
+		return palette.ColorPalette(firstIndexTransparent)
+	}
+	result := make(color.Palette, bitmap.PaletteSize)
+	for index := range result {
+		result[index] = color.Gray{Y: byte(index)}
+	}
+	if firstIndexTransparent {
+		result[0] = color.Transparent
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package preview_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/audio"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/content/preview"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecsEncodeWhatTheyDecode(t *testing.T) {
+	var palette bitmap.Palette
+	palette[1] = bitmap.RGB{Red: 0x10, Green: 0x20, Blue: 0x30}
+	img := image.NewPaletted(image.Rect(0, 0, 3, 2), palette.ColorPalette(false))
+	copy(img.Pix, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06})
+	paletteImage := image.NewPaletted(image.Rect(0, 0, 16, 16), palette.ColorPalette(false))
+	for index := range paletteImage.Pix {
+		paletteImage.Pix[index] = byte(index)
+	}
+
+	tt := []struct {
+		name    string
+		codec   preview.Codec
+		preview preview.Preview
+	}{
+		{name: "binary", codec: preview.BinaryCodec{}, preview: preview.Preview{Kind: preview.KindBinary, Data: []byte{0x01}}},
+		{name: "text", codec: preview.TextCodec{Codepage: text.DefaultCodepage()},
+			preview: preview.Preview{Kind: preview.KindText, Text: "some text"}},
+		{name: "bitmap", codec: preview.BitmapCodec{Palette: &palette},
+			preview: preview.Preview{Kind: preview.KindImage, Image: img}},
+		{name: "palette", codec: preview.PaletteCodec{},
+			preview: preview.Preview{Kind: preview.KindImage, Image: paletteImage}},
+		{name: "sound", codec: preview.SoundCodec{},
+			preview: preview.Preview{Kind: preview.KindAudio, Audio: audio.L8{SampleRate: 1000000.0 / 90, Samples: []byte{0x80, 0x81}}}},
+	}
+	for _, tc := range tt {
+		td := tc
+		t.Run(td.name, func(t *testing.T) {
+			data, err := td.codec.Encode(td.preview)
+			require.Nil(t, err, "no error expected encoding")
+			decoded, err := td.codec.Decode(data)
+			require.Nil(t, err, "no error expected decoding")
+			assert.Equal(t, td.preview, decoded)
+		})
+	}
+}
+
+func TestCodecsRejectPreviewsOfWrongKind(t *testing.T) {
+	_, err := preview.SoundCodec{}.Encode(preview.Preview{Kind: preview.KindText, Text: "text"})
+	assert.NotNil(t, err, "error expected")
+}
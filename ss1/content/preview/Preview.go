@@ -0,0 +1,83 @@
+package preview
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/inkyblackness/hacked/ss1/content/audio"
+	"github.com/inkyblackness/hacked/ss1/content/audio/wav"
+)
+
+// Kind describes the representation of a preview.
+type Kind byte
+
+// Kind constants are listed below.
+const (
+	// KindBinary is for content that has no further interpretation. The preview provides the raw data.
+	KindBinary Kind = 0
+	// KindText is for human readable text.
+	KindText Kind = 1
+	// KindImage is for palette based images.
+	KindImage Kind = 2
+	// KindAudio is for sound samples.
+	KindAudio Kind = 3
+)
+
+// String returns the textual representation of the kind.
+func (kind Kind) String() string {
+	switch kind {
+	case KindBinary:
+		return "Binary"
+	case KindText:
+		return "Text"
+	case KindImage:
+		return "Image"
+	case KindAudio:
+		return "Audio"
+	default:
+		return fmt.Sprintf("Unknown%02X", int(kind))
+	}
+}
+
+// FileExtension returns the extension, including the dot, of files that Export() creates for this kind.
+func (kind Kind) FileExtension() string {
+	switch kind {
+	case KindText:
+		return ".txt"
+	case KindImage:
+		return ".png"
+	case KindAudio:
+		return ".wav"
+	default:
+		return ".bin"
+	}
+}
+
+// Preview is the common representation of resource content.
+// Only the member matching the kind is set.
+type Preview struct {
+	Kind Kind
+
+	Text  string
+	Image *image.Paletted
+	Audio audio.L8
+	Data  []byte
+}
+
+// Export writes the preview in a common file format, as identified by Kind.FileExtension().
+func (preview Preview) Export(writer io.Writer) error {
+	switch preview.Kind {
+	case KindText:
+		_, err := io.WriteString(writer, preview.Text)
+		return err
+	case KindImage:
+		return png.Encode(writer, preview.Image)
+	case KindAudio:
+		return wav.Save(writer, preview.Audio.SampleRate, preview.Audio.Samples)
+	default:
+		_, err := writer.Write(preview.Data)
+		return err
+	}
+}
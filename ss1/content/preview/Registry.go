@@ -0,0 +1,89 @@
+package preview
+
+import (
+	"io/ioutil"
+
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// Registry selects the codec for resources.
+// Codecs registered for a range of identifier take precedence over those registered for a content type.
+// Resources without any registered codec are handled by BinaryCodec.
+type Registry struct {
+	byContentType map[resource.ContentType]Codec
+	byRange       []rangeCodec
+}
+
+type rangeCodec struct {
+	start resource.ID
+	end   resource.ID
+	codec Codec
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{byContentType: make(map[resource.ContentType]Codec)}
+}
+
+// DefaultRegistry returns a registry with the codecs for all known content types.
+// Texts are handled with given codepage. Bitmaps without a palette are previewed with given palette, which is optional.
+func DefaultRegistry(cp text.Codepage, palette *bitmap.Palette) *Registry {
+	registry := NewRegistry()
+	registry.Register(resource.Text, TextCodec{Codepage: cp})
+	registry.Register(resource.Bitmap, BitmapCodec{Palette: palette})
+	registry.Register(resource.Palette, PaletteCodec{})
+	registry.Register(resource.Sound, SoundCodec{})
+	return registry
+}
+
+// Register sets the codec for all resources of given content type.
+func (registry *Registry) Register(contentType resource.ContentType, codec Codec) {
+	registry.byContentType[contentType] = codec
+}
+
+// RegisterRange sets the codec for the resources from start (inclusive) to end (exclusive), regardless
+// of their content type. This is the same range notation as used by ids.ResourceInfo.
+// Ranges registered later take precedence over earlier ones.
+func (registry *Registry) RegisterRange(start, end resource.ID, codec Codec) {
+	registry.byRange = append(registry.byRange, rangeCodec{start: start, end: end, codec: codec})
+}
+
+// CodecFor returns the codec for the resource with given identifier and content type.
+func (registry *Registry) CodecFor(id resource.ID, contentType resource.ContentType) Codec {
+	for index := len(registry.byRange) - 1; index >= 0; index-- {
+		entry := registry.byRange[index]
+		if (id.Value() >= entry.start.Value()) && (id.Value() < entry.end.Value()) {
+			return entry.codec
+		}
+	}
+	if codec, registered := registry.byContentType[contentType]; registered {
+		return codec
+	}
+	return BinaryCodec{}
+}
+
+// Decode returns the preview of the identified block of given resource.
+// Should the block not be decodable by the codec of the resource, a binary preview is returned together with the error.
+func (registry *Registry) Decode(id resource.ID, view resource.View, index int) (Preview, error) {
+	reader, err := view.Block(index)
+	if err != nil {
+		return Preview{}, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return Preview{}, err
+	}
+	return registry.DecodeData(id, view.ContentType(), data)
+}
+
+// DecodeData returns the preview of given block data of a resource.
+// Should the data not be decodable by the codec of the resource, a binary preview is returned together with the error.
+func (registry *Registry) DecodeData(id resource.ID, contentType resource.ContentType, data []byte) (Preview, error) {
+	preview, err := registry.CodecFor(id, contentType).Decode(data)
+	if err != nil {
+		preview, _ = BinaryCodec{}.Decode(data)
+	}
+	return preview, err
+}
@@ -0,0 +1,62 @@
+package preview_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/preview"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/resource"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryReturnsBinaryCodecForUnknownContent(t *testing.T) {
+	registry := preview.NewRegistry()
+	assert.Equal(t, preview.BinaryCodec{}, registry.CodecFor(0x0100, resource.Geometry))
+}
+
+func TestRegistryReturnsCodecOfContentType(t *testing.T) {
+	registry := preview.DefaultRegistry(text.DefaultCodepage(), nil)
+	assert.Equal(t, preview.SoundCodec{}, registry.CodecFor(0x0100, resource.Sound))
+}
+
+func TestRegistryPrefersCodecOfRange(t *testing.T) {
+	registry := preview.DefaultRegistry(text.DefaultCodepage(), nil)
+	registry.RegisterRange(0x0100, 0x0110, preview.BinaryCodec{})
+
+	assert.Equal(t, preview.BinaryCodec{}, registry.CodecFor(0x0100, resource.Sound), "start should be included")
+	assert.Equal(t, preview.SoundCodec{}, registry.CodecFor(0x0110, resource.Sound), "end should be excluded")
+}
+
+func TestRegistryDecodesBlocksOfResources(t *testing.T) {
+	registry := preview.DefaultRegistry(text.DefaultCodepage(), nil)
+	res := resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text},
+		Blocks:     resource.BlocksFrom([][]byte{text.DefaultCodepage().Encode("first"), text.DefaultCodepage().Encode("second")}),
+	}
+
+	result, err := registry.Decode(0x0869, res, 1)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, preview.Preview{Kind: preview.KindText, Text: "second"}, result)
+}
+
+func TestRegistryDecodesInvalidDataAsBinary(t *testing.T) {
+	registry := preview.DefaultRegistry(text.DefaultCodepage(), nil)
+	res := resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Sound},
+		Blocks:     resource.BlocksFrom([][]byte{{0x01, 0x02}}),
+	}
+
+	result, err := registry.Decode(0x0100, res, 0)
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, preview.Preview{Kind: preview.KindBinary, Data: []byte{0x01, 0x02}}, result)
+}
+
+func TestRegistryDecodesDataWithCodecOfResource(t *testing.T) {
+	registry := preview.DefaultRegistry(text.DefaultCodepage(), nil)
+
+	result, err := registry.DecodeData(0x0869, resource.Text, text.DefaultCodepage().Encode("data"))
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, preview.Preview{Kind: preview.KindText, Text: "data"}, result)
+}
@@ -0,0 +1,6 @@
+// Package preview provides a common representation of resource content.
+//
+// Codecs convert the serialized data of resource blocks into previews, and back. A Registry selects
+// the codec based on the content type of a resource, or its identifier, so that generic tools
+// can handle any resource without knowing the details of each content package.
+package preview
@@ -97,6 +97,11 @@ func (cache *Cache) InvalidateResources(ids []resource.ID) {
 	}
 }
 
+// InvalidateAll lets the cache remove all texts, for example after the codepage changed.
+func (cache *Cache) InvalidateAll() {
+	cache.texts = make(map[resource.Key]string)
+}
+
 // Text retrieves and caches the text of given key.
 func (cache *Cache) Text(key resource.Key) (string, error) {
 	cacheKey := cache.keyResolver(key)
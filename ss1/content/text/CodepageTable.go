@@ -0,0 +1,86 @@
+package text
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CodepageTableError is reported for entries of a codepage table that can not be used.
+type CodepageTableError struct {
+	Line  int
+	Entry string
+}
+
+// Error returns the textual representation.
+func (err CodepageTableError) Error() string {
+	return fmt.Sprintf("invalid codepage table entry in line %d: '%s'", err.Line, err.Entry)
+}
+
+// LoadCodepage reads a codepage from a table.
+//
+// The table has one entry per line: the byte value and the Unicode code point, both hexadecimal
+// with a "0x" prefix, separated by white space. Text after a '#' is a comment. This is the format of
+// the mapping tables of the Unicode consortium. Byte values that are not listed keep the mapping of
+// the DefaultCodepage. The byte value 0x00 terminates strings and can not be mapped.
+// Should several listed byte values have the same code point, characters are encoded with the lowest of them.
+func LoadCodepage(reader io.Reader) (Codepage, error) {
+	tableToRune := make([]rune, len(cp437ToRune))
+	copy(tableToRune, cp437ToRune[:])
+	var listed [256]bool
+
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		entry := scanner.Text()
+		if commentStart := strings.IndexByte(entry, '#'); commentStart >= 0 {
+			entry = entry[:commentStart]
+		}
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, CodepageTableError{Line: line, Entry: scanner.Text()}
+		}
+		value, valueErr := strconv.ParseUint(fields[0], 0, 8)
+		char, charErr := strconv.ParseUint(fields[1], 0, 32)
+		if (valueErr != nil) || (charErr != nil) || (value == 0x00) || (char == 0x00) {
+			return nil, CodepageTableError{Line: line, Entry: scanner.Text()}
+		}
+		tableToRune[value] = rune(char)
+		listed[value] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Listed entries take precedence for encoding, should a character also be in an unlisted entry.
+	tableToByte := make(map[rune]byte)
+	for value, char := range tableToRune {
+		if !listed[value] {
+			tableToByte[char] = byte(value)
+		}
+	}
+	for value := len(tableToRune) - 1; value >= 0; value-- {
+		if listed[value] {
+			tableToByte[tableToRune[value]] = byte(value)
+		}
+	}
+	return &tabledCodepage{tableToRune: tableToRune, tableToByte: tableToByte}, nil
+}
+
+// SaveCodepage writes the table of given codepage, in the format that LoadCodepage() reads.
+func SaveCodepage(writer io.Writer, cp Codepage) error {
+	_, err := fmt.Fprintln(writer, "# Codepage table: byte value, Unicode code point")
+	for value := 1; (err == nil) && (value < 0x100); value++ {
+		chars := []rune(cp.Decode([]byte{byte(value)}))
+		if len(chars) == 1 {
+			_, err = fmt.Fprintf(writer, "0x%02X\t0x%04X\n", value, chars[0])
+		}
+	}
+	return err
+}
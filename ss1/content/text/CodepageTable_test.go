@@ -0,0 +1,78 @@
+package text_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/text"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCodepageMapsListedEntries(t *testing.T) {
+	table := "# Polish\n0x86\t0x0105\t# LATIN SMALL LETTER A WITH OGONEK\n\n0x8D 0x0107\n"
+
+	cp, err := text.LoadCodepage(strings.NewReader(table))
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, []byte{0x86, 0x8D, 0x41, 0x00}, cp.Encode("ąćA"), "encoding mismatch")
+	assert.Equal(t, "ąćA", cp.Decode([]byte{0x86, 0x8D, 0x41, 0x00}), "decoding mismatch")
+}
+
+func TestLoadCodepageKeepsDefaultForUnlistedEntries(t *testing.T) {
+	cp, err := text.LoadCodepage(strings.NewReader("0x86 0x0105\n"))
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, "Éß", cp.Decode([]byte{144, 225, 0x00}))
+}
+
+func TestLoadCodepagePrefersListedEntriesForEncoding(t *testing.T) {
+	cp, err := text.LoadCodepage(strings.NewReader("0xFE 0x0041\n"))
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, []byte{0xFE, 0x00}, cp.Encode("A"))
+}
+
+func TestLoadCodepageEncodesDuplicatesWithLowestListedEntry(t *testing.T) {
+	for run := 0; run < 20; run++ {
+		cp, err := text.LoadCodepage(strings.NewReader("0xFE 0x0105\n0x86 0x0105\n0xA0 0x0105\n"))
+		require.Nil(t, err, "no error expected")
+
+		assert.Equal(t, []byte{0x86, 0x00}, cp.Encode("ą"))
+	}
+}
+
+func TestLoadCodepageReportsInvalidEntries(t *testing.T) {
+	tt := []string{
+		"0x86",
+		"0x100 0x0041",
+		"0x00 0x0041",
+		"0x86 letter",
+	}
+	for _, tc := range tt {
+		td := tc
+		t.Run(td, func(t *testing.T) {
+			_, err := text.LoadCodepage(strings.NewReader("# header\n" + td + "\n"))
+			assert.Equal(t, text.CodepageTableError{Line: 2, Entry: td}, err)
+		})
+	}
+}
+
+func TestSaveCodepageCanBeLoadedAgain(t *testing.T) {
+	original, err := text.LoadCodepage(strings.NewReader("0x86 0x0105\n"))
+	require.Nil(t, err, "no error expected loading")
+	buffer := bytes.NewBuffer(nil)
+
+	err = text.SaveCodepage(buffer, original)
+	require.Nil(t, err, "no error expected saving")
+	restored, err := text.LoadCodepage(buffer)
+	require.Nil(t, err, "no error expected loading again")
+
+	allValues := make([]byte, 0xFF)
+	for index := range allValues {
+		allValues[index] = byte(index + 1)
+	}
+	assert.Equal(t, original.Decode(allValues), restored.Decode(allValues))
+}
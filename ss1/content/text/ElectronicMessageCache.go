@@ -34,6 +34,11 @@ func (cache *ElectronicMessageCache) InvalidateResources(ids []resource.ID) {
 	}
 }
 
+// InvalidateAll lets the cache remove all messages, for example after the codepage changed.
+func (cache *ElectronicMessageCache) InvalidateAll() {
+	cache.messages = make(map[resource.Key]ElectronicMessage)
+}
+
 // Message retrieves and caches the message of given key.
 func (cache *ElectronicMessageCache) Message(key resource.Key) (ElectronicMessage, error) {
 	cacheKey := resource.KeyOf(key.ID.Plus(key.Index), key.Lang, 0)
@@ -0,0 +1,27 @@
+package text
+
+// SwitchableCodepage is a Codepage that forwards to another one, which can be switched at any time.
+// It allows to share one instance among all users, while the actual codepage depends on the project.
+type SwitchableCodepage struct {
+	current Codepage
+}
+
+// NewSwitchableCodepage returns a new instance that initially forwards to the given codepage.
+func NewSwitchableCodepage(initial Codepage) *SwitchableCodepage {
+	return &SwitchableCodepage{current: initial}
+}
+
+// Switch lets the codepage forward to the given one from now on.
+func (cp *SwitchableCodepage) Switch(other Codepage) {
+	cp.current = other
+}
+
+// Encode converts the provided string with the current codepage.
+func (cp *SwitchableCodepage) Encode(value string) []byte {
+	return cp.current.Encode(value)
+}
+
+// Decode converts the provided byte slice with the current codepage.
+func (cp *SwitchableCodepage) Decode(data []byte) string {
+	return cp.current.Decode(data)
+}
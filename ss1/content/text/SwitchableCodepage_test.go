@@ -0,0 +1,23 @@
+package text_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/text"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwitchableCodepageForwardsToCurrentCodepage(t *testing.T) {
+	other, err := text.LoadCodepage(strings.NewReader("0x86 0x0105\n"))
+	require.Nil(t, err, "no error expected")
+	cp := text.NewSwitchableCodepage(text.DefaultCodepage())
+	assert.Equal(t, "å", cp.Decode([]byte{0x86, 0x00}), "initial codepage expected")
+
+	cp.Switch(other)
+
+	assert.Equal(t, "ą", cp.Decode([]byte{0x86, 0x00}), "switched codepage expected for decoding")
+	assert.Equal(t, []byte{0x86, 0x00}, cp.Encode("ą"), "switched codepage expected for encoding")
+}
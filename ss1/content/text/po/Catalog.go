@@ -0,0 +1,69 @@
+package po
+
+import "strings"
+
+// FlagFuzzy marks entries whose translation needs to be reviewed.
+const FlagFuzzy = "fuzzy"
+
+// Entry is one message of a catalog.
+type Entry struct {
+	// TranslatorComments are free comments of translators.
+	TranslatorComments []string
+	// ExtractedComments describe the message for translators.
+	ExtractedComments []string
+	// References list where the message is used.
+	References []string
+	// Flags describe the state of the message, such as FlagFuzzy.
+	Flags []string
+
+	// Context disambiguates messages with the same ID.
+	Context string
+	// ID is the original message.
+	ID string
+	// Translation is the translated message. Empty if not translated.
+	Translation string
+}
+
+// HasFlag returns true if the entry has the given flag.
+func (entry Entry) HasFlag(flag string) bool {
+	for _, existing := range entry.Flags {
+		if existing == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFuzzy returns true if the translation needs to be reviewed.
+func (entry Entry) IsFuzzy() bool {
+	return entry.HasFlag(FlagFuzzy)
+}
+
+// IsTranslated returns true if the entry has a translation.
+func (entry Entry) IsTranslated() bool {
+	return len(entry.Translation) > 0
+}
+
+// HeaderField is one property of the meta information of a catalog.
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+// Catalog is the content of a PO or POT file.
+type Catalog struct {
+	// Header contains the meta information, such as the language of the translations.
+	Header []HeaderField
+	// Entries are the messages, in order of the file.
+	Entries []Entry
+}
+
+// HeaderValue returns the value of the named header field. Empty if not set.
+func (catalog Catalog) HeaderValue(name string) string {
+	for _, field := range catalog.Header {
+		if strings.EqualFold(field.Name, name) {
+			return field.Value
+		}
+	}
+	return ""
+}
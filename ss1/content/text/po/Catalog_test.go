@@ -0,0 +1,103 @@
+package po_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/text/po"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCatalog(t *testing.T) {
+	catalog := po.Catalog{
+		Header: []po.HeaderField{{Name: "Language", Value: "de"}},
+		Entries: []po.Entry{
+			{
+				ExtractedComments: []string{"Words"},
+				Flags:             []string{po.FlagFuzzy},
+				Context:           "0869:1",
+				ID:                "say \"hi\"",
+				Translation:       "sag \"hallo\"",
+			},
+			{Context: "0A98", ID: "line 1\nline 2"},
+		},
+	}
+	buffer := bytes.NewBuffer(nil)
+
+	err := po.Write(buffer, catalog)
+
+	require.Nil(t, err, "no error expected")
+	expected := `msgid ""
+msgstr "Language: de\n"
+
+#. Words
+#, fuzzy
+msgctxt "0869:1"
+msgid "say \"hi\""
+msgstr "sag \"hallo\""
+
+msgctxt "0A98"
+msgid ""
+"line 1\n"
+"line 2"
+msgstr ""
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestReadWrittenCatalog(t *testing.T) {
+	catalog := po.Catalog{
+		Header: []po.HeaderField{{Name: "Language", Value: "fr"}, {Name: "Content-Type", Value: "text/plain; charset=UTF-8"}},
+		Entries: []po.Entry{
+			{
+				TranslatorComments: []string{"checked"},
+				ExtractedComments:  []string{"Papers"},
+				References:         []string{"cybstrng.res"},
+				Context:            "0A98",
+				ID:                 "first\nsecond\n",
+				Translation:        "premier\tdeuxième\n",
+			},
+			{Context: "0869:2", ID: "word", Flags: []string{po.FlagFuzzy, "c-format"}},
+		},
+	}
+	buffer := bytes.NewBuffer(nil)
+	err := po.Write(buffer, catalog)
+	require.Nil(t, err, "no error expected writing")
+
+	restored, err := po.Read(buffer)
+
+	require.Nil(t, err, "no error expected reading")
+	assert.Equal(t, catalog, restored)
+}
+
+func TestReadSkipsObsoleteEntries(t *testing.T) {
+	input := `msgid "a"
+msgstr "b"
+
+#~ msgid "old"
+#~ msgstr "alt"
+`
+	catalog, err := po.Read(strings.NewReader(input))
+
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, []po.Entry{{ID: "a", Translation: "b"}}, catalog.Entries)
+}
+
+func TestReadReportsLineOfFormatErrors(t *testing.T) {
+	input := "msgid \"a\"\nmsgid_plural \"as\"\n"
+
+	_, err := po.Read(strings.NewReader(input))
+
+	formatErr, isFormatErr := err.(po.FormatError)
+	require.True(t, isFormatErr, "format error expected")
+	assert.Equal(t, 2, formatErr.Line)
+}
+
+func TestEntryStates(t *testing.T) {
+	assert.True(t, po.Entry{Flags: []string{"c-format", po.FlagFuzzy}}.IsFuzzy(), "fuzzy expected")
+	assert.False(t, po.Entry{ID: "a"}.IsTranslated(), "untranslated expected")
+	assert.True(t, po.Entry{ID: "a", Translation: "b"}.IsTranslated(), "translated expected")
+}
@@ -0,0 +1,180 @@
+package po
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	keywordContext     = "msgctxt"
+	keywordID          = "msgid"
+	keywordTranslation = "msgstr"
+)
+
+// FormatError is reported for lines of a PO file that can not be parsed.
+type FormatError struct {
+	Line   int
+	Reason string
+}
+
+// Error returns the textual representation.
+func (err FormatError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Reason)
+}
+
+// Read parses a catalog in PO format. Obsolete entries are skipped.
+func Read(reader io.Reader) (Catalog, error) {
+	var catalog Catalog
+	var entry Entry
+	var target *string
+	hasEntry := false
+	hasTranslation := false
+	finishEntry := func() {
+		if !hasEntry {
+			return
+		}
+		if (len(entry.ID) == 0) && (len(entry.Context) == 0) && (len(catalog.Entries) == 0) && (catalog.Header == nil) {
+			catalog.Header = headerFrom(entry.Translation)
+		} else {
+			catalog.Entries = append(catalog.Entries, entry)
+		}
+		entry = Entry{}
+		target = nil
+		hasEntry = false
+		hasTranslation = false
+	}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		formatError := func(reason string) error {
+			return FormatError{Line: lineNumber, Reason: reason}
+		}
+		switch {
+		case len(line) == 0:
+			finishEntry()
+		case strings.HasPrefix(line, "#"):
+			if hasTranslation {
+				finishEntry()
+			}
+			if addComment(&entry, line) {
+				hasEntry = true
+			}
+		case strings.HasPrefix(line, "\""):
+			if target == nil {
+				return Catalog{}, formatError("string without keyword")
+			}
+			value, err := unquote(line)
+			if err != nil {
+				return Catalog{}, formatError(err.Error())
+			}
+			*target += value
+		default:
+			keyword, quoted := line, ""
+			if separator := strings.IndexAny(line, " \t"); separator >= 0 {
+				keyword, quoted = line[:separator], strings.TrimSpace(line[separator:])
+			}
+			if hasTranslation {
+				finishEntry()
+			}
+			switch keyword {
+			case keywordContext:
+				target = &entry.Context
+			case keywordID:
+				target = &entry.ID
+			case keywordTranslation:
+				target = &entry.Translation
+				hasTranslation = true
+			default:
+				return Catalog{}, formatError("unsupported keyword " + keyword)
+			}
+			value, err := unquote(quoted)
+			if err != nil {
+				return Catalog{}, formatError(err.Error())
+			}
+			*target = value
+			hasEntry = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Catalog{}, err
+	}
+	finishEntry()
+	return catalog, nil
+}
+
+// addComment adds the comment line to the entry. It returns false for comments that are not kept.
+func addComment(entry *Entry, line string) bool {
+	comment := func(prefixLength int) string {
+		return strings.TrimSpace(line[prefixLength:])
+	}
+	switch {
+	case strings.HasPrefix(line, "#."):
+		entry.ExtractedComments = append(entry.ExtractedComments, comment(2))
+	case strings.HasPrefix(line, "#:"):
+		entry.References = append(entry.References, comment(2))
+	case strings.HasPrefix(line, "#,"):
+		for _, flag := range strings.Split(comment(2), ",") {
+			if flag = strings.TrimSpace(flag); len(flag) > 0 {
+				entry.Flags = append(entry.Flags, flag)
+			}
+		}
+	case strings.HasPrefix(line, "#~"), strings.HasPrefix(line, "#|"):
+		// Obsolete entries and previous messages are not kept.
+		return false
+	default:
+		entry.TranslatorComments = append(entry.TranslatorComments, comment(1))
+	}
+	return true
+}
+
+func headerFrom(value string) []HeaderField {
+	fields := []HeaderField{}
+	for _, line := range strings.Split(value, "\n") {
+		separator := strings.Index(line, ":")
+		if separator < 0 {
+			continue
+		}
+		fields = append(fields, HeaderField{
+			Name:  strings.TrimSpace(line[:separator]),
+			Value: strings.TrimSpace(line[separator+1:]),
+		})
+	}
+	return fields
+}
+
+var unescapedCharacters = map[byte]byte{
+	'n': '\n', 't': '\t', 'r': '\r', '"': '"', '\\': '\\', 'a': '\a', 'b': '\b', 'f': '\f', 'v': '\v',
+}
+
+func unquote(quoted string) (string, error) {
+	if (len(quoted) < 2) || (quoted[0] != '"') || (quoted[len(quoted)-1] != '"') {
+		return "", fmt.Errorf("string is not quoted: %s", quoted)
+	}
+	inner := quoted[1 : len(quoted)-1]
+	var result strings.Builder
+	for index := 0; index < len(inner); index++ {
+		character := inner[index]
+		if character == '"' {
+			return "", fmt.Errorf("unescaped quote in %s", quoted)
+		}
+		if character != '\\' {
+			result.WriteByte(character)
+			continue
+		}
+		index++
+		if index >= len(inner) {
+			return "", fmt.Errorf("incomplete escape sequence in %s", quoted)
+		}
+		unescaped, known := unescapedCharacters[inner[index]]
+		if !known {
+			return "", fmt.Errorf("unsupported escape sequence \\%c", inner[index])
+		}
+		result.WriteByte(unescaped)
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,64 @@
+package po
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Write serializes the catalog in PO format.
+func Write(writer io.Writer, catalog Catalog) error {
+	buffered := bufio.NewWriter(writer)
+	header := make([]string, 0, len(catalog.Header))
+	for _, field := range catalog.Header {
+		header = append(header, field.Name+": "+field.Value+"\n")
+	}
+	writeEntry(buffered, Entry{Translation: strings.Join(header, "")})
+	for _, entry := range catalog.Entries {
+		_, _ = buffered.WriteString("\n")
+		writeEntry(buffered, entry)
+	}
+	return buffered.Flush()
+}
+
+func writeEntry(writer *bufio.Writer, entry Entry) {
+	writeComments := func(prefix string, comments []string) {
+		for _, comment := range comments {
+			_, _ = writer.WriteString(strings.TrimRight(prefix+comment, " ") + "\n")
+		}
+	}
+	writeComments("# ", entry.TranslatorComments)
+	writeComments("#. ", entry.ExtractedComments)
+	writeComments("#: ", entry.References)
+	if len(entry.Flags) > 0 {
+		writeComments("#, ", []string{strings.Join(entry.Flags, ", ")})
+	}
+	if len(entry.Context) > 0 {
+		writeString(writer, keywordContext, entry.Context)
+	}
+	writeString(writer, keywordID, entry.ID)
+	writeString(writer, keywordTranslation, entry.Translation)
+}
+
+// writeString writes the keyword with the quoted value. Values with line breaks are split into one
+// quoted string per line, starting on the line after the keyword.
+func writeString(writer *bufio.Writer, keyword string, value string) {
+	lines := strings.SplitAfter(value, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		_, _ = writer.WriteString(keyword + " " + quote(value) + "\n")
+		return
+	}
+	_, _ = writer.WriteString(keyword + " \"\"\n")
+	for _, line := range lines {
+		_, _ = writer.WriteString(quote(line) + "\n")
+	}
+}
+
+var escaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n", "\t", "\\t", "\r", "\\r")
+
+func quote(value string) string {
+	return "\"" + escaper.Replace(value) + "\""
+}
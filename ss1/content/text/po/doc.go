@@ -0,0 +1,6 @@
+// Package po reads and writes message catalogs in the PO format of GNU gettext.
+//
+// PO files are the common exchange format for translations. Each entry pairs an original message with its
+// translation. Templates (POT files) have the same format, with all translations empty.
+// Plural forms are not supported, as the texts of the game do not use them.
+package po
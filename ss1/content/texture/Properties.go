@@ -1,6 +1,10 @@
 package texture
 
-import "github.com/inkyblackness/hacked/ss1/serial"
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/serial"
+)
 
 const (
 	// PropertiesSize specifies, in bytes, the length a properties structure has.
@@ -28,8 +32,8 @@ type PropertiesList []Properties
 // Code serializes the list with the provided coder.
 func (list PropertiesList) Code(coder serial.Coder) {
 	version := propertiesFileVersion
-	coder.Code(&version)
+	serial.CodeField(coder, "Version", &version)
 	for i := 0; i < len(list); i++ {
-		coder.Code(&list[i])
+		serial.CodeField(coder, fmt.Sprintf("Texture[%d]", i), &list[i])
 	}
 }
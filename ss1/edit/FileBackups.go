@@ -0,0 +1,226 @@
+package edit
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const (
+	// BackupDirName is the name of the folder that keeps the backups of saved files. It is placed next to them.
+	// Temporary files, written while saving, are kept there as well.
+	BackupDirName = ".hacked-backup"
+	// DefaultBackupCount is the number of backups that are kept for each saved file, unless configured otherwise.
+	DefaultBackupCount = 3
+)
+
+// FileBackup describes a previous state of a saved file.
+type FileBackup struct {
+	// Filename is the path of the file the backup is for.
+	Filename string
+	// Path is the path of the backup itself.
+	Path string
+	// Generation counts the saves since the backup was made. The most recent backup has generation 1.
+	Generation int
+	// ModTime is the time the backed up state was saved.
+	ModTime time.Time
+}
+
+// BackupsOf returns the backups of given file, with the most recent first.
+func BackupsOf(filename string) []FileBackup {
+	var backups []FileBackup
+	for generation := 1; ; generation++ {
+		path := backupPath(filename, generation)
+		info, err := os.Stat(path)
+		if (err != nil) || info.IsDir() {
+			return backups
+		}
+		backups = append(backups, FileBackup{
+			Filename:   filename,
+			Path:       path,
+			Generation: generation,
+			ModTime:    info.ModTime(),
+		})
+	}
+}
+
+func backupPath(filename string, generation int) string {
+	return filepath.Join(filepath.Dir(filename), BackupDirName, fmt.Sprintf("%s.%d", filepath.Base(filename), generation))
+}
+
+// writeFileSafely replaces the file with given name with the content provided by the write function.
+// The content is written to a temporary file first, which then replaces the file in one step.
+// This way, the file is either in its previous, or in its new state, even if saving is interrupted.
+//
+// The new file keeps the permissions of the previous one. New files are created readable for everyone.
+//
+// Before the file is replaced, its previous state is kept as a backup. At most backupCount backups are kept,
+// the oldest are removed. A path that is currently a directory, such as the unpacked form of a resource file,
+// is kept as a single backup of its own, see replaceWith().
+func writeFileSafely(filename string, backupCount int, write func(io.WriteSeeker) error) error {
+	backupDir := filepath.Join(filepath.Dir(filename), BackupDirName)
+	err := os.MkdirAll(backupDir, 0750)
+	if err != nil {
+		return err
+	}
+	tempFile, err := ioutil.TempFile(backupDir, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempName := tempFile.Name()
+	err = write(tempFile)
+	if err == nil {
+		err = tempFile.Chmod(fileModeFor(filename))
+	}
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = rotateBackups(filename, backupCount)
+	}
+	if err == nil {
+		err = replaceWith(filename, tempName)
+	}
+	if err != nil {
+		_ = os.Remove(tempName)
+		return err
+	}
+	return syncDir(filepath.Dir(filename))
+}
+
+// fileModeFor returns the permissions of the existing file, or those for a new file.
+func fileModeFor(filename string) os.FileMode {
+	if info, err := os.Stat(filename); (err == nil) && info.Mode().IsRegular() {
+		return info.Mode().Perm()
+	}
+	return 0644
+}
+
+// replaceWith renames the temporary file to the given filename.
+// A directory at the filename can not be replaced in one step. It is moved into the backup folder first,
+// where it is kept until the next time a directory is replaced this way. Should the rename fail,
+// the directory is moved back.
+func replaceWith(filename string, tempName string) error {
+	info, err := os.Stat(filename)
+	if (err != nil) || !info.IsDir() {
+		return os.Rename(tempName, filename)
+	}
+	aside := directoryBackupPath(filename)
+	err = os.RemoveAll(aside)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(filename, aside)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tempName, filename)
+	if err != nil {
+		_ = os.Rename(aside, filename)
+	}
+	return err
+}
+
+func directoryBackupPath(filename string) string {
+	return filepath.Join(filepath.Dir(filename), BackupDirName, filepath.Base(filename)+".dir")
+}
+
+// syncDir flushes the entries of the directory, so that a rename within it is persisted.
+// Directories can not be synchronized on Windows, where renames are persisted by the file system itself.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// rotateBackups moves all existing backups of the file one generation further,
+// and stores the current state of the file as the most recent backup.
+func rotateBackups(filename string, backupCount int) error {
+	for generation := backupCount; ; generation++ {
+		err := os.Remove(backupPath(filename, generation))
+		if os.IsNotExist(err) && (generation > backupCount) {
+			break
+		} else if (err != nil) && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if backupCount <= 0 {
+		return nil
+	}
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) || ((err == nil) && info.IsDir()) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for generation := backupCount - 1; generation >= 1; generation-- {
+		err = os.Rename(backupPath(filename, generation), backupPath(filename, generation+1))
+		if (err != nil) && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return linkOrCopy(filename, backupPath(filename, 1), info.ModTime())
+}
+
+// linkOrCopy creates a hard link of the source file, or a copy if linking is not possible.
+// As the source is replaced by renaming a new file over it, the link keeps the previous content.
+func linkOrCopy(source string, target string, modTime time.Time) error {
+	if os.Link(source, target) == nil {
+		return nil
+	}
+	err := copyFile(source, target)
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(target, modTime, modTime)
+}
+
+func copyFile(source string, target string) error {
+	reader, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+	writer, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, reader)
+	closeErr := writer.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// restoreBackup replaces the file of the backup with the content of the backup.
+// The replaced state becomes a backup itself, which allows to revert the restore.
+func restoreBackup(backup FileBackup, backupCount int) error {
+	return writeFileSafely(backup.Filename, backupCount, func(writer io.WriteSeeker) error {
+		reader, err := os.Open(backup.Path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = reader.Close() }()
+		_, err = io.Copy(writer, reader)
+		return err
+	})
+}
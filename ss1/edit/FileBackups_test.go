@@ -0,0 +1,189 @@
+package edit_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/world"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectServiceSaveModKeepsBackups(t *testing.T) {
+	modDir := t.TempDir()
+	filename := filepath.Join(modDir, "cybstrng.res")
+	givenTextListFile(t, filename)
+	service := givenProjectServiceWithMod(t, modDir)
+	service.SetBackupCount(2)
+
+	versions := [][]byte{readFile(t, filename)}
+	for _, value := range []byte{0x01, 0x02, 0x03} {
+		givenModifiedText(t, service, value)
+		err := service.SaveMod()
+		require.Nil(t, err, "no error expected saving")
+		versions = append(versions, readFile(t, filename))
+	}
+
+	backups := edit.BackupsOf(filename)
+	require.Equal(t, 2, len(backups), "backups should be limited")
+	assert.Equal(t, versions[2], readFile(t, backups[0].Path), "most recent backup should be first")
+	assert.Equal(t, versions[1], readFile(t, backups[1].Path), "older backup should be second")
+	assert.Equal(t, backups, service.ModBackups(), "service should list backups of the mod")
+	temporary, _ := filepath.Glob(filepath.Join(modDir, edit.BackupDirName, "*.tmp"))
+	assert.Empty(t, temporary, "no temporary files expected")
+}
+
+func TestProjectServiceSaveModWithoutBackups(t *testing.T) {
+	modDir := t.TempDir()
+	filename := filepath.Join(modDir, "cybstrng.res")
+	givenTextListFile(t, filename)
+	service := givenProjectServiceWithMod(t, modDir)
+	service.SetBackupCount(0)
+
+	givenModifiedText(t, service, 0x01)
+	err := service.SaveMod()
+	require.Nil(t, err, "no error expected saving")
+
+	assert.Empty(t, edit.BackupsOf(filename), "no backups expected")
+}
+
+func TestProjectServiceRestoreModBackup(t *testing.T) {
+	modDir := t.TempDir()
+	filename := filepath.Join(modDir, "cybstrng.res")
+	givenTextListFile(t, filename)
+	original := readFile(t, filename)
+	service := givenProjectServiceWithMod(t, modDir)
+	givenModifiedText(t, service, 0x01)
+	err := service.SaveMod()
+	require.Nil(t, err, "no error expected saving")
+	saved := readFile(t, filename)
+
+	err = service.RestoreModBackup(edit.BackupsOf(filename)[0])
+	require.Nil(t, err, "no error expected restoring")
+
+	assert.Equal(t, original, readFile(t, filename), "original content should be restored")
+	assert.Equal(t, saved, readFile(t, edit.BackupsOf(filename)[0].Path), "replaced content should be backed up")
+	assert.Equal(t, []byte{0x00}, textBlock(t, service), "mod should be reloaded")
+}
+
+func TestProjectServiceRestoreModBackupRefusesWithPendingChanges(t *testing.T) {
+	modDir := t.TempDir()
+	filename := filepath.Join(modDir, "cybstrng.res")
+	givenTextListFile(t, filename)
+	service := givenProjectServiceWithMod(t, modDir)
+	givenModifiedText(t, service, 0x01)
+	err := service.SaveMod()
+	require.Nil(t, err, "no error expected saving")
+	givenModifiedText(t, service, 0x02)
+
+	err = service.RestoreModBackup(edit.BackupsOf(filename)[0])
+
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, []byte{0x02, 0x00}, textBlock(t, service), "changes should be kept")
+}
+
+func TestProjectServiceSaveModKeepsFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not supported on Windows")
+	}
+	modDir := t.TempDir()
+	filename := filepath.Join(modDir, "cybstrng.res")
+	givenTextListFile(t, filename)
+	err := os.Chmod(filename, 0640)
+	require.Nil(t, err, "no error expected changing permissions")
+	service := givenProjectServiceWithMod(t, modDir)
+
+	givenModifiedText(t, service, 0x01)
+	err = service.SaveMod()
+	require.Nil(t, err, "no error expected saving")
+
+	info, err := os.Stat(filename)
+	require.Nil(t, err, "file expected")
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm(), "permissions should be kept")
+}
+
+func TestProjectServiceSaveModReplacesUnpackedFormWithBackup(t *testing.T) {
+	modDir := t.TempDir()
+	filename := filepath.Join(modDir, "cybstrng.res")
+	givenTextListFile(t, filename)
+	service := givenProjectServiceWithMod(t, modDir)
+	service.SetModStorage(edit.ModStorageUnpacked)
+	givenModifiedText(t, service, 0x01)
+	err := service.SaveMod()
+	require.Nil(t, err, "no error expected saving unpacked")
+	require.True(t, isDir(t, filename), "file should be unpacked")
+	assert.Equal(t, 1, len(edit.BackupsOf(filename)), "packed form should be backed up")
+
+	service.SetModStorage(edit.ModStorageResourceFiles)
+	givenModifiedText(t, service, 0x02)
+	err = service.SaveMod()
+	require.Nil(t, err, "no error expected saving packed")
+
+	assert.False(t, isDir(t, filename), "file should be packed again")
+	assert.True(t, isDir(t, filepath.Join(modDir, edit.BackupDirName, "cybstrng.res.dir")), "unpacked form should be kept")
+}
+
+func givenProjectServiceWithMod(t *testing.T, modDir string) *edit.ProjectService {
+	t.Helper()
+	service := givenProjectService()
+	err := service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading mod")
+	return service
+}
+
+func givenModifiedText(t *testing.T, service *edit.ProjectService, value byte) {
+	t.Helper()
+	err := service.ModifyModWith(func(modder world.Modder) error {
+		modder.SetResourceBlock(resource.LangDefault, resource.ID(0x0869), 0, []byte{value, 0x00})
+		return nil
+	})
+	require.Nil(t, err, "no error expected modifying")
+}
+
+func textBlock(t *testing.T, service *edit.ProjectService) []byte {
+	t.Helper()
+	view := service.Mod().ModifiedResource(resource.LangDefault, resource.ID(0x0869))
+	require.NotNil(t, view, "resource expected")
+	reader, err := view.Block(0)
+	require.Nil(t, err, "block expected")
+	data, err := ioutil.ReadAll(reader)
+	require.Nil(t, err, "no error expected reading block")
+	return data
+}
+
+func isDir(t *testing.T, path string) bool {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.Nil(t, err, "path expected")
+	return info.IsDir()
+}
+
+func readFile(t *testing.T, filename string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	require.Nil(t, err, "no error expected reading file")
+	return data
+}
+
+// givenTextListFile creates a resource file with a list of texts, which can be modified block by block.
+func givenTextListFile(t *testing.T, filename string) {
+	t.Helper()
+	file, err := os.Create(filename)
+	require.Nil(t, err, "no error expected creating file")
+	defer func() { _ = file.Close() }()
+	var store resource.Store
+	err = store.Put(resource.ID(0x0869), resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text, Compound: true},
+		Blocks:     resource.BlocksFrom([][]byte{{0x00}}),
+	})
+	require.Nil(t, err, "no error expected putting resource")
+	err = lgres.Write(file, &store)
+	require.Nil(t, err, "no error expected writing file")
+}
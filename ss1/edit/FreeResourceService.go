@@ -0,0 +1,128 @@
+package edit
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// FreeResourceService finds places for new resources that are neither used by the world, nor by the mod.
+//
+// A place, or slot, is identified by its index within a group of resources, as described by ids.ResourceInfo.
+// For list resources, the index is that of a block within the resource. For other groups, the index
+// is the offset of the resource identifier from the start of the group.
+type FreeResourceService struct {
+	mod *world.Mod
+}
+
+// NewFreeResourceService returns a new instance for given mod.
+func NewFreeResourceService(mod *world.Mod) FreeResourceService {
+	return FreeResourceService{mod: mod}
+}
+
+// FreeIndices returns the indices of all unused slots within given group of resources.
+// A slot is unused if no language has any data for it.
+//
+// For list resources without a maximum count, the index after the last block is also listed as
+// the place to append a new entry.
+func (service FreeResourceService) FreeIndices(info ids.ResourceInfo) []int {
+	var free []int
+	count := service.slotCount(info)
+	for index := 0; index < count; index++ {
+		if !service.isUsed(info, index) {
+			free = append(free, index)
+		}
+	}
+	return free
+}
+
+// FirstFreeIndex returns the index of the first unused slot within given group of resources.
+// Returns false if all slots are used.
+func (service FreeResourceService) FirstFreeIndex(info ids.ResourceInfo) (int, bool) {
+	count := service.slotCount(info)
+	for index := 0; index < count; index++ {
+		if !service.isUsed(info, index) {
+			return index, true
+		}
+	}
+	return -1, false
+}
+
+// FreeKeys returns the keys of all unused slots of all groups of resources that have given content type,
+// and are stored in given file. A nil file considers all files. The keys are for the any-language.
+func (service FreeResourceService) FreeKeys(contentType resource.ContentType, file ids.Filename) []resource.Key {
+	var keys []resource.Key
+	for _, info := range ids.InfosOf(contentType, file) {
+		for _, index := range service.FreeIndices(info) {
+			keys = append(keys, SlotKey(info, resource.LangAny, index))
+		}
+	}
+	return keys
+}
+
+// SlotKey returns the resource key for the slot of given index within the group of resources.
+func SlotKey(info ids.ResourceInfo, lang resource.Language, index int) resource.Key {
+	if info.List {
+		return resource.KeyOf(info.StartID, lang, index)
+	}
+	return resource.KeyOf(info.StartID.Plus(index), lang, 0)
+}
+
+func (service FreeResourceService) slotCount(info ids.ResourceInfo) int {
+	if !info.List {
+		return int(info.EndID.Value() - info.StartID.Value())
+	}
+	if info.MaxCount > 0 {
+		return info.MaxCount
+	}
+	count := 0
+	for _, lang := range resource.Languages() {
+		view, err := service.mod.LocalizedResources(lang).Select(info.StartID)
+		if (err == nil) && (view.BlockCount() > count) {
+			count = view.BlockCount()
+		}
+	}
+	return count + 1
+}
+
+func (service FreeResourceService) isUsed(info ids.ResourceInfo, index int) bool {
+	key := SlotKey(info, resource.LangAny, index)
+	for _, lang := range resource.Languages() {
+		view, err := service.mod.LocalizedResources(lang).Select(key.ID)
+		if err != nil {
+			continue
+		}
+		if info.List {
+			if hasBlockData(view, key.Index, info.ContentType) {
+				return true
+			}
+			continue
+		}
+		for blockIndex := 0; blockIndex < view.BlockCount(); blockIndex++ {
+			if hasBlockData(view, blockIndex, info.ContentType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasBlockData returns true if the identified block has data.
+// Texts that consist only of their terminating zero are considered to have no data.
+func hasBlockData(view resource.View, index int, contentType resource.ContentType) bool {
+	if index >= view.BlockCount() {
+		return false
+	}
+	reader, err := view.Block(index)
+	if err != nil {
+		return false
+	}
+	data, _ := ioutil.ReadAll(reader)
+	if contentType == resource.Text {
+		return len(bytes.Trim(data, "\x00")) > 0
+	}
+	return len(data) > 0
+}
@@ -0,0 +1,109 @@
+package edit_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	freeTestGroup = ids.ResourceInfo{StartID: 0x7F00, EndID: 0x7F04, ContentType: resource.Bitmap}
+	freeTestList  = ids.ResourceInfo{StartID: 0x7F10, EndID: 0x7F11, ContentType: resource.Text, List: true}
+)
+
+func TestFreeIndicesSkipsSlotsUsedByWorldParentLayersAndMod(t *testing.T) {
+	mod := givenModWithFreeTestData(t)
+
+	free := edit.NewFreeResourceService(mod).FreeIndices(freeTestGroup)
+
+	assert.Equal(t, []int{3}, free)
+}
+
+func TestFreeIndicesOfListsConsiderBlocksOfAllSources(t *testing.T) {
+	mod := givenModWithFreeTestData(t)
+
+	free := edit.NewFreeResourceService(mod).FreeIndices(freeTestList)
+
+	assert.Equal(t, []int{1, 4}, free, "empty text and appending index expected")
+}
+
+func TestFreeIndicesOfListsWithMaximumCountHaveNoAppendingIndex(t *testing.T) {
+	mod := givenModWithFreeTestData(t)
+	info := freeTestList
+	info.MaxCount = 3
+
+	free := edit.NewFreeResourceService(mod).FreeIndices(info)
+
+	assert.Equal(t, []int{1}, free)
+}
+
+func TestFirstFreeIndexReportsExhaustedRange(t *testing.T) {
+	mod := givenModWithFreeTestData(t)
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangAny, freeTestGroup.StartID.Plus(3), 0, []byte{0x01})
+	})
+	service := edit.NewFreeResourceService(mod)
+
+	index, found := service.FirstFreeIndex(freeTestGroup)
+
+	assert.False(t, found, "no free index expected")
+	assert.Equal(t, -1, index)
+	assert.Empty(t, service.FreeIndices(freeTestGroup), "no free indices expected")
+}
+
+func TestFirstFreeIndexReturnsLowestUnusedSlot(t *testing.T) {
+	mod := givenModWithFreeTestData(t)
+
+	index, found := edit.NewFreeResourceService(mod).FirstFreeIndex(freeTestList)
+
+	require.True(t, found, "free index expected")
+	assert.Equal(t, 1, index)
+}
+
+// givenModWithFreeTestData returns a mod in which the test group has its first slot used by the world,
+// the second by a parent layer, and the third by the mod itself. The test list has texts at the blocks 0, 2, and 3,
+// coming from the world, a parent layer, and the mod, while block 1 is an empty text.
+func givenModWithFreeTestData(t *testing.T) *world.Mod {
+	t.Helper()
+	text := func(value string) []byte { return append([]byte(value), 0x00) }
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+
+	var worldStore resource.Store
+	_ = worldStore.Put(freeTestGroup.StartID, resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Bitmap},
+		Blocks:     resource.BlocksFrom([][]byte{{0x01}}),
+	})
+	_ = worldStore.Put(freeTestList.StartID, resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text, Compound: true},
+		Blocks:     resource.BlocksFrom([][]byte{text("zero"), text("")}),
+	})
+	err := mod.World().InsertEntry(0, &world.ManifestEntry{
+		ID:        "world",
+		Resources: resource.LocalizedResourcesList{{ID: "world.res", Language: resource.LangAny, Viewer: worldStore}},
+	})
+	require.Nil(t, err, "no error expected inserting entry")
+
+	layer := &world.LocalizedResources{Language: resource.LangGerman}
+	_ = layer.Store.Put(freeTestGroup.StartID.Plus(1), resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Bitmap},
+		Blocks:     resource.BlocksFrom([][]byte{{0x02}}),
+	})
+	_ = layer.Store.Put(freeTestList.StartID, resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text, Compound: true},
+		Blocks:     resource.BlocksFrom([][]byte{text(""), text(""), text("zwei")}),
+	})
+	mod.SetParentLayers([]*world.ModLayer{{Name: "parent", LocalizedResources: []*world.LocalizedResources{layer}}})
+
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangFrench, freeTestGroup.StartID.Plus(2), 0, []byte{0x03})
+		modder.SetResourceBlocks(resource.LangFrench, freeTestList.StartID,
+			[][]byte{text(""), text(""), text(""), text("trois")})
+	})
+	return mod
+}
@@ -0,0 +1,357 @@
+package edit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/content/movie"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/edit/media"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// TextResources provides access to the resources that contain the texts of the game.
+type TextResources interface {
+	resource.Localizer
+	ModifiedBlocks(lang resource.Language, id resource.ID) [][]byte
+}
+
+// Message field names are used to identify the texts of electronic messages.
+const (
+	messageFieldTitle   = "title"
+	messageFieldSender  = "sender"
+	messageFieldSubject = "subject"
+	messageFieldVerbose = "verbose"
+	messageFieldTerse   = "terse"
+
+	subtitlePart = "subtitle"
+)
+
+var messageFields = []string{
+	messageFieldTitle, messageFieldSender, messageFieldSubject, messageFieldVerbose, messageFieldTerse,
+}
+
+func messageField(message *text.ElectronicMessage, field string) *string {
+	switch field {
+	case messageFieldTitle:
+		return &message.Title
+	case messageFieldSender:
+		return &message.Sender
+	case messageFieldSubject:
+		return &message.Subject
+	case messageFieldVerbose:
+		return &message.VerboseText
+	case messageFieldTerse:
+		return &message.TerseText
+	default:
+		return nil
+	}
+}
+
+var subtitledMovies = []struct {
+	id        resource.ID
+	title     string
+	multilang bool
+}{
+	{id: ids.MovieIntro, title: "Intro", multilang: true},
+	{id: ids.MovieDeath, title: "Death"},
+	{id: ids.MovieEnd, title: "End"},
+}
+
+// textUnit identifies one text of the game, independent of language.
+//
+// The string form of a unit has one of the following forms, with the resource ID in hexadecimal:
+//   - "0869:12" for the block 12 of a list of texts.
+//   - "0A98" for a text that spans all blocks of a resource, such as a paper.
+//   - "0989:verbose" for a field of an electronic message: title, sender, subject, verbose, or terse.
+//   - "0BD9:subtitle:3" for the subtitle 3 of a movie.
+type textUnit struct {
+	id    resource.ID
+	index int
+	field string
+}
+
+func (unit textUnit) String() string {
+	switch {
+	case unit.field == subtitlePart:
+		return fmt.Sprintf("%v:%s:%d", unit.id, subtitlePart, unit.index)
+	case len(unit.field) > 0:
+		return fmt.Sprintf("%v:%s", unit.id, unit.field)
+	case unit.index >= 0:
+		return fmt.Sprintf("%v:%d", unit.id, unit.index)
+	default:
+		return unit.id.String()
+	}
+}
+
+// viewKey returns the key of the unit as the editor views identify texts: the ID of the first resource
+// of its kind, and the index within. For subtitles, the index is the one of the subtitle.
+func (unit textUnit) viewKey(lang resource.Language) resource.Key {
+	info, _ := ids.Info(unit.id)
+	switch {
+	case unit.field == subtitlePart, unit.index >= 0:
+		return resource.KeyOf(unit.id, lang, unit.index)
+	default:
+		return resource.KeyOf(info.StartID, lang, int(unit.id.Value()-info.StartID.Value()))
+	}
+}
+
+func textUnitFrom(value string) (textUnit, bool) {
+	parts := strings.Split(value, ":")
+	idValue, err := strconv.ParseUint(parts[0], 16, 16)
+	if (err != nil) || (len(parts) > 3) {
+		return textUnit{}, false
+	}
+	unit := textUnit{id: resource.ID(idValue), index: -1}
+	info, known := ids.Info(unit.id)
+	switch len(parts) {
+	case 1:
+		return unit, known && (info.ContentType == resource.Text) && !info.List && !isElectronicMessage(unit.id)
+	case 2:
+		if index, err := strconv.Atoi(parts[1]); err == nil {
+			unit.index = index
+			return unit, known && (info.ContentType == resource.Text) && info.List && (index >= 0) && (unit.id == info.StartID)
+		}
+		unit.field = parts[1]
+		return unit, isElectronicMessage(unit.id) && (messageField(&text.ElectronicMessage{}, unit.field) != nil)
+	default:
+		index, err := strconv.Atoi(parts[2])
+		unit.field = parts[1]
+		unit.index = index
+		return unit, (err == nil) && (index >= 0) && (unit.field == subtitlePart) && isSubtitledMovie(unit.id)
+	}
+}
+
+// textTitle returns the title of known texts, and a generic one for the others, such as object names.
+func textTitle(id resource.ID) string {
+	for _, info := range KnownTexts() {
+		if info.ID == id {
+			return info.Title
+		}
+	}
+	return fmt.Sprintf("Texts %v", id)
+}
+
+func isSubtitledMovie(id resource.ID) bool {
+	for _, subtitled := range subtitledMovies {
+		if subtitled.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// gameTexts reads and writes all texts of the game: the lists of texts, the texts spanning a resource,
+// electronic messages, and the subtitles of movies.
+type gameTexts struct {
+	cp        text.Codepage
+	resources TextResources
+
+	textViewer   media.TextViewerService
+	textSetter   media.TextSetterService
+	messageCache *text.ElectronicMessageCache
+	movieService MovieService
+}
+
+// forEach calls the handler for all texts of given language that are not empty, together with a description.
+func (texts gameTexts) forEach(lang resource.Language, handler func(unit textUnit, title string, value string)) {
+	report := func(unit textUnit, title string, value string) {
+		if len(value) > 0 {
+			handler(unit, title, value)
+		}
+	}
+	for _, info := range ids.InfosOf(resource.Text, ids.CybStrng) {
+		switch {
+		case info.List:
+			title := textTitle(info.StartID)
+			for index := 0; index < texts.listLength(info, lang); index++ {
+				report(textUnit{id: info.StartID, index: index}, fmt.Sprintf("%s %d", title, index),
+					texts.textViewer.Text(resource.KeyOf(info.StartID, lang, index)))
+			}
+		case isElectronicMessage(info.StartID):
+			for index := 0; index < info.MaxCount; index++ {
+				id := info.StartID.Plus(index)
+				message, err := texts.messageCache.Message(resource.KeyOf(id, lang, 0))
+				if err != nil {
+					continue
+				}
+				for _, field := range messageFields {
+					report(textUnit{id: id, index: -1, field: field}, fmt.Sprintf("Message %v, %s", id, field),
+						*messageField(&message, field))
+				}
+			}
+		default:
+			title := textTitle(info.StartID)
+			for index := 0; index < info.MaxCount; index++ {
+				id := info.StartID.Plus(index)
+				report(textUnit{id: id, index: -1}, fmt.Sprintf("%s %d", title, index),
+					texts.textViewer.Text(resource.KeyOf(id, lang, 0)))
+			}
+		}
+	}
+	for _, subtitled := range subtitledMovies {
+		list := texts.movieService.Subtitles(texts.movieKey(subtitled.id, lang), lang)
+		for index, entry := range list.Entries {
+			report(textUnit{id: subtitled.id, index: index, field: subtitlePart},
+				fmt.Sprintf("Movie %s, subtitle at %v", subtitled.title, entry.Timestamp), entry.Text)
+		}
+	}
+}
+
+// text returns the current value of identified text.
+func (texts gameTexts) text(unit textUnit, lang resource.Language) string {
+	switch {
+	case unit.field == subtitlePart:
+		list := texts.movieService.Subtitles(texts.movieKey(unit.id, lang), lang)
+		if unit.index < len(list.Entries) {
+			return list.Entries[unit.index].Text
+		}
+		return ""
+	case len(unit.field) > 0:
+		message, err := texts.messageCache.Message(resource.KeyOf(unit.id, lang, 0))
+		if err != nil {
+			return ""
+		}
+		return *messageField(&message, unit.field)
+	case unit.index >= 0:
+		return texts.textViewer.Text(resource.KeyOf(unit.id, lang, unit.index))
+	default:
+		return texts.textViewer.Text(resource.KeyOf(unit.id, lang, 0))
+	}
+}
+
+func (texts gameTexts) listLength(info ids.ResourceInfo, lang resource.Language) int {
+	if info.MaxCount > 0 {
+		return info.MaxCount
+	}
+	view, err := texts.resources.LocalizedResources(lang).Select(info.StartID)
+	if err != nil {
+		return 0
+	}
+	return view.BlockCount()
+}
+
+func (texts gameTexts) movieKey(id resource.ID, lang resource.Language) resource.Key {
+	for _, subtitled := range subtitledMovies {
+		if (subtitled.id == id) && !subtitled.multilang {
+			return resource.KeyOf(id, resource.LangDefault, 0)
+		}
+	}
+	return resource.KeyOf(id, lang, 0)
+}
+
+// set stores the given values of the texts, per language.
+// The fields of an electronic message, and the subtitles of a movie in all languages, are stored together.
+func (texts gameTexts) set(setter media.TextBlockSetter, values map[resource.Language]map[textUnit]string) {
+	type messageKey struct {
+		lang resource.Language
+		id   resource.ID
+	}
+	messages := make(map[messageKey]text.ElectronicMessage)
+	subtitles := make(map[resource.Key]map[resource.Language]movie.SubtitleList)
+	for lang, units := range values {
+		for unit, value := range units {
+			switch {
+			case unit.field == subtitlePart:
+				key := texts.movieKey(unit.id, lang)
+				lists, known := subtitles[key]
+				if !known {
+					lists = make(map[resource.Language]movie.SubtitleList)
+					subtitles[key] = lists
+				}
+				list, known := lists[lang]
+				if !known {
+					list = texts.movieService.Subtitles(key, lang)
+				}
+				lists[lang] = texts.withSubtitle(list, unit, value)
+			case len(unit.field) > 0:
+				key := messageKey{lang: lang, id: unit.id}
+				message, known := messages[key]
+				if !known {
+					message = texts.baseMessage(unit.id, lang)
+				}
+				*messageField(&message, unit.field) = value
+				messages[key] = message
+			case unit.index >= 0:
+				texts.textSetter.Set(setter, resource.KeyOf(unit.id, lang, unit.index), value)
+			default:
+				texts.textSetter.Set(setter, resource.KeyOf(unit.id, lang, 0), value)
+			}
+		}
+	}
+	for key, message := range messages {
+		setter.SetResourceBlocks(key.lang, key.id, message.Encode(texts.cp))
+	}
+	for key, lists := range subtitles {
+		texts.movieService.SetSubtitlesPerLanguage(setter, key, lists)
+	}
+}
+
+// baseMessage returns the message to store fields in. For messages that do not exist in the given
+// language yet, the properties are taken from the default language.
+func (texts gameTexts) baseMessage(id resource.ID, lang resource.Language) text.ElectronicMessage {
+	message, err := texts.messageCache.Message(resource.KeyOf(id, lang, 0))
+	if err == nil {
+		return message
+	}
+	message, err = texts.messageCache.Message(resource.KeyOf(id, resource.LangDefault, 0))
+	if err != nil {
+		return text.EmptyElectronicMessage()
+	}
+	for _, field := range messageFields {
+		*messageField(&message, field) = ""
+	}
+	return message
+}
+
+// withSubtitle returns the list with the given subtitle. Subtitles that are missing in the list
+// are added with the timestamp of the first language that has them.
+func (texts gameTexts) withSubtitle(list movie.SubtitleList, unit textUnit, value string) movie.SubtitleList {
+	entries := append([]movie.Subtitle{}, list.Entries...)
+	if unit.index < len(entries) {
+		entries[unit.index].Text = value
+		return movie.SubtitleList{Entries: entries}
+	}
+	for _, lang := range resource.Languages() {
+		other := texts.movieService.Subtitles(texts.movieKey(unit.id, lang), lang)
+		if unit.index < len(other.Entries) {
+			entries = append(entries, movie.Subtitle{Timestamp: other.Entries[unit.index].Timestamp, Text: value})
+			sort.SliceStable(entries, func(a, b int) bool { return entries[a].Timestamp < entries[b].Timestamp })
+			break
+		}
+	}
+	return movie.SubtitleList{Entries: entries}
+}
+
+// restoreFunc creates a snapshot of all resources that set() would change for the given texts,
+// and returns a function to restore them.
+func (texts gameTexts) restoreFunc(values map[resource.Language]map[textUnit]string) func(setter media.TextBlockSetter) {
+	type resourceKey struct {
+		lang resource.Language
+		id   resource.ID
+	}
+	snapshots := make(map[resourceKey][][]byte)
+	for lang, units := range values {
+		for unit := range units {
+			key := resourceKey{lang: lang, id: unit.id}
+			if unit.field == subtitlePart {
+				key.lang = texts.movieKey(unit.id, lang).Lang
+			}
+			if _, captured := snapshots[key]; !captured {
+				snapshots[key] = texts.resources.ModifiedBlocks(key.lang, key.id)
+			}
+		}
+	}
+	return func(setter media.TextBlockSetter) {
+		for key, data := range snapshots {
+			if len(data) > 0 {
+				setter.SetResourceBlocks(key.lang, key.id, data)
+			} else {
+				setter.DelResource(key.lang, key.id)
+			}
+		}
+	}
+}
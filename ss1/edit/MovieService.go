@@ -135,8 +135,17 @@ func (service MovieService) Subtitles(key resource.Key, language resource.Langua
 // SetSubtitles sets the subtitles of identified movie in given language.
 func (service MovieService) SetSubtitles(setter media.MovieBlockSetter, key resource.Key,
 	language resource.Language, subtitles movie.SubtitleList) {
+	service.SetSubtitlesPerLanguage(setter, key, map[resource.Language]movie.SubtitleList{language: subtitles})
+}
+
+// SetSubtitlesPerLanguage sets the subtitles of identified movie in several languages at once.
+// The movie is stored only once, which is necessary for movies that keep the subtitles of all languages.
+func (service MovieService) SetSubtitlesPerLanguage(setter media.MovieBlockSetter, key resource.Key,
+	subtitles map[resource.Language]movie.SubtitleList) {
 	baseContainer := service.getBaseContainer(key)
-	baseContainer.Subtitles.PerLanguage[language] = subtitles
+	for language, list := range subtitles {
+		baseContainer.Subtitles.PerLanguage[language] = list
+	}
 	service.movieSetter.Set(setter, key, baseContainer)
 }
 
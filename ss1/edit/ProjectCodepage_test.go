@@ -0,0 +1,76 @@
+package edit_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/edit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type codepageUser struct {
+	cp text.Codepage
+}
+
+func (user *codepageUser) UseCodepage(cp text.Codepage) {
+	user.cp = cp
+}
+
+func TestProjectServiceRestoresCodepageOfProject(t *testing.T) {
+	projectDir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(projectDir, "polish.txt"), []byte("0x86 0x0105\n"), 0640)
+	require.Nil(t, err, "no error expected writing table")
+	service := givenProjectService()
+	var user codepageUser
+	service.SetCodepageUser(&user)
+	settings := edit.ProjectSettings{
+		PathMode: edit.ProjectPathsRelative,
+		Codepage: "polish.txt",
+	}
+
+	err = service.RestoreProject(settings, filepath.Join(projectDir, "test.hacked-project"))
+	require.Nil(t, err, "no error expected")
+	require.NotNil(t, user.cp, "codepage expected")
+	assert.Equal(t, "ą", user.cp.Decode([]byte{0x86, 0x00}), "codepage of table expected")
+	assert.Equal(t, "polish.txt", service.CurrentSettings().Codepage, "codepage should be stored again")
+
+	service.ResetProject()
+	assert.Equal(t, "å", user.cp.Decode([]byte{0x86, 0x00}), "default codepage expected after reset")
+	assert.Equal(t, "", service.CurrentSettings().Codepage, "no codepage should be stored")
+}
+
+func TestProjectServiceKeepsCodepageIfTableIsInvalid(t *testing.T) {
+	projectDir := t.TempDir()
+	filename := filepath.Join(projectDir, "broken.txt")
+	err := ioutil.WriteFile(filename, []byte("0x86\n"), 0640)
+	require.Nil(t, err, "no error expected writing table")
+	service := givenProjectService()
+
+	err = service.SetCodepageFile(filename)
+
+	assert.Error(t, err, "error expected")
+	assert.Equal(t, "", service.CodepageFile(), "previous codepage should be kept")
+}
+
+func TestProjectServiceKeepsCodepageOfProjectThatCanNotBeLoaded(t *testing.T) {
+	projectDir := t.TempDir()
+	service := givenProjectService()
+	settings := edit.ProjectSettings{
+		PathMode: edit.ProjectPathsRelative,
+		Codepage: "missing.txt",
+	}
+
+	err := service.RestoreProject(settings, filepath.Join(projectDir, "test.hacked-project"))
+	assert.Error(t, err, "error expected")
+	assert.Equal(t, "missing.txt", service.UnresolvedCodepage(), "table should be unresolved")
+	assert.Equal(t, "missing.txt", service.CurrentSettings().Codepage, "table should be stored again")
+
+	err = service.SetCodepageFile("")
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, "", service.UnresolvedCodepage(), "no unresolved table expected")
+	assert.Equal(t, "", service.CurrentSettings().Codepage, "no codepage should be stored")
+}
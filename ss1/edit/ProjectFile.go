@@ -0,0 +1,118 @@
+package edit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1"
+)
+
+const (
+	errProjectFileMalformed ss1.StringError = "project file is malformed"
+)
+
+// ProjectFileVersion is the version of the project file schema this build writes.
+//
+// Version history:
+//   - 1: The initial format, without an explicit version. Editor state is stored at the top level.
+//   - 2: Adds the version, and stores the editor state, including recently used folders, in "Editor".
+const ProjectFileVersion = 2
+
+// ProjectFileVersionError is reported for project files that were written by a newer build.
+type ProjectFileVersionError struct {
+	Version int
+}
+
+// Error returns the textual representation.
+func (err ProjectFileVersionError) Error() string {
+	return fmt.Sprintf("project file has version %d, only up to version %d is supported", err.Version, ProjectFileVersion)
+}
+
+// ProjectFile is the content of a project file.
+type ProjectFile struct {
+	Version int
+
+	ProjectSettings   *ProjectSettings   `json:",omitempty"`
+	GameStateSettings *GameStateSettings `json:",omitempty"`
+	Editor            *EditorSettings    `json:",omitempty"`
+}
+
+// EditorSettings describe the state of the editor for a project.
+type EditorSettings struct {
+	OpenWindows      []string `json:",omitempty"`
+	ActiveLevelIndex *int     `json:",omitempty"`
+
+	LastImportDir string `json:",omitempty"`
+	LastExportDir string `json:",omitempty"`
+}
+
+// NewProjectFile returns a project file of the current version.
+func NewProjectFile() ProjectFile {
+	return ProjectFile{Version: ProjectFileVersion}
+}
+
+// ProjectFileFrom decodes a project file from given serialized form.
+// Files of older versions are migrated to the current version.
+func ProjectFileFrom(data []byte) (ProjectFile, error) {
+	migrated, err := MigrateProjectFile(data)
+	if err != nil {
+		return ProjectFile{}, err
+	}
+	var file ProjectFile
+	err = json.Unmarshal(migrated, &file)
+	if err != nil {
+		return ProjectFile{}, errProjectFileMalformed
+	}
+	return file, nil
+}
+
+// MigrateProjectFile returns the serialized form of given project file in the current version.
+// Properties that are not part of the schema are kept as they are, which allows to embed
+// the project file in other documents.
+func MigrateProjectFile(data []byte) ([]byte, error) {
+	var properties map[string]json.RawMessage
+	err := json.Unmarshal(data, &properties)
+	if (err != nil) || (properties == nil) {
+		return nil, errProjectFileMalformed
+	}
+	version := 1
+	if rawVersion, hasVersion := properties["Version"]; hasVersion {
+		err = json.Unmarshal(rawVersion, &version)
+		if (err != nil) || (version < 1) {
+			return nil, errProjectFileMalformed
+		}
+	}
+	if version > ProjectFileVersion {
+		return nil, ProjectFileVersionError{Version: version}
+	}
+	for ; version < ProjectFileVersion; version++ {
+		err = projectFileMigrations[version-1](properties)
+		if err != nil {
+			return nil, err
+		}
+	}
+	properties["Version"], _ = json.Marshal(version)
+	return json.Marshal(properties)
+}
+
+// projectFileMigrations lists the functions that upgrade the properties of a project file by one version.
+// The entry at index n upgrades from version n+1.
+var projectFileMigrations = []func(properties map[string]json.RawMessage) error{
+	migrateProjectFileFromVersion1,
+}
+
+func migrateProjectFileFromVersion1(properties map[string]json.RawMessage) error {
+	editor := make(map[string]json.RawMessage)
+	for _, key := range []string{"OpenWindows", "ActiveLevelIndex"} {
+		if value, isSet := properties[key]; isSet {
+			editor[key] = value
+			delete(properties, key)
+		}
+	}
+	if len(editor) == 0 {
+		return nil
+	}
+	var err error
+	properties["Editor"], err = json.Marshal(editor)
+	return err
+}
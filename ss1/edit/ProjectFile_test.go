@@ -0,0 +1,74 @@
+package edit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectFileFromMigratesUnversionedFiles(t *testing.T) {
+	data := []byte(`{
+		"ProjectSettings": {"ModFiles": ["mod/cybstrng.res"], "Manifest": [{"Origin": ["data"]}]},
+		"GameStateSettings": {"BaseContext": 1},
+		"OpenWindows": ["project", "texts"],
+		"ActiveLevelIndex": 3
+	}`)
+
+	file, err := edit.ProjectFileFrom(data)
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, edit.ProjectFileVersion, file.Version, "version should be current")
+	require.NotNil(t, file.ProjectSettings, "project settings expected")
+	assert.Equal(t, []string{"mod/cybstrng.res"}, file.ProjectSettings.ModFiles, "mod files should be kept")
+	require.NotNil(t, file.GameStateSettings, "game state settings expected")
+	assert.Equal(t, 1, file.GameStateSettings.BaseContext, "base context should be kept")
+	require.NotNil(t, file.Editor, "editor settings expected")
+	assert.Equal(t, []string{"project", "texts"}, file.Editor.OpenWindows, "open windows should be moved")
+	require.NotNil(t, file.Editor.ActiveLevelIndex, "active level expected")
+	assert.Equal(t, 3, *file.Editor.ActiveLevelIndex, "active level should be moved")
+}
+
+func TestProjectFileFromAcceptsCurrentVersion(t *testing.T) {
+	level := 5
+	file := edit.NewProjectFile()
+	file.Editor = &edit.EditorSettings{
+		ActiveLevelIndex: &level,
+		LastImportDir:    "/home/user/import",
+		LastExportDir:    "/home/user/export",
+	}
+	data, err := json.Marshal(file)
+	require.Nil(t, err, "no error expected marshalling")
+
+	restored, err := edit.ProjectFileFrom(data)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, file, restored, "file should be restored")
+}
+
+func TestProjectFileFromRejectsNewerVersions(t *testing.T) {
+	_, err := edit.ProjectFileFrom([]byte(`{"Version": 1000}`))
+
+	assert.Equal(t, edit.ProjectFileVersionError{Version: 1000}, err, "version error expected")
+}
+
+func TestProjectFileFromRejectsMalformedData(t *testing.T) {
+	for _, data := range []string{``, `null`, `[]`, `{"Version": "two"}`, `{"Version": 0}`, `{"Editor": 12}`} {
+		_, err := edit.ProjectFileFrom([]byte(data))
+		assert.NotNil(t, err, "error expected for <%s>", data)
+	}
+}
+
+func TestMigrateProjectFileKeepsUnknownProperties(t *testing.T) {
+	migrated, err := edit.MigrateProjectFile([]byte(`{"LastProject": "some.hacked-project", "OpenWindows": ["texts"]}`))
+	require.Nil(t, err, "no error expected")
+
+	var properties map[string]interface{}
+	err = json.Unmarshal(migrated, &properties)
+	require.Nil(t, err, "no error expected unmarshalling")
+	assert.Equal(t, "some.hacked-project", properties["LastProject"], "unknown property should be kept")
+	assert.Equal(t, float64(edit.ProjectFileVersion), properties["Version"], "version should be set")
+	assert.Nil(t, properties["OpenWindows"], "open windows should be moved")
+}
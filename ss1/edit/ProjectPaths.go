@@ -0,0 +1,163 @@
+package edit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProjectPathMode describes how the paths of the static world data are stored in a project file.
+type ProjectPathMode string
+
+const (
+	// ProjectPathsAbsolute stores the paths of the static world data as absolute paths.
+	ProjectPathsAbsolute ProjectPathMode = ""
+	// ProjectPathsRelative stores the paths of the static world data relative to the project file,
+	// so that the project can be used on other machines as well.
+	ProjectPathsRelative ProjectPathMode = "relative"
+)
+
+const pathVariablePrefix = "$"
+
+// UnknownPathVariableError is reported for paths that refer to a variable that is not defined.
+type UnknownPathVariableError struct {
+	Name string
+}
+
+// Error returns the textual representation.
+func (err UnknownPathVariableError) Error() string {
+	return fmt.Sprintf("path variable %s%s is not defined", pathVariablePrefix, err.Name)
+}
+
+// PathVariables map names to folders. Paths in project files can start with a variable, such as "$GAME/data",
+// which are resolved when the project is loaded. This allows to refer to folders that differ between machines.
+type PathVariables map[string]string
+
+// Expand resolves a leading variable in given path. Variables that are not defined in the map, or are empty there,
+// are taken from the environment. Paths without a variable are returned as they are.
+func (vars PathVariables) Expand(path string) (string, error) {
+	if !strings.HasPrefix(path, pathVariablePrefix) {
+		return path, nil
+	}
+	name := path[len(pathVariablePrefix):]
+	rest := ""
+	if separator := strings.IndexAny(name, `/\`); separator >= 0 {
+		name, rest = name[:separator], name[separator+1:]
+	}
+	value, defined := vars.lookup(name)
+	if !defined {
+		return "", UnknownPathVariableError{Name: name}
+	}
+	if len(rest) == 0 {
+		return filepath.Clean(value), nil
+	}
+	return filepath.Join(value, filepath.FromSlash(rest)), nil
+}
+
+// lookup returns the folder of the named variable, as Expand() resolves it.
+func (vars PathVariables) lookup(name string) (string, bool) {
+	value := vars[name]
+	if len(value) == 0 {
+		value = os.Getenv(name)
+	}
+	return value, len(value) > 0
+}
+
+// Collapse replaces the start of given path with the variable of the longest matching folder.
+// Folders of the variables are resolved as Expand() does, so variables that are empty in the map are taken
+// from the environment. The remainder of the path is separated by slashes. If no variable matches,
+// the path is returned as it is.
+func (vars PathVariables) Collapse(path string) string {
+	bestName := ""
+	bestLength := 0
+	for _, name := range vars.Names() {
+		folder, defined := vars.lookup(name)
+		value := filepath.Clean(folder)
+		if !defined || (len(value) <= bestLength) {
+			continue
+		}
+		if (path == value) || strings.HasPrefix(path, strings.TrimSuffix(value, string(filepath.Separator))+string(filepath.Separator)) {
+			bestName = name
+			bestLength = len(value)
+		}
+	}
+	if bestLength == 0 {
+		return path
+	}
+	collapsed := pathVariablePrefix + bestName
+	rest := strings.TrimLeft(path[bestLength:], string(filepath.Separator))
+	if len(rest) > 0 {
+		collapsed += "/" + filepath.ToSlash(rest)
+	}
+	return collapsed
+}
+
+// Names returns the sorted names of all variables.
+func (vars PathVariables) Names() []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnresolvedManifestEntry describes an entry of the static world data that could not be loaded.
+type UnresolvedManifestEntry struct {
+	// Index is the position of the entry within the manifest of the project, counting the unresolved entries.
+	// The entry is stored at this position again.
+	Index int
+	// Origin are the paths as they are stored in the project.
+	Origin []string
+	// Err is the reason why the entry could not be loaded.
+	Err error
+}
+
+// UnresolvedMod describes a mod, or a parent mod, of the project that could not be loaded.
+type UnresolvedMod struct {
+	// Index is the position of a parent mod within the parent mods of the project, counting the unresolved ones.
+	// The parent mod is stored at this position again. It is not used for the mod itself.
+	Index int
+	// Origin are the paths as they are stored in the project.
+	Origin []string
+	// Err is the reason why the mod could not be loaded.
+	Err error
+}
+
+// UnresolvedPathsError is reported when a project refers to static world data or mods that could not be loaded.
+type UnresolvedPathsError struct {
+	Entries    []UnresolvedManifestEntry
+	ParentMods []UnresolvedMod
+	// Mod is set if the mod itself could not be loaded.
+	Mod *UnresolvedMod
+}
+
+// Error returns the textual representation, listing each entry and mod.
+func (err UnresolvedPathsError) Error() string {
+	var lines []string
+	if len(err.Entries) > 0 {
+		lines = append(lines, fmt.Sprintf("%d entries of the static world data could not be loaded:", len(err.Entries)))
+	}
+	for _, entry := range err.Entries {
+		lines = append(lines, fmt.Sprintf("#%d %s: %v", entry.Index, strings.Join(entry.Origin, ", "), entry.Err))
+	}
+	if len(err.ParentMods) > 0 {
+		lines = append(lines, fmt.Sprintf("%d parent mods could not be loaded:", len(err.ParentMods)))
+	}
+	for _, parent := range err.ParentMods {
+		lines = append(lines, fmt.Sprintf("#%d %s: %v", parent.Index, strings.Join(parent.Origin, ", "), parent.Err))
+	}
+	if err.Mod != nil {
+		lines = append(lines, fmt.Sprintf("the mod could not be loaded from %s: %v", strings.Join(err.Mod.Origin, ", "), err.Mod.Err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func unresolvedPathsError(entries []UnresolvedManifestEntry, parentMods []UnresolvedMod, mod *UnresolvedMod) error {
+	if (len(entries) == 0) && (len(parentMods) == 0) && (mod == nil) {
+		return nil
+	}
+	return UnresolvedPathsError{Entries: entries, ParentMods: parentMods, Mod: mod}
+}
@@ -0,0 +1,262 @@
+package edit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/world"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathVariablesExpand(t *testing.T) {
+	vars := edit.PathVariables{"GAME": filepath.FromSlash("/games/ss1")}
+
+	tt := []struct {
+		path     string
+		expected string
+	}{
+		{path: "$GAME", expected: filepath.FromSlash("/games/ss1")},
+		{path: "$GAME/data", expected: filepath.FromSlash("/games/ss1/data")},
+		{path: "$GAME/data/cybstrng.res", expected: filepath.FromSlash("/games/ss1/data/cybstrng.res")},
+		{path: "data/cybstrng.res", expected: "data/cybstrng.res"},
+	}
+	for _, tc := range tt {
+		expanded, err := vars.Expand(tc.path)
+		require.Nil(t, err, "no error expected for %s", tc.path)
+		assert.Equal(t, tc.expected, expanded, "wrong result for %s", tc.path)
+	}
+}
+
+func TestPathVariablesExpandFallsBackToEnvironment(t *testing.T) {
+	t.Setenv("HACKED_TEST_DATA", filepath.FromSlash("/env/data"))
+	vars := edit.PathVariables{}
+
+	expanded, err := vars.Expand("$HACKED_TEST_DATA/archive.dat")
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, filepath.FromSlash("/env/data/archive.dat"), expanded)
+}
+
+func TestPathVariablesExpandReportsUnknownVariables(t *testing.T) {
+	vars := edit.PathVariables{"GAME": ""}
+
+	_, err := vars.Expand("$GAME/data")
+	assert.Equal(t, edit.UnknownPathVariableError{Name: "GAME"}, err, "empty variable is not defined")
+	_, err = vars.Expand("$HACKED_TEST_UNDEFINED/data")
+	assert.Equal(t, edit.UnknownPathVariableError{Name: "HACKED_TEST_UNDEFINED"}, err, "unknown variable expected")
+}
+
+func TestPathVariablesCollapse(t *testing.T) {
+	vars := edit.PathVariables{
+		"GAME": filepath.FromSlash("/games/ss1"),
+		"CD":   filepath.FromSlash("/games/ss1/cd/"),
+		"GAM":  filepath.FromSlash("/games/ss"),
+	}
+
+	tt := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/games/ss1", expected: "$GAME"},
+		{path: "/games/ss1/data/cybstrng.res", expected: "$GAME/data/cybstrng.res"},
+		{path: "/games/ss1/cd/data", expected: "$CD/data"},
+		{path: "/games/ss10/data", expected: filepath.FromSlash("/games/ss10/data")},
+		{path: "/other/data", expected: filepath.FromSlash("/other/data")},
+	}
+	for _, tc := range tt {
+		assert.Equal(t, tc.expected, vars.Collapse(filepath.FromSlash(tc.path)), "wrong result for %s", tc.path)
+	}
+}
+
+func TestPathVariablesCollapseUsesEnvironmentForEmptyVariables(t *testing.T) {
+	gameDir := filepath.FromSlash("/games/ss1")
+	t.Setenv("HACKED_TEST_GAME", gameDir)
+	vars := edit.PathVariables{"HACKED_TEST_GAME": ""}
+
+	assert.Equal(t, "$HACKED_TEST_GAME/data", vars.Collapse(filepath.Join(gameDir, "data")))
+	expanded, err := vars.Expand("$HACKED_TEST_GAME/data")
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, filepath.Join(gameDir, "data"), expanded)
+}
+
+func TestProjectServiceRestoreProjectListsUnresolvedEntries(t *testing.T) {
+	baseDir := t.TempDir()
+	gameDir := filepath.Join(baseDir, "game")
+	givenResourceFile(t, filepath.Join(gameDir, "data", "cybstrng.res"))
+	service := givenProjectService()
+	service.SetPathVariables(edit.PathVariables{"GAME": gameDir})
+	settings := edit.ProjectSettings{
+		Manifest: []edit.ManifestEntrySettings{
+			{Origin: []string{"$GAME/data"}},
+			{Origin: []string{"$MISSING_HACKED_VARIABLE/data"}},
+			{Origin: []string{"./missing/data"}},
+		},
+	}
+
+	err := service.RestoreProject(settings, filepath.Join(baseDir, "project", "test.hacked-project"))
+
+	unresolved, isUnresolved := err.(edit.UnresolvedPathsError)
+	require.True(t, isUnresolved, "unresolved paths expected")
+	require.Equal(t, 2, len(unresolved.Entries), "two entries expected")
+	assert.Equal(t, 1, unresolved.Entries[0].Index, "first unresolved entry")
+	assert.Equal(t, edit.UnknownPathVariableError{Name: "MISSING_HACKED_VARIABLE"}, unresolved.Entries[0].Err)
+	assert.Equal(t, 2, unresolved.Entries[1].Index, "second unresolved entry")
+	assert.Equal(t, 1, service.Mod().World().EntryCount(), "resolved entry should be loaded")
+	assert.Equal(t, 2, len(service.UnresolvedManifestEntries()), "unresolved entries should be kept")
+	assert.Equal(t, settings.Manifest, service.CurrentSettings().Manifest, "all entries should be stored again")
+}
+
+func TestProjectServiceCurrentSettingsStoresRelativePaths(t *testing.T) {
+	projectDir := t.TempDir()
+	givenResourceFile(t, filepath.Join(projectDir, "data", "cybstrng.res"))
+	service := givenProjectService()
+	settings := edit.ProjectSettings{
+		Manifest: []edit.ManifestEntrySettings{{Origin: []string{"./data"}}},
+		PathMode: edit.ProjectPathsRelative,
+	}
+
+	err := service.RestoreProject(settings, filepath.Join(projectDir, "test.hacked-project"))
+	require.Nil(t, err, "no error expected")
+	entry, err := service.Mod().World().Entry(0)
+	require.Nil(t, err, "entry expected")
+	assert.Equal(t, []string{filepath.Join(projectDir, "data")}, entry.Origin, "entry should be loaded from absolute path")
+	current := service.CurrentSettings()
+	assert.Equal(t, edit.ProjectPathsRelative, current.PathMode, "path mode should be kept")
+	assert.Equal(t, []edit.ManifestEntrySettings{{Origin: []string{"data"}}}, current.Manifest,
+		"paths should be stored relative")
+
+	service.SetPathMode(edit.ProjectPathsAbsolute)
+	assert.Equal(t, []edit.ManifestEntrySettings{{Origin: []string{filepath.Join(projectDir, "data")}}},
+		service.CurrentSettings().Manifest, "paths should be stored absolute")
+}
+
+func TestProjectServiceRetryUnresolvedEntries(t *testing.T) {
+	gameDir := t.TempDir()
+	service := givenProjectService()
+	settings := edit.ProjectSettings{
+		Manifest: []edit.ManifestEntrySettings{{Origin: []string{"$GAME/data"}}},
+	}
+	err := service.RestoreProject(settings, "")
+	require.NotNil(t, err, "error expected")
+
+	givenResourceFile(t, filepath.Join(gameDir, "data", "cybstrng.res"))
+	service.SetPathVariables(edit.PathVariables{"GAME": gameDir})
+	err = service.RetryUnresolvedEntries()
+
+	assert.Nil(t, err, "no error expected")
+	assert.Equal(t, 1, service.Mod().World().EntryCount(), "entry should be loaded")
+	assert.Empty(t, service.UnresolvedManifestEntries(), "no unresolved entries expected")
+}
+
+func TestProjectServiceKeepsUnresolvedEntriesAtTheirIndex(t *testing.T) {
+	baseDir := t.TempDir()
+	givenResourceFile(t, filepath.Join(baseDir, "first", "cybstrng.res"))
+	givenResourceFile(t, filepath.Join(baseDir, "third", "cybstrng.res"))
+	givenResourceFile(t, filepath.Join(baseDir, "fifth", "cybstrng.res"))
+	service := givenProjectService()
+	service.SetPathVariables(edit.PathVariables{"BASE": baseDir})
+	settings := edit.ProjectSettings{
+		Manifest: []edit.ManifestEntrySettings{
+			{Origin: []string{"$BASE/first"}},
+			{Origin: []string{"$BASE/second"}},
+			{Origin: []string{"$BASE/third"}},
+			{Origin: []string{"$BASE/fourth"}},
+			{Origin: []string{"$BASE/fifth"}},
+		},
+	}
+
+	err := service.RestoreProject(settings, filepath.Join(baseDir, "test.hacked-project"))
+	require.NotNil(t, err, "error expected")
+	assert.Equal(t, settings.Manifest, service.CurrentSettings().Manifest, "entries should be stored at their index")
+
+	givenResourceFile(t, filepath.Join(baseDir, "fourth", "cybstrng.res"))
+	err = service.RetryUnresolvedEntries()
+	unresolved, isUnresolved := err.(edit.UnresolvedPathsError)
+	require.True(t, isUnresolved, "unresolved paths expected")
+	require.Equal(t, 1, len(unresolved.Entries), "one entry expected")
+	assert.Equal(t, 1, unresolved.Entries[0].Index, "index should refer to the manifest")
+
+	assert.Equal(t, 4, service.Mod().World().EntryCount(), "retried entry should be loaded")
+	entry, err := service.Mod().World().Entry(2)
+	require.Nil(t, err, "entry expected")
+	assert.Equal(t, []string{filepath.Join(baseDir, "fourth")}, entry.Origin, "entry should be inserted at its position")
+	assert.Equal(t, settings.Manifest, service.CurrentSettings().Manifest, "entries should be stored at their index")
+}
+
+func TestProjectServiceRestoreProjectKeepsUnresolvedMods(t *testing.T) {
+	baseDir := t.TempDir()
+	givenResourceFile(t, filepath.Join(baseDir, "first", "cybstrng.res"))
+	service := givenProjectService()
+	service.SetPathVariables(edit.PathVariables{"BASE": baseDir})
+	settings := edit.ProjectSettings{
+		ModFiles:   []string{"$MOD/cybstrng.res"},
+		ParentMods: []string{"$MOD/second", "$BASE/first"},
+	}
+
+	err := service.RestoreProject(settings, filepath.Join(baseDir, "test.hacked-project"))
+
+	unresolved, isUnresolved := err.(edit.UnresolvedPathsError)
+	require.True(t, isUnresolved, "unresolved paths expected")
+	require.NotNil(t, unresolved.Mod, "unresolved mod expected")
+	assert.Equal(t, edit.UnknownPathVariableError{Name: "MOD"}, unresolved.Mod.Err)
+	require.Equal(t, 1, len(unresolved.ParentMods), "one parent mod expected")
+	assert.Equal(t, 0, unresolved.ParentMods[0].Index, "index should refer to the parent mods")
+	assert.Equal(t, 1, len(service.Mod().ParentLayers()), "resolved parent mod should be loaded")
+	current := service.CurrentSettings()
+	assert.Equal(t, settings.ModFiles, current.ModFiles, "mod should be stored again")
+	assert.Equal(t, settings.ParentMods, current.ParentMods, "parent mods should be stored at their index")
+}
+
+func TestProjectServiceRetryUnresolvedPathsLoadsMods(t *testing.T) {
+	baseDir := t.TempDir()
+	modDir := t.TempDir()
+	givenResourceFile(t, filepath.Join(baseDir, "first", "cybstrng.res"))
+	givenResourceFile(t, filepath.Join(modDir, "second", "cybstrng.res"))
+	givenResourceFile(t, filepath.Join(modDir, "cybstrng.res"))
+	service := givenProjectService()
+	settings := edit.ProjectSettings{
+		ModFiles:   []string{"$MOD/cybstrng.res"},
+		ParentMods: []string{filepath.ToSlash(filepath.Join(baseDir, "first")), "$MOD/second"},
+	}
+	err := service.RestoreProject(settings, filepath.Join(baseDir, "test.hacked-project"))
+	require.NotNil(t, err, "error expected")
+
+	service.SetPathVariables(edit.PathVariables{"MOD": modDir})
+	err = service.RetryUnresolvedPaths()
+
+	assert.Nil(t, err, "no error expected")
+	assert.Equal(t, modDir, service.ModPath(), "mod should be loaded")
+	_, isUnresolved := service.UnresolvedMod()
+	assert.False(t, isUnresolved, "mod should no longer be unresolved")
+	assert.Empty(t, service.UnresolvedParentMods(), "no unresolved parent mods expected")
+	layers := service.Mod().ParentLayers()
+	require.Equal(t, 2, len(layers), "both parent mods expected")
+	assert.Equal(t, filepath.Join(modDir, "second"), layers[1].Name, "parent mod should be inserted at its position")
+}
+
+func givenProjectService() *edit.ProjectService {
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	return edit.NewProjectService(nil, mod)
+}
+
+func givenResourceFile(t *testing.T, filename string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(filename), 0750)
+	require.Nil(t, err, "no error expected creating directory")
+	file, err := os.Create(filename)
+	require.Nil(t, err, "no error expected creating file")
+	defer func() { _ = file.Close() }()
+	var store resource.Store
+	err = store.Put(resource.ID(0x0869), resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text},
+		Blocks:     resource.BlocksFrom([][]byte{{0x00}}),
+	})
+	require.Nil(t, err, "no error expected putting resource")
+	err = lgres.Write(file, store)
+	require.Nil(t, err, "no error expected writing resources")
+}
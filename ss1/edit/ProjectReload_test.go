@@ -0,0 +1,153 @@
+package edit_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectServiceReloadsChangedWorldFiles(t *testing.T) {
+	worldDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(worldDir, "cybstrng.res"), []byte{0x41, 0x00})
+	service := givenProjectService()
+	err := service.RestoreProject(edit.ProjectSettings{
+		Manifest: []edit.ManifestEntrySettings{{Origin: []string{worldDir}}},
+	}, "")
+	require.Nil(t, err, "no error expected restoring")
+	service.ReloadChangedFilesNow()
+
+	givenTextsFile(t, filepath.Join(worldDir, "cybstrng.res"), []byte{0x42, 0x42, 0x00})
+	service.ReloadChangedFilesNow()
+
+	entry, err := service.Mod().World().Entry(0)
+	require.Nil(t, err, "entry expected")
+	assert.Equal(t, worldDir, entry.ID, "ID should be kept")
+	view, err := entry.LocalizedResources(resource.LangDefault).Select(ids.PaperTextsStart)
+	require.Nil(t, err, "resource expected")
+	assert.Equal(t, []byte{0x42, 0x42, 0x00}, firstBlockOf(t, view), "world data should be reloaded")
+}
+
+func TestProjectServiceReloadsChangedParentMods(t *testing.T) {
+	parentDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(parentDir, "cybstrng.res"), []byte{0x41, 0x00})
+	service := givenProjectService()
+	err := service.RestoreProject(edit.ProjectSettings{ParentMods: []string{parentDir}}, "")
+	require.Nil(t, err, "no error expected restoring")
+	service.ReloadChangedFilesNow()
+
+	givenTextsFile(t, filepath.Join(parentDir, "cybstrng.res"), []byte{0x42, 0x42, 0x00})
+	service.ReloadChangedFilesNow()
+
+	view, layer := service.Mod().ModifiedResourceLayer(resource.LangDefault, ids.PaperTextsStart)
+	require.NotNil(t, layer, "resource should be from parent layer")
+	assert.Equal(t, []byte{0x42, 0x42, 0x00}, firstBlockOf(t, view), "parent mod should be reloaded")
+}
+
+func TestProjectServiceRestoreProjectKeepsParentModsOfMod(t *testing.T) {
+	parentDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(parentDir, "cybstrng.res"), []byte{0x41, 0x00})
+	modDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(modDir, "cybstrng.res"), []byte{0x42, 0x00})
+	service := givenProjectService()
+
+	err := service.RestoreProject(edit.ProjectSettings{ParentMods: []string{parentDir}, ModFiles: []string{modDir}}, "")
+
+	require.Nil(t, err, "no error expected restoring")
+	assert.Len(t, service.Mod().ParentLayers(), 1, "parent layer expected")
+}
+
+func TestProjectServiceRemovesParentModsForOtherMods(t *testing.T) {
+	parentDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(parentDir, "cybstrng.res"), []byte{0x41, 0x00})
+	modDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(modDir, "cybstrng.res"), []byte{0x42, 0x00})
+	service := givenProjectService()
+
+	err := service.AddParentModFrom(parentDir)
+	require.Nil(t, err, "no error expected adding parent")
+	err = service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading")
+	assert.Empty(t, service.Mod().ParentLayers(), "parent layers should be removed when loading a mod")
+
+	err = service.AddParentModFrom(parentDir)
+	require.Nil(t, err, "no error expected adding parent")
+	err = service.TryLoadModFrom([]string{filepath.Join(modDir, "missing")})
+	require.NotNil(t, err, "error expected loading")
+	assert.Len(t, service.Mod().ParentLayers(), 1, "parent layers should be kept if loading fails")
+
+	service.NewMod()
+	assert.Empty(t, service.Mod().ParentLayers(), "parent layers should be removed for a new mod")
+}
+
+func TestProjectServiceReloadsChangedModWithoutPendingChanges(t *testing.T) {
+	modDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(modDir, "cybstrng.res"), []byte{0x41, 0x00})
+	service := givenProjectService()
+	err := service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading")
+	service.ReloadChangedFilesNow()
+
+	givenTextsFile(t, filepath.Join(modDir, "cybstrng.res"), []byte{0x42, 0x42, 0x00})
+	service.ReloadChangedFilesNow()
+
+	assert.Equal(t, []byte{0x42, 0x42, 0x00}, service.Mod().ModifiedBlock(resource.LangDefault, ids.PaperTextsStart, 0))
+	assert.False(t, service.ModChangedOnDisk(), "no skipped reload expected")
+}
+
+func TestProjectServiceSkipsReloadOfModWithPendingChanges(t *testing.T) {
+	modDir := t.TempDir()
+	givenTextsFile(t, filepath.Join(modDir, "cybstrng.res"), []byte{0x41, 0x00})
+	service := givenProjectService()
+	err := service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading")
+	service.ReloadChangedFilesNow()
+	service.Mod().Modify(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangDefault, ids.PaperTextsStart, 0, []byte{0x43, 0x00})
+	})
+
+	givenTextsFile(t, filepath.Join(modDir, "cybstrng.res"), []byte{0x42, 0x42, 0x00})
+	service.ReloadChangedFilesNow()
+
+	assert.Equal(t, []byte{0x43, 0x00}, service.Mod().ModifiedBlock(resource.LangDefault, ids.PaperTextsStart, 0),
+		"pending change should be kept")
+	assert.True(t, service.ModChangedOnDisk(), "skipped reload should be reported")
+
+	err = service.SaveMod()
+	require.Nil(t, err, "no error expected saving")
+	assert.False(t, service.ModChangedOnDisk(), "saving should overwrite the changes on disk")
+}
+
+func givenTextsFile(t *testing.T, filename string, data []byte) {
+	t.Helper()
+	var store resource.Store
+	err := store.Put(ids.PaperTextsStart, resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text, Compound: true},
+		Blocks:     resource.BlocksFrom([][]byte{data}),
+	})
+	require.Nil(t, err, "no error expected putting resource")
+	file, err := os.Create(filename)
+	require.Nil(t, err, "no error expected creating file")
+	defer func() { _ = file.Close() }()
+	err = lgres.Write(file, store)
+	require.Nil(t, err, "no error expected writing resources")
+}
+
+func firstBlockOf(t *testing.T, view resource.View) []byte {
+	t.Helper()
+	require.NotNil(t, view, "view expected")
+	reader, err := view.Block(0)
+	require.Nil(t, err, "block expected")
+	data, err := ioutil.ReadAll(reader)
+	require.Nil(t, err, "no error expected reading block")
+	return data
+}
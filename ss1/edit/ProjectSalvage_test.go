@@ -0,0 +1,71 @@
+package edit_test
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/serial"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectServiceRejectsDamagedModFilesByDefault(t *testing.T) {
+	modDir := givenModWithDamagedResourceFile(t)
+	service := givenProjectService()
+
+	err := service.TryLoadModFrom([]string{modDir})
+
+	assert.NotNil(t, err, "error expected")
+	assert.Empty(t, service.ModLoadProblems(), "no problems expected")
+}
+
+func TestProjectServiceSalvagesDamagedModFilesOnRequest(t *testing.T) {
+	modDir := givenModWithDamagedResourceFile(t)
+	service := givenProjectService()
+	service.SetSalvageDamagedFiles(true)
+
+	err := service.TryLoadModFrom([]string{modDir})
+
+	require.Nil(t, err, "no error expected")
+	assert.Len(t, service.ModLoadProblems(), 1, "salvaged inconsistency should be reported")
+	assert.True(t, service.CurrentSettings().SalvageDamagedFiles, "setting should be stored")
+	assert.Empty(t, service.Mod().ModifiedBlocks(resource.LangDefault, resource.ID(0x0869)), "damaged resource expected to be lost")
+	assert.NotEmpty(t, service.Mod().ModifiedBlocks(resource.LangDefault, resource.ID(0x003C)), "intact resource expected")
+
+	service.NewMod()
+	assert.Empty(t, service.ModLoadProblems(), "problems should be cleared with new mod")
+}
+
+// givenModWithDamagedResourceFile returns the path of a mod with a resource file, in which the block table
+// of resource 0x0869 is damaged.
+func givenModWithDamagedResourceFile(t *testing.T) string {
+	t.Helper()
+	var store resource.Store
+	err := store.Put(resource.ID(0x0869), resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text, Compound: true},
+		Blocks:     resource.BlocksFrom([][]byte{{0x41, 0x00}}),
+	})
+	require.Nil(t, err, "no error expected putting resource")
+	err = store.Put(resource.ID(0x003C), resource.Resource{
+		Properties: resource.Properties{ContentType: resource.Text},
+		Blocks:     resource.BlocksFrom([][]byte{{0x42, 0x00}}),
+	})
+	require.Nil(t, err, "no error expected putting resource")
+	target := serial.NewByteStore()
+	err = lgres.Write(target, store)
+	require.Nil(t, err, "no error expected writing resources")
+	data := target.Data()
+	dirOffset := binary.LittleEndian.Uint32(data[0x7C:])
+	firstResourceOffset := binary.LittleEndian.Uint32(data[dirOffset+2:])
+	binary.LittleEndian.PutUint16(data[firstResourceOffset:], 0xFFFF)
+
+	modDir := t.TempDir()
+	err = ioutil.WriteFile(filepath.Join(modDir, "cybstrng.res"), data, 0640)
+	require.Nil(t, err, "no error expected writing file")
+	return modDir
+}
@@ -1,31 +1,67 @@
 package edit
 
 import (
+	"archive/zip"
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/inkyblackness/hacked/ss1"
 	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/content/text"
 	"github.com/inkyblackness/hacked/ss1/content/texture"
 	"github.com/inkyblackness/hacked/ss1/edit/undoable/cmd"
 	"github.com/inkyblackness/hacked/ss1/resource"
 	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/resource/unpacked"
 	"github.com/inkyblackness/hacked/ss1/serial"
 	"github.com/inkyblackness/hacked/ss1/world"
 	"github.com/inkyblackness/hacked/ss1/world/ids"
 )
 
 const (
-	errNoResourcesFound     ss1.StringError = "no resources found"
 	errNoStorageLocationSet ss1.StringError = "no storage location set"
+	errModHasPendingChanges ss1.StringError = "mod has changes that are not saved"
+)
+
+// ModStorage describes how the resources of a mod are stored.
+type ModStorage string
+
+const (
+	// ModStorageResourceFiles stores the resources in binary resource files, as they are used by the engine.
+	ModStorageResourceFiles ModStorage = ""
+	// ModStorageUnpacked stores each resource file as a directory tree, with one file per block.
+	// This storage is meant for version control. The engine can not use it directly.
+	ModStorageUnpacked ModStorage = "unpacked"
 )
 
 // ProjectSettings describe the properties of a project.
 type ProjectSettings struct {
-	ModFiles []string
-	Manifest []ManifestEntrySettings
+	ModFiles   []string
+	ModStorage ModStorage `json:",omitempty"`
+	ParentMods []string   `json:",omitempty"`
+	Manifest   []ManifestEntrySettings
+	PathMode   ProjectPathMode `json:",omitempty"`
+	// BackupCount is the number of backups kept for each saved file of the mod. The default is used if not set.
+	BackupCount *int `json:",omitempty"`
+	// Codepage is the table file of the codepage for texts. The default codepage is used if not set.
+	Codepage string `json:",omitempty"`
+	// SalvageDamagedFiles loads the intact resources of damaged resource files, instead of rejecting such files.
+	SalvageDamagedFiles bool `json:",omitempty"`
+	// TextFit lists the fonts and display areas to check the texts with. The defaults are used for missing categories.
+	TextFit []TextFitSettings `json:",omitempty"`
+}
+
+// TextFitSettings describe the font and display area of one category of texts, see TextFitCategory.
+type TextFitSettings struct {
+	ID    resource.ID
+	Terse bool `json:",omitempty"`
+	Font  resource.ID
+	Area  TextArea
 }
 
 // ManifestEntrySettings describe the properties of one manifest entry in a project.
@@ -33,7 +69,10 @@ type ManifestEntrySettings struct {
 	Origin []string
 }
 
-const autosaveTimeoutSec = 5
+const (
+	autosaveTimeoutSec   = 5
+	fileCheckIntervalSec = 2
+)
 
 // SaveStatus describes the current change state.
 type SaveStatus struct {
@@ -55,21 +94,63 @@ func (status SaveStatus) ConfirmPendingSave() {
 	status.mod.ResetLastChangeTime()
 }
 
+// CodepageUser applies the codepage of a project to all texts.
+type CodepageUser interface {
+	// UseCodepage is called with the codepage to use from now on.
+	UseCodepage(cp text.Codepage)
+}
+
 // ProjectService handles the overall information about the active project.
 type ProjectService struct {
 	commander cmd.Registry
 
-	mod     *world.Mod
-	modPath string
+	mod         *world.Mod
+	modPath     string
+	modStorage  ModStorage
+	backupCount int
+	history     UndoHistory
+	// historyStorage refers to the undo history file of the mod path.
+	historyStorage undoHistoryStorage
+
+	codepage     text.Codepage
+	codepageFile string
+	codepageUser CodepageUser
+	// unresolvedCodepage is the table file of the settings that could not be loaded, in the form of the settings.
+	unresolvedCodepage string
+
+	textFitCategories []TextFitCategory
 
 	stateFilename string
+	pathMode      ProjectPathMode
+	pathVariables PathVariables
+	unresolved    []UnresolvedManifestEntry
+	// unresolvedParentMods and unresolvedMod are the mods of the settings that could not be loaded.
+	unresolvedParentMods []UnresolvedMod
+	unresolvedMod        *UnresolvedMod
+
+	salvageDamagedFiles bool
+	modProblems         []string
+	// modFiles are the paths of the files the mod was loaded from or saved to, which are watched for changes.
+	modFiles []string
+
+	watcher          *world.FileWatcher
+	lastFileCheck    time.Time
+	fileReloads      chan fileReload
+	fileCheckPending bool
+	// modGeneration is increased whenever the mod is loaded or saved, to detect outdated reloads.
+	modGeneration    int
+	modChangedOnDisk bool
 }
 
 // NewProjectService returns a new instance of a service for given mod.
 func NewProjectService(commander cmd.Registry, mod *world.Mod) *ProjectService {
 	return &ProjectService{
-		commander: commander,
-		mod:       mod,
+		commander:   commander,
+		mod:         mod,
+		backupCount: DefaultBackupCount,
+		codepage:    text.DefaultCodepage(),
+		watcher:     world.NewFileWatcher(BackupDirName, UndoHistoryFilename),
+		fileReloads: make(chan fileReload, 1),
 	}
 }
 
@@ -104,63 +185,417 @@ func (service *ProjectService) SetCurrentStateFilename(value string) {
 }
 
 // CurrentSettings returns the snapshot of the project.
+// Entries of the static world data that could not be loaded are kept at their index.
 func (service ProjectService) CurrentSettings() ProjectSettings {
 	manifest := service.mod.World()
-	settings := ProjectSettings{Manifest: make([]ManifestEntrySettings, manifest.EntryCount())}
-	for i := 0; i < manifest.EntryCount(); i++ {
-		entry, _ := manifest.Entry(i)
-		settings.Manifest[i].Origin = entry.Origin
+	total := manifest.EntryCount() + len(service.unresolved)
+	settings := ProjectSettings{Manifest: make([]ManifestEntrySettings, 0, total)}
+	unresolved := service.unresolved
+	loaded := 0
+	for len(settings.Manifest) < total {
+		position := len(settings.Manifest)
+		if (len(unresolved) > 0) && ((unresolved[0].Index <= position) || (loaded >= manifest.EntryCount())) {
+			settings.Manifest = append(settings.Manifest, ManifestEntrySettings{Origin: unresolved[0].Origin})
+			unresolved = unresolved[1:]
+			continue
+		}
+		entry, _ := manifest.Entry(loaded)
+		settings.Manifest = append(settings.Manifest, ManifestEntrySettings{Origin: service.originToSettings(entry.Origin...)})
+		loaded++
 	}
+	settings.PathMode = service.pathMode
 
 	settings.ModFiles = service.relativeToSettings(service.mod.AllAbsoluteFilenames(service.modPath)...)
+	if service.unresolvedMod != nil {
+		settings.ModFiles = service.unresolvedMod.Origin
+	}
+	settings.ModStorage = service.modStorage
+	backupCount := service.backupCount
+	settings.BackupCount = &backupCount
+	settings.ParentMods = service.parentModsToSettings()
+	if len(service.codepageFile) > 0 {
+		settings.Codepage = service.originToSettings(service.codepageFile)[0]
+	} else {
+		settings.Codepage = service.unresolvedCodepage
+	}
+	settings.SalvageDamagedFiles = service.salvageDamagedFiles
+	for _, category := range service.textFitCategories {
+		settings.TextFit = append(settings.TextFit, TextFitSettings{
+			ID:    category.ID,
+			Terse: category.Terse,
+			Font:  category.Font,
+			Area:  category.Area,
+		})
+	}
 
 	return settings
 }
 
+// parentModsToSettings returns the paths of the parent layers, with the unresolved parent mods kept at their index.
+func (service ProjectService) parentModsToSettings() []string {
+	layers := service.mod.ParentLayers()
+	unresolved := service.unresolvedParentMods
+	var paths []string
+	for (len(layers) > 0) || (len(unresolved) > 0) {
+		if (len(unresolved) > 0) && ((unresolved[0].Index <= len(paths)) || (len(layers) == 0)) {
+			paths = append(paths, unresolved[0].Origin...)
+			unresolved = unresolved[1:]
+			continue
+		}
+		paths = append(paths, service.relativeToSettings(layers[0].Name)...)
+		layers = layers[1:]
+	}
+	return paths
+}
+
+func (service *ProjectService) originToSettings(filenames ...string) []string {
+	if service.pathMode == ProjectPathsRelative {
+		return service.relativeToSettings(filenames...)
+	}
+	stored := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		stored = append(stored, service.pathVariables.Collapse(filename))
+	}
+	return stored
+}
+
 func (service *ProjectService) relativeToSettings(filenames ...string) []string {
 	loc := world.FileLocationFrom(service.stateFilename)
 	relatives := make([]string, 0, len(filenames))
 	for _, filename := range filenames {
-		relatives = append(relatives, world.FileLocationFrom(filename).NestedRelativeTo(loc.DirPath))
+		relative := service.pathVariables.Collapse(filename)
+		if relative == filename {
+			relative = filepath.ToSlash(world.FileLocationFrom(filename).NestedRelativeTo(loc.DirPath))
+		}
+		relatives = append(relatives, relative)
 	}
 	return relatives
 }
 
-func (service *ProjectService) absoluteFromSettings(filenames ...string) []string {
+func (service *ProjectService) resolveFromSettings(filenames ...string) ([]string, error) {
 	loc := world.FileLocationFrom(service.stateFilename)
 	absolutes := make([]string, 0, len(filenames))
+	var firstErr error
 	for _, filename := range filenames {
-		absolutes = append(absolutes, world.FileLocationFrom(filename).AbsolutePathFrom(loc.DirPath))
+		expanded, err := service.pathVariables.Expand(filename)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		absolutes = append(absolutes, world.FileLocationFrom(filepath.FromSlash(expanded)).AbsolutePathFrom(loc.DirPath))
 	}
-	return absolutes
+	return absolutes, firstErr
 }
 
 // RestoreProject sets internal data based on the given settings.
-func (service *ProjectService) RestoreProject(settings ProjectSettings, stateFilename string) {
+// If entries of the static world data, parent mods, or the mod can not be loaded, an UnresolvedPathsError lists them.
+// These are kept in the settings of the project, and can be loaded again with RetryUnresolvedPaths().
+// Should the codepage not be loadable, the default codepage is used and the error is returned.
+// The table file is kept in the settings of the project, see UnresolvedCodepage().
+func (service *ProjectService) RestoreProject(settings ProjectSettings, stateFilename string) error {
 	service.ResetProject()
 
 	service.stateFilename = stateFilename
+	service.pathMode = settings.PathMode
+	service.salvageDamagedFiles = settings.SalvageDamagedFiles
 
-	manifest := service.mod.World()
-	for _, entrySettings := range settings.Manifest {
-		entry, err := world.NewManifestEntryFrom(entrySettings.Origin)
+	var codepageErr error
+	if len(settings.Codepage) > 0 {
+		var codepageFiles []string
+		codepageFiles, codepageErr = service.resolveFromSettings(settings.Codepage)
+		if codepageErr == nil {
+			codepageErr = service.SetCodepageFile(codepageFiles[0])
+		}
+		if codepageErr != nil {
+			service.unresolvedCodepage = settings.Codepage
+		}
+	}
+
+	entries := make([]UnresolvedManifestEntry, 0, len(settings.Manifest))
+	for index, entrySettings := range settings.Manifest {
+		entries = append(entries, UnresolvedManifestEntry{Index: index, Origin: entrySettings.Origin})
+	}
+	unresolvedEntries := service.loadManifestEntries(entries)
+	var unresolvedMod *UnresolvedMod
+	if len(settings.ModFiles) > 0 {
+		unresolvedMod = service.loadModFromSettings(UnresolvedMod{Origin: settings.ModFiles})
+	}
+	parentMods := make([]UnresolvedMod, 0, len(settings.ParentMods))
+	for index, parentPath := range settings.ParentMods {
+		parentMods = append(parentMods, UnresolvedMod{Index: index, Origin: []string{parentPath}})
+	}
+	unresolvedParentMods := service.loadParentMods(parentMods)
+	err := unresolvedPathsError(unresolvedEntries, unresolvedParentMods, unresolvedMod)
+	if settings.ModStorage != ModStorageResourceFiles {
+		service.modStorage = settings.ModStorage
+	}
+	if settings.BackupCount != nil {
+		service.SetBackupCount(*settings.BackupCount)
+	}
+	service.restoreTextFitCategories(settings.TextFit)
+	if err == nil {
+		err = codepageErr
+	}
+	return err
+}
+
+// RetryUnresolvedEntries attempts to load the entries of the static world data again, which previously could not
+// be loaded. This is useful after the path variables were changed. Loaded entries are inserted into the manifest
+// at their original position. Entries that still can not be loaded are reported with an UnresolvedPathsError.
+func (service *ProjectService) RetryUnresolvedEntries() error {
+	entries := service.unresolved
+	service.unresolved = nil
+	return unresolvedPathsError(service.loadManifestEntries(entries), nil, nil)
+}
+
+// RetryUnresolvedPaths attempts to load everything of the project again that previously could not be loaded:
+// the entries of the static world data, see RetryUnresolvedEntries(), the parent mods, and the mod.
+// The mod is only loaded if the current one has no pending changes, as these would be lost otherwise.
+// Anything that still can not be loaded is reported with an UnresolvedPathsError.
+func (service *ProjectService) RetryUnresolvedPaths() error {
+	entries := service.unresolved
+	service.unresolved = nil
+	unresolvedEntries := service.loadManifestEntries(entries)
+	unresolvedMod := service.unresolvedMod
+	if unresolvedMod != nil {
+		if len(service.mod.ModifiedFilenames()) > 0 {
+			unresolvedMod.Err = errModHasPendingChanges
+		} else {
+			unresolvedMod = service.loadModFromSettings(*unresolvedMod)
+		}
+	}
+	parentMods := service.unresolvedParentMods
+	service.unresolvedParentMods = nil
+	unresolvedParentMods := service.loadParentMods(parentMods)
+	return unresolvedPathsError(unresolvedEntries, unresolvedParentMods, unresolvedMod)
+}
+
+// loadModFromSettings loads the mod from the paths as they are stored in the settings, keeping the parent layers.
+// If the mod can not be loaded, it is kept as unresolved one, and returned.
+func (service *ProjectService) loadModFromSettings(candidate UnresolvedMod) *UnresolvedMod {
+	names, err := service.resolveFromSettings(candidate.Origin...)
+	if err == nil {
+		err = service.loadModFrom(names)
+	}
+	if err != nil {
+		candidate.Err = err
+		service.unresolvedMod = &candidate
+		return &candidate
+	}
+	return nil
+}
+
+// loadParentMods loads the given parent mods into the parent layers, at the position their index describes.
+// Parent mods that can not be loaded are kept as unresolved ones, and returned.
+func (service *ProjectService) loadParentMods(parentMods []UnresolvedMod) []UnresolvedMod {
+	sorted := append([]UnresolvedMod{}, parentMods...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Index < sorted[b].Index })
+	var unresolved []UnresolvedMod
+	layers := append([]*world.ModLayer{}, service.mod.ParentLayers()...)
+	loaded := 0
+	for _, candidate := range sorted {
+		names, err := service.resolveFromSettings(candidate.Origin...)
+		var layer *world.ModLayer
+		if err == nil {
+			layer, err = loadModLayer(service.salvageDamagedFiles, names[0])
+		}
 		if err != nil {
+			candidate.Err = err
+			unresolved = append(unresolved, candidate)
+			service.addUnresolvedParentMod(candidate)
 			continue
 		}
-		err = manifest.InsertEntry(manifest.EntryCount(), entry)
+		position := service.parentModPositionOf(candidate.Index, len(layers))
+		layers = append(layers[:position], append([]*world.ModLayer{layer}, layers[position:]...)...)
+		loaded++
+	}
+	if loaded > 0 {
+		service.mod.SetParentLayers(layers)
+	}
+	return unresolved
+}
+
+// parentModPositionOf returns the position within the parent layers for a parent mod with given index,
+// which also counts the unresolved parent mods.
+func (service *ProjectService) parentModPositionOf(index int, layerCount int) int {
+	position := index
+	for _, parent := range service.unresolvedParentMods {
+		if parent.Index < index {
+			position--
+		}
+	}
+	if position > layerCount {
+		position = layerCount
+	}
+	if position < 0 {
+		position = 0
+	}
+	return position
+}
+
+func (service *ProjectService) addUnresolvedParentMod(parent UnresolvedMod) {
+	service.unresolvedParentMods = append(service.unresolvedParentMods, parent)
+	sort.SliceStable(service.unresolvedParentMods, func(a, b int) bool {
+		return service.unresolvedParentMods[a].Index < service.unresolvedParentMods[b].Index
+	})
+}
+
+// loadManifestEntries loads the given entries into the manifest, at the position their index describes.
+// Entries that can not be loaded are kept as unresolved ones, and returned.
+func (service *ProjectService) loadManifestEntries(entries []UnresolvedManifestEntry) []UnresolvedManifestEntry {
+	sorted := append([]UnresolvedManifestEntry{}, entries...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Index < sorted[b].Index })
+	var unresolved []UnresolvedManifestEntry
+	manifest := service.mod.World()
+	for _, candidate := range sorted {
+		entry, err := service.manifestEntryFrom(candidate.Origin)
+		if err == nil {
+			err = manifest.InsertEntry(service.manifestPositionOf(candidate.Index), entry)
+		}
 		if err != nil {
-			continue
+			candidate.Err = err
+			unresolved = append(unresolved, candidate)
+			service.addUnresolved(candidate)
+		}
+	}
+	return unresolved
+}
+
+// manifestPositionOf returns the position within the manifest for an entry with given index,
+// which also counts the unresolved entries.
+func (service *ProjectService) manifestPositionOf(index int) int {
+	position := index
+	for _, entry := range service.unresolved {
+		if entry.Index < index {
+			position--
+		}
+	}
+	if position > service.mod.World().EntryCount() {
+		position = service.mod.World().EntryCount()
+	}
+	if position < 0 {
+		position = 0
+	}
+	return position
+}
+
+func (service *ProjectService) addUnresolved(entry UnresolvedManifestEntry) {
+	service.unresolved = append(service.unresolved, entry)
+	sort.SliceStable(service.unresolved, func(a, b int) bool {
+		return service.unresolved[a].Index < service.unresolved[b].Index
+	})
+}
+
+func (service *ProjectService) manifestEntryFrom(origin []string) (*world.ManifestEntry, error) {
+	names, err := service.resolveFromSettings(origin...)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if _, err = os.Stat(name); err != nil {
+			return nil, err
 		}
 	}
+	return service.NewManifestEntryFrom(names)
+}
+
+// NewManifestEntryFrom creates a manifest entry from the given files.
+// Damaged resource files are salvaged if the project is set to do so, see SetSalvageDamagedFiles().
+func (service ProjectService) NewManifestEntryFrom(names []string) (*world.ManifestEntry, error) {
+	return newManifestEntryFrom(service.salvageDamagedFiles, names)
+}
+
+func newManifestEntryFrom(salvage bool, names []string) (*world.ManifestEntry, error) {
+	if salvage {
+		return world.SalvageManifestEntryFrom(names)
+	}
+	return world.NewManifestEntryFrom(names)
+}
 
-	_ = service.TryLoadModFrom(service.absoluteFromSettings(settings.ModFiles...))
+// SalvageDamagedFiles returns whether the intact resources of damaged resource files are loaded.
+func (service ProjectService) SalvageDamagedFiles() bool {
+	return service.salvageDamagedFiles
+}
+
+// SetSalvageDamagedFiles sets whether the intact resources of damaged resource files are loaded.
+// If not set, damaged files are rejected. Salvaged files are reported as problems, as saving them again
+// loses the damaged resources. The setting applies to files loaded from now on.
+func (service *ProjectService) SetSalvageDamagedFiles(value bool) {
+	service.salvageDamagedFiles = value
+}
+
+func loadFiles(salvage bool, allowZips bool, names []string) world.FileLoadResult {
+	if salvage {
+		return world.SalvageFiles(allowZips, names)
+	}
+	return world.LoadFiles(allowZips, names)
+}
+
+// ModLoadProblems describes the files of the mod that could not be loaded completely when it was loaded.
+// Saving the mod loses any resource that could not be loaded.
+func (service ProjectService) ModLoadProblems() []string {
+	return append([]string{}, service.modProblems...)
+}
+
+// UnresolvedManifestEntries returns all entries of the static world data that could not be loaded.
+func (service ProjectService) UnresolvedManifestEntries() []UnresolvedManifestEntry {
+	return append([]UnresolvedManifestEntry{}, service.unresolved...)
+}
+
+// UnresolvedParentMods returns all parent mods of the project that could not be loaded.
+func (service ProjectService) UnresolvedParentMods() []UnresolvedMod {
+	return append([]UnresolvedMod{}, service.unresolvedParentMods...)
+}
+
+// UnresolvedMod returns the mod of the project that could not be loaded, if any.
+// The paths of such a mod are kept in the settings until another mod is loaded, created, or saved.
+func (service ProjectService) UnresolvedMod() (UnresolvedMod, bool) {
+	if service.unresolvedMod == nil {
+		return UnresolvedMod{}, false
+	}
+	return *service.unresolvedMod, true
+}
+
+// PathMode returns how paths of the static world data are stored.
+func (service ProjectService) PathMode() ProjectPathMode {
+	return service.pathMode
+}
+
+// SetPathMode changes how paths of the static world data are stored.
+func (service *ProjectService) SetPathMode(value ProjectPathMode) {
+	service.pathMode = value
+}
+
+// PathVariables returns a copy of the currently defined path variables.
+func (service ProjectService) PathVariables() PathVariables {
+	vars := make(PathVariables)
+	for name, value := range service.pathVariables {
+		vars[name] = value
+	}
+	return vars
+}
+
+// SetPathVariables defines the variables for paths in the project.
+// The variables are meant to be specific to the machine, and are not part of the project settings.
+func (service *ProjectService) SetPathVariables(vars PathVariables) {
+	service.pathVariables = vars
 }
 
 // ResetProject clears the project and returns it to initial state.
 func (service *ProjectService) ResetProject() {
 	service.setActiveMod("", nil, nil, nil)
+	service.mod.SetParentLayers(nil)
 	service.mod.World().Reset()
 	service.stateFilename = ""
+	service.pathMode = ProjectPathsAbsolute
+	service.unresolved = nil
+	service.unresolvedParentMods = nil
+	service.backupCount = DefaultBackupCount
+	service.salvageDamagedFiles = false
+	service.textFitCategories = nil
+	_ = service.SetCodepageFile("")
 }
 
 // AddManifestEntry attempts to insert the given manifest entry at given index.
@@ -212,15 +647,33 @@ func (service ProjectService) Mod() *world.Mod {
 	return service.mod
 }
 
-// NewMod resets the mod to a new state.
+// NewMod resets the mod to a new state, without any parent layers.
 func (service *ProjectService) NewMod() {
 	service.setActiveMod("", nil, nil, nil)
+	service.mod.SetParentLayers(nil)
+	service.unresolvedParentMods = nil
+	service.modStorage = ModStorageResourceFiles
 }
 
 // TryLoadModFrom attempts to set the active mod from given filenames.
+// A single zip archive, as created by SaveModAsZip(), is loaded as well. Such a mod has no storage location.
+// The parent layers of the previous mod are removed if the mod could be loaded.
 func (service *ProjectService) TryLoadModFrom(names []string) error {
-	loaded := world.LoadFiles(false, names)
+	err := service.loadModFrom(names)
+	if err == nil {
+		service.mod.SetParentLayers(nil)
+		service.unresolvedParentMods = nil
+	}
+	return err
+}
+
+// loadModFrom sets the active mod from given filenames, keeping the current parent layers.
+func (service *ProjectService) loadModFrom(names []string) error {
+	fromZip := isZipArchive(names)
+	return service.takeLoadedMod(loadFiles(service.salvageDamagedFiles, fromZip, names), fromZip)
+}
 
+func (service *ProjectService) takeLoadedMod(loaded world.FileLoadResult, fromZip bool) error {
 	resourcesToTake := loaded.Resources
 	isSavegame := false
 	if (len(resourcesToTake) == 0) && (len(loaded.Savegames) == 1) {
@@ -228,19 +681,44 @@ func (service *ProjectService) TryLoadModFrom(names []string) error {
 		isSavegame = true
 	}
 	if len(resourcesToTake) == 0 {
-		return errNoResourcesFound
+		return loaded.NothingLoadedError()
 	}
-	var locs []*world.LocalizedResources
 	modPath := ""
-
 	for location := range resourcesToTake {
 		if (len(modPath) == 0) || (len(location.DirPath) < len(modPath)) {
 			modPath = location.DirPath
 		}
 	}
 
-	for location, viewer := range resourcesToTake {
-		lang := ids.LocalizeFilename(location.Name)
+	locs, storage := localizedResourcesOf(resourcesToTake, loaded.Unpacked, isSavegame)
+	if fromZip {
+		modPath = ""
+	}
+	service.setActiveMod(modPath, locs, loaded.ObjectProperties, loaded.TextureProperties)
+	service.modStorage = storage
+	service.modProblems = loaded.Problems()
+	if !fromZip {
+		service.modFiles = loaded.Paths()
+	}
+	if fromZip {
+		// Nothing of the mod is stored in a folder yet, saving must write all files.
+		service.mod.MarkAllFilesChanged()
+	}
+	service.restoreUndoHistory()
+	return nil
+}
+
+func localizedResourcesOf(resources map[world.FileLocation]resource.Viewer,
+	unpackedLanguages map[world.FileLocation]resource.Language, isSavegame bool) ([]*world.LocalizedResources, ModStorage) {
+	var locs []*world.LocalizedResources
+	storage := ModStorageResourceFiles
+	for location, viewer := range resources {
+		lang, isUnpacked := unpackedLanguages[location]
+		if isUnpacked {
+			storage = ModStorageUnpacked
+		} else {
+			lang = ids.LocalizeFilename(location.Name)
+		}
 		template := location.Name
 		if isSavegame {
 			template = string(ids.Archive)
@@ -260,14 +738,255 @@ func (service *ProjectService) TryLoadModFrom(names []string) error {
 		}
 		locs = append(locs, loc)
 	}
+	return locs, storage
+}
 
-	service.setActiveMod(modPath, locs, loaded.ObjectProperties, loaded.TextureProperties)
+// loadModLayer loads a mod from given path as a read-only layer.
+// The path is either the folder of the mod, or a zip archive.
+func loadModLayer(salvage bool, path string) (*world.ModLayer, error) {
+	names := []string{path}
+	loaded := loadFiles(salvage, isZipArchive(names), names)
+	if (len(loaded.Resources) == 0) && (len(loaded.ObjectProperties) == 0) && (len(loaded.TextureProperties) == 0) {
+		return nil, loaded.NothingLoadedError()
+	}
+	locs, _ := localizedResourcesOf(loaded.Resources, loaded.Unpacked, false)
+	return &world.ModLayer{
+		Name:               path,
+		LocalizedResources: locs,
+		ObjectProperties:   loaded.ObjectProperties,
+		TextureProperties:  loaded.TextureProperties,
+		Problems:           loaded.Problems(),
+		Files:              loaded.Paths(),
+	}, nil
+}
+
+// AddParentModFrom loads the mod from given path and places it as the top-most parent layer of the current mod.
+// The path is either the folder of the mod, or a zip archive.
+func (service *ProjectService) AddParentModFrom(path string) error {
+	layer, err := loadModLayer(service.salvageDamagedFiles, path)
+	if err != nil {
+		return err
+	}
+	layers := append([]*world.ModLayer{}, service.mod.ParentLayers()...)
+	service.mod.SetParentLayers(append(layers, layer))
 	return nil
 }
 
+// fileReload contains what was loaded again from files that changed on disk.
+type fileReload struct {
+	entries []reloadedEntry
+	layers  []reloadedLayer
+
+	modGeneration int
+	mod           *world.FileLoadResult
+}
+
+type reloadedEntry struct {
+	old *world.ManifestEntry
+	new *world.ManifestEntry
+}
+
+type reloadedLayer struct {
+	old *world.ModLayer
+	new *world.ModLayer
+}
+
+// ReloadChangedFiles reloads the manifest entries, parent mods, and the mod, of which files were changed on disk.
+// This function is meant to be called regularly from the main loop. It does not block: files are checked and
+// loaded in the background, at most every few seconds, and the loaded data is taken over with a later call.
+//
+// Manifest entries are replaced directly, without an undoable command, as the change happened outside of the editor.
+// The mod is only reloaded if it has no pending changes, as these would be lost otherwise. Such a skipped reload
+// is reported by ModChangedOnDisk().
+// Any reload notifies the changed resources, so that dependent caches are invalidated.
+func (service *ProjectService) ReloadChangedFiles() {
+	select {
+	case reload := <-service.fileReloads:
+		service.fileCheckPending = false
+		service.applyFileReload(reload)
+	default:
+	}
+	now := time.Now()
+	if service.fileCheckPending || (now.Sub(service.lastFileCheck) < time.Duration(fileCheckIntervalSec)*time.Second) {
+		return
+	}
+	service.lastFileCheck = now
+	service.fileCheckPending = true
+	check := service.fileCheck()
+	go func() { service.fileReloads <- check() }()
+}
+
+// ReloadChangedFilesNow checks the files immediately and waits for them to be reloaded, see ReloadChangedFiles().
+// This blocks the caller for as long as the files are checked and loaded.
+func (service *ProjectService) ReloadChangedFilesNow() {
+	if service.fileCheckPending {
+		service.fileCheckPending = false
+		service.applyFileReload(<-service.fileReloads)
+	}
+	service.lastFileCheck = time.Now()
+	service.applyFileReload(service.fileCheck()())
+}
+
+// ModChangedOnDisk returns true if the files of the mod were changed on disk, yet the mod was not reloaded
+// because of its pending changes. Saving the mod overwrites the changed files.
+func (service ProjectService) ModChangedOnDisk() bool {
+	return service.modChangedOnDisk
+}
+
+// fileCheck captures what to check, and returns a function that checks and loads the changed files.
+// The returned function only works on the captured state, so that it can run in the background.
+func (service ProjectService) fileCheck() func() fileReload {
+	salvage := service.salvageDamagedFiles
+	paths := service.watchedFiles()
+	manifest := service.mod.World()
+	var entries []*world.ManifestEntry
+	for at := 0; at < manifest.EntryCount(); at++ {
+		entry, _ := manifest.Entry(at)
+		entries = append(entries, entry)
+	}
+	layers := service.mod.ParentLayers()
+	modPath := ""
+	var modFiles []string
+	if service.ModHasStorageLocation() {
+		modPath = service.modPath
+		modFiles = service.modFiles
+	}
+	watcher := service.watcher
+	modGeneration := service.modGeneration
+
+	return func() fileReload {
+		reload := fileReload{modGeneration: modGeneration}
+		watcher.Watch(paths)
+		changed := make(map[string]bool)
+		for _, path := range watcher.Changed() {
+			changed[path] = true
+		}
+		for _, entry := range entries {
+			if !anyChanged(changed, entry.Files) {
+				continue
+			}
+			newEntry, err := newManifestEntryFrom(salvage, entry.Origin)
+			if err != nil {
+				continue
+			}
+			newEntry.ID = entry.ID
+			reload.entries = append(reload.entries, reloadedEntry{old: entry, new: newEntry})
+		}
+		for _, layer := range layers {
+			if !anyChanged(changed, layer.Files) {
+				continue
+			}
+			newLayer, err := loadModLayer(salvage, layer.Name)
+			if err != nil {
+				continue
+			}
+			reload.layers = append(reload.layers, reloadedLayer{old: layer, new: newLayer})
+		}
+		if (len(modPath) > 0) && anyChanged(changed, modFiles) {
+			loaded := loadFiles(salvage, false, []string{modPath})
+			reload.mod = &loaded
+		}
+		return reload
+	}
+}
+
+// applyFileReload takes over the reloaded data. Entries and layers that were removed in the meantime are ignored,
+// as is a reloaded mod that was loaded or saved in the meantime.
+func (service *ProjectService) applyFileReload(reload fileReload) {
+	manifest := service.mod.World()
+	for _, reloaded := range reload.entries {
+		for at := 0; at < manifest.EntryCount(); at++ {
+			if entry, _ := manifest.Entry(at); entry == reloaded.old {
+				_ = manifest.ReplaceEntry(at, reloaded.new)
+			}
+		}
+	}
+
+	layers := append([]*world.ModLayer{}, service.mod.ParentLayers()...)
+	layersChanged := false
+	for _, reloaded := range reload.layers {
+		for index, layer := range layers {
+			if layer == reloaded.old {
+				layers[index] = reloaded.new
+				layersChanged = true
+			}
+		}
+	}
+	if layersChanged {
+		service.mod.SetParentLayers(layers)
+	}
+
+	if (reload.mod == nil) || (reload.modGeneration != service.modGeneration) {
+		return
+	}
+	if len(service.mod.ModifiedFilenames()) > 0 {
+		service.modChangedOnDisk = true
+		return
+	}
+	_ = service.takeLoadedMod(*reload.mod, false)
+}
+
+func (service ProjectService) watchedFiles() []string {
+	var paths []string
+	manifest := service.mod.World()
+	for at := 0; at < manifest.EntryCount(); at++ {
+		entry, _ := manifest.Entry(at)
+		paths = append(paths, entry.Files...)
+	}
+	for _, layer := range service.mod.ParentLayers() {
+		paths = append(paths, layer.Files...)
+	}
+	if service.ModHasStorageLocation() {
+		paths = append(paths, service.modFiles...)
+	}
+	return paths
+}
+
+func mergedPaths(a []string, b []string) []string {
+	unique := make(map[string]bool)
+	var merged []string
+	for _, path := range append(append([]string{}, a...), b...) {
+		if !unique[path] {
+			unique[path] = true
+			merged = append(merged, path)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+func anyChanged(changed map[string]bool, paths []string) bool {
+	for _, path := range paths {
+		if changed[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveParentMod removes the parent layer at given index.
+func (service *ProjectService) RemoveParentMod(at int) {
+	current := service.mod.ParentLayers()
+	if (at < 0) || (at >= len(current)) {
+		return
+	}
+	layers := append([]*world.ModLayer{}, current[:at]...)
+	service.mod.SetParentLayers(append(layers, current[at+1:]...))
+}
+
+func isZipArchive(names []string) bool {
+	return (len(names) == 1) && strings.EqualFold(filepath.Ext(names[0]), ".zip")
+}
+
 func (service *ProjectService) setActiveMod(modPath string, resources []*world.LocalizedResources,
 	objectProperties object.PropertiesTable, textureProperties texture.PropertiesList) {
 	service.setModPath(modPath)
+	service.modProblems = nil
+	service.modFiles = nil
+	service.historyStorage = undoHistoryStorage{}
+	service.unresolvedMod = nil
+	service.modGeneration++
+	service.modChangedOnDisk = false
 	service.mod.Reset(resources, objectProperties, textureProperties)
 	// fix list resources for any "old" mod.
 	service.mod.FixListResources()
@@ -281,6 +1000,120 @@ func (service *ProjectService) ModifyModWith(modifier func(world.Modder) error)
 	return
 }
 
+// ModifyModRecorded runs a function with the intent to alter the current mod, and returns the resulting change.
+func (service *ProjectService) ModifyModRecorded(modifier func(world.Modder) error) (change world.ModChange, err error) {
+	change = service.mod.ModifyRecorded(func(modder world.Modder) {
+		err = modifier(modder)
+	})
+	return
+}
+
+// SetUndoHistory registers the history of changes that is stored with the mod when it is saved,
+// and restored when the mod is loaded again.
+func (service *ProjectService) SetUndoHistory(history UndoHistory) {
+	service.history = history
+}
+
+// saveUndoHistory stores the undo history next to the saved files of the mod, of which the given ones were written.
+// The history is a convenience, failing to store it does not fail saving the mod.
+func (service *ProjectService) saveUndoHistory(modPath string, written []string) {
+	if service.history == nil {
+		return
+	}
+	undo, redo := service.history.RecordedChanges()
+	_ = service.historyStorage.save(modPath, service.mod.AllAbsoluteFilenames(modPath), written, undo, redo)
+}
+
+// restoreUndoHistory restores the undo history of the loaded mod, should the files of the mod
+// still be in the state the history was stored for.
+func (service *ProjectService) restoreUndoHistory() {
+	if (service.history == nil) || !service.ModHasStorageLocation() {
+		return
+	}
+	undo, redo, valid := service.historyStorage.load(service.modPath, service.mod.AllAbsoluteFilenames(service.modPath))
+	if valid {
+		service.history.RestoreRecordedChanges(undo, redo)
+	}
+}
+
+// SetCodepageUser registers the one that applies the codepage of the project.
+// The user is informed whenever the codepage changes.
+func (service *ProjectService) SetCodepageUser(user CodepageUser) {
+	service.codepageUser = user
+}
+
+// Codepage returns the codepage of the project.
+func (service ProjectService) Codepage() text.Codepage {
+	return service.codepage
+}
+
+// CodepageFile returns the table file of the codepage of the project. Empty for the default codepage.
+func (service ProjectService) CodepageFile() string {
+	return service.codepageFile
+}
+
+// UnresolvedCodepage returns the table file of the project settings that could not be loaded, in the form
+// of the settings. It is kept in the settings until another codepage is set.
+func (service ProjectService) UnresolvedCodepage() string {
+	return service.unresolvedCodepage
+}
+
+// SetCodepageFile loads the codepage of the project from the given table file, see text.LoadCodepage().
+// An empty filename selects the default codepage. Should the table not be loadable, the current codepage is kept.
+func (service *ProjectService) SetCodepageFile(filename string) error {
+	cp := text.DefaultCodepage()
+	if len(filename) > 0 {
+		file, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		cp, err = text.LoadCodepage(file)
+		if err != nil {
+			return err
+		}
+	}
+	service.codepage = cp
+	service.codepageFile = filename
+	service.unresolvedCodepage = ""
+	if service.codepageUser != nil {
+		service.codepageUser.UseCodepage(cp)
+	}
+	return nil
+}
+
+// TextFitCategories returns the categories of texts to check, with the fonts and display areas of the project.
+// Categories that were not changed have the values of DefaultTextFitCategories().
+func (service ProjectService) TextFitCategories() []TextFitCategory {
+	if service.textFitCategories == nil {
+		return DefaultTextFitCategories()
+	}
+	return append([]TextFitCategory{}, service.textFitCategories...)
+}
+
+// SetTextFitCategories stores the fonts and display areas of the categories with the project.
+func (service *ProjectService) SetTextFitCategories(categories []TextFitCategory) {
+	service.textFitCategories = append([]TextFitCategory{}, categories...)
+}
+
+func (service *ProjectService) restoreTextFitCategories(settings []TextFitSettings) {
+	service.textFitCategories = nil
+	if len(settings) == 0 {
+		return
+	}
+	categories := DefaultTextFitCategories()
+	for _, stored := range settings {
+		for index := range categories {
+			category := &categories[index]
+			if (category.ID == stored.ID) && (category.Terse == stored.Terse) {
+				category.Font = stored.Font
+				category.Area = stored.Area
+			}
+		}
+	}
+	service.textFitCategories = categories
+}
+
 // ModHasStorageLocation returns whether the mod has a place to be stored.
 func (service ProjectService) ModHasStorageLocation() bool {
 	return len(service.modPath) > 0
@@ -295,6 +1128,65 @@ func (service *ProjectService) setModPath(value string) {
 	service.modPath = value
 }
 
+// BackupCount returns the number of backups that are kept for each saved file of the mod.
+func (service ProjectService) BackupCount() int {
+	return service.backupCount
+}
+
+// SetBackupCount changes the number of backups that are kept for each saved file of the mod.
+// Excess backups are removed the next time a file is saved.
+func (service *ProjectService) SetBackupCount(value int) {
+	if value < 0 {
+		value = 0
+	}
+	service.backupCount = value
+}
+
+// ModBackups returns the backups of all files of the mod, grouped by file, with the most recent first.
+func (service ProjectService) ModBackups() []FileBackup {
+	if !service.ModHasStorageLocation() {
+		return nil
+	}
+	var backups []FileBackup
+	for _, filename := range service.mod.AllAbsoluteFilenames(service.modPath) {
+		backups = append(backups, BackupsOf(filename)...)
+	}
+	return backups
+}
+
+// RestoreModBackup replaces a file of the mod with given backup, and loads the mod again.
+// The replaced state of the file becomes a backup itself, so restoring can be reverted.
+// This is not possible while the mod has changes that are not saved, as they would be lost.
+func (service *ProjectService) RestoreModBackup(backup FileBackup) error {
+	if !service.ModHasStorageLocation() {
+		return errNoStorageLocationSet
+	}
+	if len(service.mod.ModifiedFilenames()) > 0 {
+		return errModHasPendingChanges
+	}
+	err := restoreBackup(backup, service.backupCount)
+	if err != nil {
+		return err
+	}
+	service.watcher.Refresh(service.modFiles...)
+	return service.loadModFrom([]string{service.modPath})
+}
+
+// ModStorage returns how the resources of the mod are stored.
+func (service ProjectService) ModStorage() ModStorage {
+	return service.modStorage
+}
+
+// SetModStorage changes how the resources of the mod are stored.
+// Changing the storage marks all files of the mod to be saved again.
+func (service *ProjectService) SetModStorage(value ModStorage) {
+	if service.modStorage == value {
+		return
+	}
+	service.modStorage = value
+	service.mod.MarkAllFilesChanged()
+}
+
 // SaveMod will store the currently active mod in its current path.
 func (service *ProjectService) SaveMod() error {
 	if !service.ModHasStorageLocation() {
@@ -306,16 +1198,101 @@ func (service *ProjectService) SaveMod() error {
 // SaveModUnder will store the currently active mod in the given path.
 func (service *ProjectService) SaveModUnder(modPath string) error {
 	service.mod.FixListResources()
-	err := service.saveModResourcesTo(modPath)
+	written, err := service.saveModResourcesTo(modPath)
 	if err != nil {
 		return err
 	}
+	if modPath != service.modPath {
+		service.modFiles = nil
+		service.historyStorage = undoHistoryStorage{}
+	}
 	service.setModPath(modPath)
+	service.unresolvedMod = nil
+	service.modFiles = mergedPaths(service.modFiles, service.mod.AllAbsoluteFilenames(modPath))
+	service.saveUndoHistory(modPath, written)
+	service.watcher.Refresh(service.modFiles...)
+	service.modGeneration++
+	service.modChangedOnDisk = false
 	service.mod.MarkSave()
 	return nil
 }
 
-func (service *ProjectService) saveModResourcesTo(modPath string) error {
+// SaveModAsZip stores all files of the currently active mod in a single zip archive.
+// Resources are always stored as resource files, keeping their nested location relative to the mod path.
+// The archive can be loaded again with TryLoadModFrom(). The storage location of the mod remains unchanged.
+// The undo history is not part of the archive, it is only stored by SaveModUnder().
+func (service *ProjectService) SaveModAsZip(filename string) error {
+	service.mod.FixListResources()
+	buffer := bytes.NewBuffer(nil)
+	archive := zip.NewWriter(buffer)
+	for _, loc := range service.mod.ModifiedResources() {
+		data := serial.NewByteStore()
+		err := lgres.Write(data, loc.Store)
+		if err != nil {
+			return err
+		}
+		err = addZipEntry(archive, zipEntryName(loc.File, service.modPath), data.Data())
+		if err != nil {
+			return err
+		}
+	}
+	if service.mod.HasModifiableTextureProperties() {
+		err := addCodableZipEntry(archive, world.TexturePropertiesFilename, service.mod.TextureProperties())
+		if err != nil {
+			return err
+		}
+	}
+	if service.mod.HasModifiableObjectProperties() {
+		err := addCodableZipEntry(archive, world.ObjectPropertiesFilename, service.mod.ObjectProperties())
+		if err != nil {
+			return err
+		}
+	}
+	err := archive.Close()
+	if err != nil {
+		return err
+	}
+	return writeFileSafely(filename, service.backupCount, func(writer io.WriteSeeker) error {
+		_, err := writer.Write(buffer.Bytes())
+		return err
+	})
+}
+
+// zipEntryName returns the slash-separated path of the location, relative to the mod path.
+// Locations outside of the mod path are stored at the root.
+func zipEntryName(loc world.FileLocation, modPath string) string {
+	nested := filepath.Join(loc.DirPath, loc.Name)
+	if len(modPath) > 0 {
+		nested = loc.NestedRelativeTo(modPath)
+	}
+	if filepath.IsAbs(nested) {
+		return loc.Name
+	}
+	return filepath.ToSlash(nested)
+}
+
+func addZipEntry(archive *zip.Writer, name string, data []byte) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(data)
+	return err
+}
+
+func addCodableZipEntry(archive *zip.Writer, name string, codable serial.Codable) error {
+	buffer := bytes.NewBuffer(nil)
+	encoder := serial.NewEncoder(buffer)
+	codable.Code(encoder)
+	err := encoder.FirstError()
+	if err != nil {
+		return err
+	}
+	return addZipEntry(archive, name, buffer.Bytes())
+}
+
+// saveModResourcesTo writes the modified files of the mod, and returns their absolute paths.
+func (service *ProjectService) saveModResourcesTo(modPath string) ([]string, error) {
 	localized := service.mod.ModifiedResources()
 	filenamesToSave := service.mod.ModifiedFilenames()
 
@@ -328,52 +1305,77 @@ func (service *ProjectService) saveModResourcesTo(modPath string) error {
 		return false
 	}
 
+	var written []string
 	for _, loc := range localized {
 		if shallBeSaved(loc.File.Name) {
-			err := saveResourcesTo(loc.Store, loc.File.AbsolutePathFrom(modPath))
+			var err error
+			absFilename := loc.File.AbsolutePathFrom(modPath)
+			if service.modStorage == ModStorageUnpacked {
+				err = saveUnpackedResourcesTo(loc.Store, loc.Language, absFilename, service.backupCount)
+			} else {
+				err = saveResourcesTo(loc.Store, absFilename, service.backupCount)
+			}
 			if err != nil {
-				return err
+				return nil, err
 			}
+			written = append(written, absFilename)
 		}
 	}
 
 	if shallBeSaved(world.TexturePropertiesFilename) {
-		err := saveTexturePropertiesTo(service.mod.TextureProperties(), filepath.Join(modPath, world.TexturePropertiesFilename))
+		absFilename := filepath.Join(modPath, world.TexturePropertiesFilename)
+		err := saveTexturePropertiesTo(service.mod.TextureProperties(), absFilename, service.backupCount)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		written = append(written, absFilename)
 	}
 	if shallBeSaved(world.ObjectPropertiesFilename) {
-		err := saveObjectPropertiesTo(service.mod.ObjectProperties(), filepath.Join(modPath, world.ObjectPropertiesFilename))
+		absFilename := filepath.Join(modPath, world.ObjectPropertiesFilename)
+		err := saveObjectPropertiesTo(service.mod.ObjectProperties(), absFilename, service.backupCount)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		written = append(written, absFilename)
 	}
 
-	return nil
+	return written, nil
 }
 
-func saveResourcesTo(viewer resource.Viewer, absFilename string) error {
-	file, err := os.Create(absFilename)
-	if err != nil {
-		return err
+// saveResourcesTo writes the resources as a resource file. This also replaces a previously unpacked form of the file.
+func saveResourcesTo(viewer resource.Viewer, absFilename string, backupCount int) error {
+	return writeFileSafely(absFilename, backupCount, func(writer io.WriteSeeker) error {
+		return lgres.Write(writer, viewer)
+	})
+}
+
+// saveUnpackedResourcesTo writes the resources in unpacked form. This also replaces a previously packed form
+// of the file, which is kept as a backup.
+func saveUnpackedResourcesTo(viewer resource.Viewer, lang resource.Language, absDirname string, backupCount int) error {
+	if info, err := os.Stat(absDirname); (err == nil) && !info.IsDir() {
+		err = os.MkdirAll(filepath.Join(filepath.Dir(absDirname), BackupDirName), 0750)
+		if err == nil {
+			err = rotateBackups(absDirname, backupCount)
+		}
+		if err == nil {
+			err = os.Remove(absDirname)
+		}
+		if err != nil {
+			return err
+		}
 	}
-	defer func() {
-		_ = file.Close() // nolint: gas
-	}()
-	err = lgres.Write(file, viewer)
-	return err
+	return unpacked.WriteLocalized(absDirname, lang, viewer)
 }
 
-func saveTexturePropertiesTo(list texture.PropertiesList, absFilename string) error {
-	return saveCodableTo(list, absFilename)
+func saveTexturePropertiesTo(list texture.PropertiesList, absFilename string, backupCount int) error {
+	return saveCodableTo(list, absFilename, backupCount)
 }
 
-func saveObjectPropertiesTo(list object.PropertiesTable, absFilename string) error {
-	return saveCodableTo(list, absFilename)
+func saveObjectPropertiesTo(list object.PropertiesTable, absFilename string, backupCount int) error {
+	return saveCodableTo(list, absFilename, backupCount)
 }
 
-func saveCodableTo(codable serial.Codable, absFilename string) error {
+func saveCodableTo(codable serial.Codable, absFilename string, backupCount int) error {
 	buffer := bytes.NewBuffer(nil)
 	encoder := serial.NewEncoder(buffer)
 	codable.Code(encoder)
@@ -381,14 +1383,8 @@ func saveCodableTo(codable serial.Codable, absFilename string) error {
 	if err != nil {
 		return err
 	}
-
-	file, err := os.Create(absFilename)
-	if err != nil {
+	return writeFileSafely(absFilename, backupCount, func(writer io.WriteSeeker) error {
+		_, err := writer.Write(buffer.Bytes())
 		return err
-	}
-	defer func() {
-		_ = file.Close() // nolint: gas
-	}()
-	_, err = file.Write(buffer.Bytes())
-	return err
+	})
 }
@@ -0,0 +1,257 @@
+package edit
+
+import (
+	"io/ioutil"
+
+	"github.com/inkyblackness/hacked/ss1/content/font"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// TextArea describes the space in which a text is displayed.
+type TextArea struct {
+	// Width is the number of pixels available per line.
+	Width int
+	// Lines is the number of lines available. Zero for texts that can be scrolled or paged.
+	Lines int
+}
+
+// TextFitCategory describes a kind of texts that are displayed in the same area, with the same font.
+type TextFitCategory struct {
+	Title string
+	// ID identifies the texts: either a list of single-block texts, or the first resource
+	// of pages or electronic messages.
+	ID resource.ID
+	// Terse selects the terse text of electronic messages, instead of the verbose one.
+	Terse bool
+	// Font identifies the font the texts are displayed with. Categories without a font are not checked.
+	Font resource.ID
+	Area TextArea
+}
+
+// TextFitIssue describes a text that does not fit into its area.
+type TextFitIssue struct {
+	Category string
+	// Key identifies the text. Its index is the one of the text within its category.
+	Key  resource.Key
+	Text string
+	// Width is the width of the widest line, in pixels.
+	Width int
+	// Lines is the number of lines of the wrapped text.
+	Lines int
+}
+
+// textFitDisplay describes how the game displays a kind of texts, on a screen of 320x200 pixels.
+type textFitDisplay struct {
+	font resource.ID
+	area TextArea
+}
+
+var (
+	// messageLineDisplay is the line at the top of the main view.
+	messageLineDisplay = textFitDisplay{font: ids.CitadelFont, area: TextArea{Width: 230, Lines: 1}}
+	// mfdLineDisplay is a single line in a multi-function display.
+	mfdLineDisplay = textFitDisplay{font: ids.TinyTechFont, area: TextArea{Width: 74, Lines: 1}}
+	// mfdDisplay is the text area of a multi-function display.
+	mfdDisplay = textFitDisplay{font: ids.TinyTechFont, area: TextArea{Width: 74, Lines: 6}}
+	// fullViewDisplay is the main view, used for texts that are paged.
+	fullViewDisplay = textFitDisplay{font: ids.CitadelFont, area: TextArea{Width: 230, Lines: 0}}
+)
+
+var textFitDisplays = map[resource.ID]textFitDisplay{
+	ids.TrapMessageTexts:     messageLineDisplay,
+	ids.WordTexts:            mfdLineDisplay,
+	ids.LogCategoryTexts:     mfdLineDisplay,
+	ids.VariousMessageTexts:  messageLineDisplay,
+	ids.ScreenMessageTexts:   {font: ids.TinyTechFont, area: TextArea{Width: 64, Lines: 6}},
+	ids.InfoNodeMessageTexts: mfdDisplay,
+	ids.AccessCardNameTexts:  mfdLineDisplay,
+	ids.DataletMessageTexts:  mfdDisplay,
+	ids.PaperTextsStart:      fullViewDisplay,
+	ids.PanelNameTexts:       mfdLineDisplay,
+}
+
+var electronicMessageTypes = []struct {
+	id    resource.ID
+	title string
+}{
+	{id: ids.MailsStart, title: "Mails"},
+	{id: ids.LogsStart, title: "Logs"},
+	{id: ids.FragmentsStart, title: "Fragments"},
+}
+
+// isElectronicMessage returns true if the given ID is one of the resources of electronic messages.
+func isElectronicMessage(id resource.ID) bool {
+	info, known := ids.Info(id)
+	if !known {
+		return false
+	}
+	for _, messageType := range electronicMessageTypes {
+		if messageType.id == info.StartID {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTextFitCategories returns the categories of all known texts and electronic messages,
+// with the fonts and display areas the game uses for them.
+func DefaultTextFitCategories() []TextFitCategory {
+	var categories []TextFitCategory
+	for _, info := range KnownTexts() {
+		display := textFitDisplays[info.ID]
+		categories = append(categories, TextFitCategory{
+			Title: info.Title,
+			ID:    info.ID,
+			Font:  display.font,
+			Area:  display.area,
+		})
+	}
+	for _, messageType := range electronicMessageTypes {
+		categories = append(categories,
+			TextFitCategory{
+				Title: messageType.title + " (verbose)",
+				ID:    messageType.id,
+				Font:  fullViewDisplay.font,
+				Area:  fullViewDisplay.area,
+			},
+			TextFitCategory{
+				Title: messageType.title + " (terse)",
+				ID:    messageType.id,
+				Terse: true,
+				Font:  mfdDisplay.font,
+				Area:  mfdDisplay.area,
+			})
+	}
+	return categories
+}
+
+// CheckTextFit measures all texts of the given categories, in all languages, and returns those that
+// do not fit into their area. Issues are ordered by language, then by category.
+func CheckTextFit(localizer resource.Localizer, cp text.Codepage, categories []TextFitCategory) []TextFitIssue {
+	var issues []TextFitIssue
+	for _, lang := range resource.Languages() {
+		selector := localizer.LocalizedResources(lang)
+		for _, category := range categories {
+			fnt := textFitFont(selector, category.Font)
+			if fnt == nil {
+				continue
+			}
+			forEachTextOf(selector, cp, category, func(index int, characters []byte) {
+				lines := fnt.Wrap(characters, category.Area.Width)
+				widest := 0
+				for _, line := range lines {
+					if width := fnt.Width(line); width > widest {
+						widest = width
+					}
+				}
+				if (widest > category.Area.Width) || ((category.Area.Lines > 0) && (len(lines) > category.Area.Lines)) {
+					issues = append(issues, TextFitIssue{
+						Category: category.Title,
+						Key:      resource.KeyOf(category.ID, lang, index),
+						Text:     cp.Decode(characters),
+						Width:    widest,
+						Lines:    len(lines),
+					})
+				}
+			})
+		}
+	}
+	return issues
+}
+
+func textFitFont(selector resource.Selector, id resource.ID) *font.Font {
+	if id == 0 {
+		return nil
+	}
+	view, err := selector.Select(id)
+	if err != nil {
+		return nil
+	}
+	data, err := blockData(view, 0)
+	if err != nil {
+		return nil
+	}
+	fnt, err := font.Decode(data)
+	if err != nil {
+		return nil
+	}
+	return fnt
+}
+
+func forEachTextOf(selector resource.Selector, cp text.Codepage, category TextFitCategory,
+	handler func(index int, characters []byte)) {
+	info, known := ids.Info(category.ID)
+	if !known {
+		return
+	}
+	if info.List {
+		view, err := selector.Select(category.ID)
+		if (err != nil) || (view.ContentType() != resource.Text) {
+			return
+		}
+		for index := 0; index < view.BlockCount(); index++ {
+			data, err := blockData(view, index)
+			if data = trimTerminators(data); (err == nil) && (len(data) > 0) {
+				handler(index, data)
+			}
+		}
+		return
+	}
+	isMessage := isElectronicMessage(category.ID)
+	for index := 0; index < info.MaxCount; index++ {
+		view, err := selector.Select(category.ID.Plus(index))
+		if (err != nil) || (view.ContentType() != resource.Text) {
+			continue
+		}
+		var data []byte
+		if isMessage {
+			data = messageData(view, cp, category.Terse)
+		} else {
+			data = pageData(view)
+		}
+		if len(data) > 0 {
+			handler(index, data)
+		}
+	}
+}
+
+func messageData(view resource.View, cp text.Codepage, terse bool) []byte {
+	message, err := text.DecodeElectronicMessage(cp, view)
+	if err != nil {
+		return nil
+	}
+	value := message.VerboseText
+	if terse {
+		value = message.TerseText
+	}
+	return trimTerminators(cp.Encode(value))
+}
+
+func pageData(view resource.View) []byte {
+	var data []byte
+	for index := 0; index < view.BlockCount(); index++ {
+		block, err := blockData(view, index)
+		if err != nil {
+			return nil
+		}
+		data = append(data, trimTerminators(block)...)
+	}
+	return data
+}
+
+func blockData(view resource.View, index int) ([]byte, error) {
+	reader, err := view.Block(index)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(reader)
+}
+
+func trimTerminators(data []byte) []byte {
+	for (len(data) > 0) && (data[len(data)-1] == 0x00) {
+		data = data[:len(data)-1]
+	}
+	return data
+}
@@ -0,0 +1,121 @@
+package edit_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/font"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const textFitFontID = resource.ID(0x0600)
+
+func TestCheckTextFitListsOverflowingTextsPerLanguage(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := givenModWithFixedWidthFont(t)
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.PanelNameTexts,
+			[][]byte{cp.Encode("fits"), cp.Encode("too wide")})
+		modder.SetResourceBlocks(resource.LangGerman, ids.PanelNameTexts,
+			[][]byte{cp.Encode("zu breit")})
+	})
+	categories := []edit.TextFitCategory{
+		{Title: "Panel Names", ID: ids.PanelNameTexts, Font: textFitFontID, Area: edit.TextArea{Width: 5, Lines: 1}},
+	}
+
+	issues := edit.CheckTextFit(mod, cp, categories)
+
+	assert.Equal(t, []edit.TextFitIssue{
+		{Category: "Panel Names", Key: resource.KeyOf(ids.PanelNameTexts, resource.LangDefault, 1),
+			Text: "too wide", Width: 4, Lines: 2},
+		{Category: "Panel Names", Key: resource.KeyOf(ids.PanelNameTexts, resource.LangGerman, 0),
+			Text: "zu breit", Width: 5, Lines: 2},
+	}, issues)
+}
+
+func TestCheckTextFitMeasuresElectronicMessages(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := givenModWithFixedWidthFont(t)
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.MailsStart.Plus(2), [][]byte{
+			cp.Encode(""), cp.Encode("title"), cp.Encode("sender"), cp.Encode("subject"),
+			cp.Encode("one two three four"), cp.Encode(""),
+			cp.Encode("short"), cp.Encode(""),
+		})
+	})
+	categories := []edit.TextFitCategory{
+		{Title: "verbose", ID: ids.MailsStart, Font: textFitFontID, Area: edit.TextArea{Width: 10, Lines: 1}},
+		{Title: "terse", ID: ids.MailsStart, Terse: true, Font: textFitFontID, Area: edit.TextArea{Width: 10, Lines: 1}},
+	}
+
+	issues := edit.CheckTextFit(mod, cp, categories)
+
+	require.Equal(t, 1, len(issues), "one issue expected")
+	assert.Equal(t, resource.KeyOf(ids.MailsStart, resource.LangDefault, 2), issues[0].Key, "key mismatch")
+	assert.Equal(t, 2, issues[0].Lines, "lines mismatch")
+}
+
+func TestCheckTextFitSkipsCategoriesWithoutFont(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := givenModWithFixedWidthFont(t)
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.PanelNameTexts, [][]byte{cp.Encode("too wide")})
+	})
+	categories := []edit.TextFitCategory{
+		{Title: "Panel Names", ID: ids.PanelNameTexts, Area: edit.TextArea{Width: 1, Lines: 1}},
+	}
+
+	issues := edit.CheckTextFit(mod, cp, categories)
+
+	assert.Empty(t, issues)
+}
+
+func TestDefaultTextFitCategoriesHaveFontsAndAreas(t *testing.T) {
+	categories := edit.DefaultTextFitCategories()
+
+	require.NotEmpty(t, categories, "categories expected")
+	for _, category := range categories {
+		assert.NotEqual(t, resource.ID(0), category.Font, "font expected for "+category.Title)
+		assert.True(t, category.Area.Width > 0, "width expected for "+category.Title)
+	}
+}
+
+func TestProjectServiceStoresTextFitCategoriesWithProject(t *testing.T) {
+	service := givenProjectService()
+	categories := service.TextFitCategories()
+	categories[0].Font = textFitFontID
+	categories[0].Area = edit.TextArea{Width: 100, Lines: 2}
+	service.SetTextFitCategories(categories)
+	settings := service.CurrentSettings()
+
+	restored := givenProjectService()
+	err := restored.RestoreProject(settings, "")
+
+	require.Nil(t, err, "no error expected restoring")
+	assert.Equal(t, categories, restored.TextFitCategories(), "categories should be restored")
+
+	restored.ResetProject()
+	assert.Equal(t, edit.DefaultTextFitCategories(), restored.TextFitCategories(), "defaults expected after reset")
+}
+
+// givenModWithFixedWidthFont returns a mod with a font in which all printable characters have a width of one.
+func givenModWithFixedWidthFont(t *testing.T) *world.Mod {
+	t.Helper()
+	glyphs := make([]font.Glyph, 0x60)
+	for index := range glyphs {
+		glyphs[index] = font.Glyph{Width: 1, Pixels: []byte{0}}
+	}
+	data, err := font.Encode(font.Font{Type: font.TypeMonochrome, FirstCharacter: 0x20, Height: 1, Glyphs: glyphs})
+	require.Nil(t, err, "no error expected encoding font")
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangAny, textFitFontID, 0, data)
+	})
+	return mod
+}
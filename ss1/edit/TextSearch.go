@@ -0,0 +1,109 @@
+package edit
+
+import (
+	"regexp"
+
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/edit/media"
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// TextSearchResult describes a text that matches a search.
+type TextSearchResult struct {
+	// Key identifies the text as the editor views do: the ID of the first resource of its kind,
+	// and the index within. For subtitles, the ID is the one of the movie and the index the one of the subtitle.
+	Key resource.Key
+	// Field names the part of an electronic message the text is in: title, sender, subject, verbose, or terse.
+	// Subtitles have the field "subtitle", other texts none.
+	Field string
+	// Title describes the text.
+	Title string
+	Text  string
+	// Matches is the number of occurrences of the search pattern in the text.
+	Matches int
+}
+
+// IsSubtitle returns true if the result is the subtitle of a movie.
+func (result TextSearchResult) IsSubtitle() bool {
+	return result.Field == subtitlePart
+}
+
+// TextSearchService searches and replaces in all texts of the game, in all languages.
+// This covers the lists of texts, such as object names, the texts spanning a resource, electronic messages,
+// and the subtitles of movies.
+type TextSearchService struct {
+	texts gameTexts
+}
+
+// NewTextSearchService returns a new instance.
+func NewTextSearchService(cp text.Codepage, resources TextResources,
+	textViewer media.TextViewerService, textSetter media.TextSetterService,
+	messageCache *text.ElectronicMessageCache, movieService MovieService) TextSearchService {
+	return TextSearchService{
+		texts: gameTexts{
+			cp:        cp,
+			resources: resources,
+
+			textViewer:   textViewer,
+			textSetter:   textSetter,
+			messageCache: messageCache,
+			movieService: movieService,
+		},
+	}
+}
+
+// Search returns all texts that match the given pattern. Results are ordered by language.
+func (service TextSearchService) Search(pattern *regexp.Regexp) []TextSearchResult {
+	var results []TextSearchResult
+	for _, lang := range resource.Languages() {
+		service.texts.forEach(lang, func(unit textUnit, title string, value string) {
+			matches := pattern.FindAllStringIndex(value, -1)
+			if len(matches) == 0 {
+				return
+			}
+			results = append(results, TextSearchResult{
+				Key:     unit.viewKey(lang),
+				Field:   unit.field,
+				Title:   title,
+				Text:    value,
+				Matches: len(matches),
+			})
+		})
+	}
+	return results
+}
+
+// replacements returns the new values of all texts that change when replacing the pattern, per language.
+// The replacement may refer to submatches, as in regexp.Regexp.ReplaceAllString().
+func (service TextSearchService) replacements(pattern *regexp.Regexp, replacement string) map[resource.Language]map[textUnit]string {
+	result := make(map[resource.Language]map[textUnit]string)
+	for _, lang := range resource.Languages() {
+		values := make(map[textUnit]string)
+		service.texts.forEach(lang, func(unit textUnit, _ string, value string) {
+			if newValue := pattern.ReplaceAllString(value, replacement); newValue != value {
+				values[unit] = newValue
+			}
+		})
+		if len(values) > 0 {
+			result[lang] = values
+		}
+	}
+	return result
+}
+
+// Replace replaces all occurrences of the pattern in all texts, and returns the number of changed texts.
+func (service TextSearchService) Replace(setter media.TextBlockSetter, pattern *regexp.Regexp, replacement string) int {
+	values := service.replacements(pattern, replacement)
+	service.texts.set(setter, values)
+	changed := 0
+	for _, units := range values {
+		changed += len(units)
+	}
+	return changed
+}
+
+// ReplaceRestoreFunc creates a snapshot of all resources that Replace() would change, and returns a function
+// to restore them.
+func (service TextSearchService) ReplaceRestoreFunc(pattern *regexp.Regexp, replacement string) func(setter media.TextBlockSetter) {
+	return service.texts.restoreFunc(service.replacements(pattern, replacement))
+}
@@ -0,0 +1,115 @@
+package edit_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/inkyblackness/hacked/ss1/content/movie"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/edit/media"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextSearchFindsTextsInAllLanguages(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.ObjectLongNames,
+			[][]byte{cp.Encode("assault rifle"), cp.Encode("Rebecca's rifle")})
+		modder.SetResourceBlocks(resource.LangGerman, ids.PaperTextsStart.Plus(2), [][]byte{cp.Encode("Rebecca")})
+		message := text.EmptyElectronicMessage()
+		message.Sender = "Rebecca"
+		message.VerboseText = "Rebecca Lansing, Rebecca"
+		modder.SetResourceBlocks(resource.LangDefault, ids.LogsStart.Plus(3), message.Encode(cp))
+	})
+
+	results := aTextSearchService(mod, cp).Search(regexp.MustCompile(`Rebecca\b`))
+
+	assert.Equal(t, []edit.TextSearchResult{
+		{Key: resource.KeyOf(ids.LogsStart, resource.LangDefault, 3), Field: "sender",
+			Title: "Message " + ids.LogsStart.Plus(3).String() + ", sender", Text: "Rebecca", Matches: 1},
+		{Key: resource.KeyOf(ids.LogsStart, resource.LangDefault, 3), Field: "verbose",
+			Title: "Message " + ids.LogsStart.Plus(3).String() + ", verbose", Text: "Rebecca Lansing, Rebecca", Matches: 2},
+		{Key: resource.KeyOf(ids.ObjectLongNames, resource.LangDefault, 1),
+			Title: "Texts " + ids.ObjectLongNames.String() + " 1", Text: "Rebecca's rifle", Matches: 1},
+		{Key: resource.KeyOf(ids.PaperTextsStart, resource.LangGerman, 2),
+			Title: "Papers 2", Text: "Rebecca", Matches: 1},
+	}, results)
+}
+
+func TestTextSearchReplaceChangesAllMatchingTexts(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.PanelNameTexts,
+			[][]byte{cp.Encode("door 1234"), cp.Encode("no code")})
+		modder.SetResourceBlocks(resource.LangFrench, ids.PanelNameTexts, [][]byte{cp.Encode("porte 1234")})
+	})
+	pattern := regexp.MustCompile(`(\d)234`)
+
+	var changed int
+	mod.Modify(func(modder world.Modder) {
+		changed = aTextSearchService(mod, cp).Replace(modder, pattern, "${1}999")
+	})
+
+	assert.Equal(t, 2, changed, "two texts should be changed")
+	assert.Equal(t, cp.Encode("door 1999"), mod.ModifiedBlock(resource.LangDefault, ids.PanelNameTexts, 0))
+	assert.Equal(t, cp.Encode("no code"), mod.ModifiedBlock(resource.LangDefault, ids.PanelNameTexts, 1))
+	assert.Equal(t, cp.Encode("porte 1999"), mod.ModifiedBlock(resource.LangFrench, ids.PanelNameTexts, 0))
+}
+
+func TestTextSearchReplaceChangesSubtitlesOfAllLanguagesInSharedMovie(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	movieKey := resource.KeyOf(ids.MovieDeath, resource.LangDefault, 0)
+	movies := aMovieService(mod, cp)
+	mod.Modify(func(modder world.Modder) {
+		movies.SetSubtitlesPerLanguage(modder, movieKey, map[resource.Language]movie.SubtitleList{
+			resource.LangDefault: {Entries: []movie.Subtitle{{Timestamp: time.Second, Text: "Shodan lives"}}},
+			resource.LangGerman:  {Entries: []movie.Subtitle{{Timestamp: time.Second, Text: "Shodan lebt"}}},
+			resource.LangFrench:  {Entries: []movie.Subtitle{{Timestamp: time.Second, Text: "Shodan vit"}}},
+		})
+	})
+
+	var changed int
+	mod.Modify(func(modder world.Modder) {
+		changed = aTextSearchService(mod, cp).Replace(modder, regexp.MustCompile(`Shodan`), "SHODAN")
+	})
+
+	assert.Equal(t, 3, changed, "three subtitles should be changed")
+	assert.Equal(t, "SHODAN lives", movies.Subtitles(movieKey, resource.LangDefault).Entries[0].Text)
+	assert.Equal(t, "SHODAN lebt", movies.Subtitles(movieKey, resource.LangGerman).Entries[0].Text)
+	assert.Equal(t, "SHODAN vit", movies.Subtitles(movieKey, resource.LangFrench).Entries[0].Text)
+}
+
+func TestTextSearchReplaceRestoreFuncRestoresPreviousState(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.PanelNameTexts, [][]byte{cp.Encode("old")})
+	})
+	pattern := regexp.MustCompile(`old`)
+	service := aTextSearchService(mod, cp)
+	restore := service.ReplaceRestoreFunc(pattern, "new")
+	mod.Modify(func(modder world.Modder) { service.Replace(modder, pattern, "new") })
+	require.Equal(t, cp.Encode("new"), mod.ModifiedBlock(resource.LangDefault, ids.PanelNameTexts, 0))
+
+	mod.Modify(func(modder world.Modder) { restore(modder) })
+
+	assert.Equal(t, cp.Encode("old"), mod.ModifiedBlock(resource.LangDefault, ids.PanelNameTexts, 0))
+}
+
+func aTextSearchService(mod *world.Mod, cp text.Codepage) edit.TextSearchService {
+	return edit.NewTextSearchService(cp, mod,
+		media.NewTextViewerService(text.NewLineCache(cp, mod), text.NewPageCache(cp, mod), mod),
+		media.NewTextSetterService(cp),
+		text.NewElectronicMessageCache(cp, mod),
+		aMovieService(mod, cp))
+}
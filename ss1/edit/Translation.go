@@ -0,0 +1,123 @@
+package edit
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/content/text/po"
+	"github.com/inkyblackness/hacked/ss1/edit/media"
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// TranslationReport summarizes which entries of a catalog are stored as translation.
+// The entries are identified by their context.
+type TranslationReport struct {
+	// Applied entries have a translation that is stored.
+	Applied []string
+	// Fuzzy entries are skipped, as their translation needs to be reviewed.
+	Fuzzy []string
+	// Untranslated entries are skipped, as they have no translation.
+	Untranslated []string
+	// Unknown entries are skipped, as their context does not identify a text.
+	Unknown []string
+}
+
+var languageCodes = map[resource.Language]string{
+	resource.LangDefault: "en",
+	resource.LangFrench:  "fr",
+	resource.LangGerman:  "de",
+}
+
+// TranslationService exchanges all texts of the game with catalogs in PO format.
+// This covers the lists of texts, the texts spanning a resource, electronic messages, and the subtitles of movies.
+// The context of each entry identifies the text, such as "0869:12" for the block 12 of the list 0x0869.
+type TranslationService struct {
+	texts gameTexts
+}
+
+// NewTranslationService returns a new instance.
+func NewTranslationService(cp text.Codepage, resources TextResources,
+	textViewer media.TextViewerService, textSetter media.TextSetterService,
+	messageCache *text.ElectronicMessageCache, movieService MovieService) TranslationService {
+	return TranslationService{
+		texts: gameTexts{
+			cp:        cp,
+			resources: resources,
+
+			textViewer:   textViewer,
+			textSetter:   textSetter,
+			messageCache: messageCache,
+			movieService: movieService,
+		},
+	}
+}
+
+// Catalog returns a catalog of all texts in the source language, with their translations in the target language.
+// For a template, the translations are left empty.
+func (service TranslationService) Catalog(source, target resource.Language, template bool) po.Catalog {
+	catalog := po.Catalog{
+		Header: []po.HeaderField{
+			{Name: "Project-Id-Version", Value: "System Shock"},
+			{Name: "MIME-Version", Value: "1.0"},
+			{Name: "Content-Type", Value: "text/plain; charset=UTF-8"},
+			{Name: "Content-Transfer-Encoding", Value: "8bit"},
+			{Name: "X-Source-Language", Value: languageCodes[source]},
+		},
+	}
+	if !template {
+		catalog.Header = append(catalog.Header, po.HeaderField{Name: "Language", Value: languageCodes[target]})
+	}
+	service.texts.forEach(source, func(unit textUnit, title string, value string) {
+		entry := po.Entry{
+			ExtractedComments: []string{title},
+			Context:           unit.String(),
+			ID:                value,
+		}
+		if !template {
+			entry.Translation = service.texts.text(unit, target)
+		}
+		catalog.Entries = append(catalog.Entries, entry)
+	})
+	return catalog
+}
+
+// Report classifies the entries of given catalog, as Translate() would apply them.
+func (service TranslationService) Report(catalog po.Catalog) TranslationReport {
+	var report TranslationReport
+	for _, entry := range catalog.Entries {
+		_, known := textUnitFrom(entry.Context)
+		switch {
+		case !known:
+			report.Unknown = append(report.Unknown, entry.Context)
+		case entry.IsFuzzy():
+			report.Fuzzy = append(report.Fuzzy, entry.Context)
+		case !entry.IsTranslated():
+			report.Untranslated = append(report.Untranslated, entry.Context)
+		default:
+			report.Applied = append(report.Applied, entry.Context)
+		}
+	}
+	return report
+}
+
+// appliedUnits returns the translations of the entries that Report() lists as applied.
+func (service TranslationService) appliedUnits(catalog po.Catalog) map[textUnit]string {
+	units := make(map[textUnit]string)
+	for _, entry := range catalog.Entries {
+		unit, known := textUnitFrom(entry.Context)
+		if known && !entry.IsFuzzy() && entry.IsTranslated() {
+			units[unit] = entry.Translation
+		}
+	}
+	return units
+}
+
+// Translate stores the translations of the catalog in the target language.
+// Entries that are fuzzy, untranslated, or unknown are skipped, see Report().
+func (service TranslationService) Translate(setter media.TextBlockSetter, catalog po.Catalog, target resource.Language) {
+	service.texts.set(setter, map[resource.Language]map[textUnit]string{target: service.appliedUnits(catalog)})
+}
+
+// RestoreFunc creates a snapshot of all resources that Translate() would change, and returns a function
+// to restore them.
+func (service TranslationService) RestoreFunc(catalog po.Catalog, target resource.Language) func(setter media.TextBlockSetter) {
+	return service.texts.restoreFunc(map[resource.Language]map[textUnit]string{target: service.appliedUnits(catalog)})
+}
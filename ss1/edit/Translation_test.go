@@ -0,0 +1,164 @@
+package edit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/inkyblackness/hacked/ss1/content/movie"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/content/text/po"
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/edit/media"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslationCatalogContainsTextsOfSourceWithTargetTranslation(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.PanelNameTexts,
+			[][]byte{cp.Encode("first"), cp.Encode(""), cp.Encode("third")})
+		modder.SetResourceBlocks(resource.LangGerman, ids.PanelNameTexts,
+			[][]byte{cp.Encode("erstes")})
+		modder.SetResourceBlocks(resource.LangDefault, ids.PaperTextsStart.Plus(1),
+			[][]byte{cp.Encode("page "), cp.Encode("text")})
+	})
+
+	catalog := aTranslationService(mod, cp).Catalog(resource.LangDefault, resource.LangGerman, false)
+
+	assert.Equal(t, "de", catalog.HeaderValue("Language"), "target language expected")
+	assert.Equal(t, []po.Entry{
+		{ExtractedComments: []string{"Papers 1"}, Context: ids.PaperTextsStart.Plus(1).String(), ID: "page text"},
+		{ExtractedComments: []string{"Panel Names 0"}, Context: ids.PanelNameTexts.String() + ":0",
+			ID: "first", Translation: "erstes"},
+		{ExtractedComments: []string{"Panel Names 2"}, Context: ids.PanelNameTexts.String() + ":2", ID: "third"},
+	}, catalog.Entries)
+}
+
+func TestTranslationTemplateHasNoTranslations(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, ids.PanelNameTexts, [][]byte{cp.Encode("first")})
+		modder.SetResourceBlocks(resource.LangFrench, ids.PanelNameTexts, [][]byte{cp.Encode("premier")})
+	})
+
+	catalog := aTranslationService(mod, cp).Catalog(resource.LangDefault, resource.LangFrench, true)
+
+	require.Equal(t, 1, len(catalog.Entries), "one entry expected")
+	assert.Equal(t, "first", catalog.Entries[0].ID, "source text expected")
+	assert.Equal(t, "", catalog.Entries[0].Translation, "no translation expected")
+}
+
+func TestTranslationReportClassifiesEntries(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	catalog := po.Catalog{Entries: []po.Entry{
+		{Context: ids.PanelNameTexts.String() + ":0", ID: "a", Translation: "b"},
+		{Context: ids.PanelNameTexts.String() + ":1", ID: "a", Translation: "b", Flags: []string{po.FlagFuzzy}},
+		{Context: ids.PanelNameTexts.String() + ":2", ID: "a"},
+		{Context: "FFFF:0", ID: "a", Translation: "b"},
+		{Context: ids.MailsStart.String() + ":unknown", ID: "a", Translation: "b"},
+	}}
+
+	report := aTranslationService(mod, cp).Report(catalog)
+
+	assert.Equal(t, edit.TranslationReport{
+		Applied:      []string{ids.PanelNameTexts.String() + ":0"},
+		Fuzzy:        []string{ids.PanelNameTexts.String() + ":1"},
+		Untranslated: []string{ids.PanelNameTexts.String() + ":2"},
+		Unknown:      []string{"FFFF:0", ids.MailsStart.String() + ":unknown"},
+	}, report)
+}
+
+func TestTranslateStoresTranslatedTexts(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		message := text.EmptyElectronicMessage()
+		message.Title = "title"
+		message.VerboseText = "verbose"
+		message.NextMessage = 5
+		modder.SetResourceBlocks(resource.LangDefault, ids.MailsStart, message.Encode(cp))
+	})
+	catalog := po.Catalog{Entries: []po.Entry{
+		{Context: ids.PanelNameTexts.String() + ":1", ID: "name", Translation: "Name"},
+		{Context: ids.PaperTextsStart.String(), ID: "page", Translation: "Seite"},
+		{Context: ids.MailsStart.String() + ":title", ID: "title", Translation: "Titel"},
+		{Context: ids.MailsStart.String() + ":verbose", ID: "verbose", Translation: "ausführlich",
+			Flags: []string{po.FlagFuzzy}},
+	}}
+
+	mod.Modify(func(modder world.Modder) {
+		aTranslationService(mod, cp).Translate(modder, catalog, resource.LangGerman)
+	})
+
+	assert.Equal(t, cp.Encode("Name"), mod.ModifiedBlock(resource.LangGerman, ids.PanelNameTexts, 1), "line mismatch")
+	page, err := text.NewPageCache(cp, mod).Text(resource.KeyOf(ids.PaperTextsStart, resource.LangGerman, 0))
+	require.Nil(t, err, "no error expected reading page")
+	assert.Equal(t, "Seite", page, "page mismatch")
+	message, err := text.NewElectronicMessageCache(cp, mod).Message(resource.KeyOf(ids.MailsStart, resource.LangGerman, 0))
+	require.Nil(t, err, "no error expected decoding message")
+	assert.Equal(t, "Titel", message.Title, "title should be translated")
+	assert.Equal(t, "", message.VerboseText, "fuzzy verbose text should be skipped")
+	assert.Equal(t, 5, message.NextMessage, "properties should be taken from default language")
+}
+
+func TestTranslateAddsSubtitlesWithTimestampOfOtherLanguage(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		aMovieService(mod, cp).SetSubtitles(modder, resource.KeyOf(ids.MovieIntro, resource.LangDefault, 0),
+			resource.LangDefault, movie.SubtitleList{Entries: []movie.Subtitle{
+				{Timestamp: 1 * time.Second, Text: "one"},
+				{Timestamp: 2 * time.Second, Text: "two"},
+			}})
+	})
+	catalog := po.Catalog{Entries: []po.Entry{
+		{Context: ids.MovieIntro.String() + ":subtitle:1", ID: "two", Translation: "zwei"},
+	}}
+
+	mod.Modify(func(modder world.Modder) {
+		aTranslationService(mod, cp).Translate(modder, catalog, resource.LangGerman)
+	})
+
+	list := aMovieService(mod, cp).Subtitles(resource.KeyOf(ids.MovieIntro, resource.LangGerman, 0), resource.LangGerman)
+	assert.Equal(t, []movie.Subtitle{{Timestamp: 2 * time.Second, Text: "zwei"}}, list.Entries)
+}
+
+func TestTranslationRestoreFuncRestoresPreviousState(t *testing.T) {
+	cp := text.DefaultCodepage()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangFrench, ids.PanelNameTexts, [][]byte{cp.Encode("nom")})
+	})
+	catalog := po.Catalog{Entries: []po.Entry{
+		{Context: ids.PanelNameTexts.String() + ":0", ID: "name", Translation: "autre"},
+		{Context: ids.PaperTextsStart.String(), ID: "page", Translation: "page"},
+	}}
+	service := aTranslationService(mod, cp)
+	restore := service.RestoreFunc(catalog, resource.LangFrench)
+	mod.Modify(func(modder world.Modder) { service.Translate(modder, catalog, resource.LangFrench) })
+
+	mod.Modify(func(modder world.Modder) { restore(modder) })
+
+	assert.Equal(t, [][]byte{cp.Encode("nom")}, mod.ModifiedBlocks(resource.LangFrench, ids.PanelNameTexts), "list mismatch")
+	assert.Empty(t, mod.ModifiedBlocks(resource.LangFrench, ids.PaperTextsStart), "page should be removed")
+}
+
+func aTranslationService(mod *world.Mod, cp text.Codepage) edit.TranslationService {
+	return edit.NewTranslationService(cp, mod,
+		media.NewTextViewerService(text.NewLineCache(cp, mod), text.NewPageCache(cp, mod), mod),
+		media.NewTextSetterService(cp),
+		text.NewElectronicMessageCache(cp, mod),
+		aMovieService(mod, cp))
+}
+
+func aMovieService(mod *world.Mod, cp text.Codepage) edit.MovieService {
+	return edit.NewMovieService(cp, media.NewMovieViewerService(movie.NewCache(cp, mod), mod), media.NewMovieSetterService(cp))
+}
@@ -0,0 +1,180 @@
+package edit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/inkyblackness/hacked/ss1/world"
+)
+
+const (
+	// UndoHistoryFilename is the name of the file that keeps the undo history of a mod.
+	// It is placed in the folder of the mod and written each time the mod is saved.
+	UndoHistoryFilename = ".hacked-history.json"
+	// UndoHistoryLimit is the maximum number of changes per list that are kept in the file.
+	UndoHistoryLimit = 100
+
+	undoHistoryVersion = 1
+)
+
+// UndoHistory provides the changes of the active mod that can be undone and redone.
+type UndoHistory interface {
+	// RecordedChanges returns the changes, each list starting with the most recent.
+	RecordedChanges() (undo []world.ModChange, redo []world.ModChange)
+	// RestoreRecordedChanges replaces the history with given changes, each list starting with the most recent.
+	RestoreRecordedChanges(undo []world.ModChange, redo []world.ModChange)
+}
+
+// undoHistoryFile is the serialized form of an undo history.
+type undoHistoryFile struct {
+	Version int
+	// Files maps the paths of all files of the mod, relative to the folder of the mod, to the hash of their content.
+	// The history is only valid as long as the files on disk match.
+	Files map[string]string
+	Undo  []world.ModChange `json:",omitempty"`
+	Redo  []world.ModChange `json:",omitempty"`
+}
+
+// undoHistoryStorage keeps what is known about the undo history file of a mod folder, so that saving the history
+// only hashes the files that were written since, and only writes the history file if its content changed.
+type undoHistoryStorage struct {
+	// hashes map the absolute paths of the files of the mod to the hash of their content, as last read or written.
+	hashes map[string]string
+	// content is the serialized history, as last read or written.
+	content []byte
+}
+
+// save stores the undo history in the folder of the mod. The files that were written since the last call
+// are hashed again, the hashes of the other files are reused.
+func (storage *undoHistoryStorage) save(modPath string, filenames []string, written []string,
+	undo, redo []world.ModChange) error {
+	for _, filename := range written {
+		delete(storage.hashes, filename)
+	}
+	hashes, err := storage.hashesOfFiles(modPath, filenames)
+	if err != nil {
+		return err
+	}
+	history := undoHistoryFile{
+		Version: undoHistoryVersion,
+		Files:   hashes,
+		Undo:    limitedChanges(undo),
+		Redo:    limitedChanges(redo),
+	}
+	buffer := bytes.NewBuffer(nil)
+	err = json.NewEncoder(buffer).Encode(&history)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(buffer.Bytes(), storage.content) {
+		return nil
+	}
+	err = writeFileSafely(filepath.Join(modPath, UndoHistoryFilename), 0, func(writer io.WriteSeeker) error {
+		_, writeErr := writer.Write(buffer.Bytes())
+		return writeErr
+	})
+	if err != nil {
+		return err
+	}
+	storage.content = buffer.Bytes()
+	return nil
+}
+
+// load reads the undo history from the folder of the mod.
+// The returned bool is false if there is no valid history for the given files.
+func (storage *undoHistoryStorage) load(modPath string, filenames []string) (undo, redo []world.ModChange, valid bool) {
+	data, err := ioutil.ReadFile(filepath.Join(modPath, UndoHistoryFilename))
+	if err != nil {
+		return nil, nil, false
+	}
+	var history undoHistoryFile
+	err = json.Unmarshal(data, &history)
+	if (err != nil) || (history.Version != undoHistoryVersion) {
+		return nil, nil, false
+	}
+	hashes, err := storage.hashesOfFiles(modPath, filenames)
+	if (err != nil) || !sameHashes(hashes, history.Files) {
+		return nil, nil, false
+	}
+	storage.content = data
+	return history.Undo, history.Redo, true
+}
+
+func limitedChanges(changes []world.ModChange) []world.ModChange {
+	if len(changes) > UndoHistoryLimit {
+		return changes[:UndoHistoryLimit]
+	}
+	return changes
+}
+
+func sameHashes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, hash := range a {
+		if other, known := b[name]; !known || (other != hash) {
+			return false
+		}
+	}
+	return true
+}
+
+// hashesOfFiles returns the hashes of the content of given files, keyed by their path relative to the mod.
+// Directories, as used for unpacked resources, are hashed with all the files they contain.
+// Files hashed before are not read again.
+func (storage *undoHistoryStorage) hashesOfFiles(modPath string, filenames []string) (map[string]string, error) {
+	if storage.hashes == nil {
+		storage.hashes = make(map[string]string)
+	}
+	hashes := make(map[string]string)
+	for _, filename := range filenames {
+		relative, err := filepath.Rel(modPath, filename)
+		if err != nil {
+			return nil, err
+		}
+		hash, known := storage.hashes[filename]
+		if !known {
+			hash, err = hashOfPath(filename)
+			if err != nil {
+				return nil, err
+			}
+			storage.hashes[filename] = hash
+		}
+		hashes[filepath.ToSlash(relative)] = hash
+	}
+	return hashes, nil
+}
+
+func hashOfPath(path string) (string, error) {
+	hasher := sha256.New()
+	err := filepath.Walk(path, func(current string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if (current != path) && (info.Name() == BackupDirName) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, _ := filepath.Rel(path, current)
+		_, _ = hasher.Write([]byte(filepath.ToSlash(relative) + "\x00"))
+		file, err := os.Open(current)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		_, err = io.Copy(hasher, file)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
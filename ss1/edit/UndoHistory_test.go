@@ -0,0 +1,108 @@
+package edit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/edit/undoable/cmd"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type setTextCommand byte
+
+func (value setTextCommand) Do(modder world.Modder) error {
+	modder.SetResourceBlock(resource.LangDefault, resource.ID(0x0869), 0, []byte{byte(value), 0x00})
+	return nil
+}
+
+func (value setTextCommand) Undo(modder world.Modder) error {
+	modder.SetResourceBlock(resource.LangDefault, resource.ID(0x0869), 0, []byte{0x00})
+	return nil
+}
+
+func TestProjectServiceRestoresUndoHistoryOfSavedMod(t *testing.T) {
+	modDir := t.TempDir()
+	givenTextListFile(t, filepath.Join(modDir, "cybstrng.res"))
+	stack := givenModWithRecordedTexts(t, modDir, 0x01, 0x02)
+	undo, redo := stack.RecordedChanges()
+
+	restoredStack := new(cmd.Stack)
+	service := givenProjectService()
+	service.SetUndoHistory(restoredStack)
+	err := service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading mod")
+
+	restoredUndo, restoredRedo := restoredStack.RecordedChanges()
+	assert.Equal(t, undo, restoredUndo, "undo list should be restored")
+	assert.Equal(t, redo, restoredRedo, "redo list should be restored")
+	err = service.ModifyModWith(restoredStack.Undo)
+	require.Nil(t, err, "no error expected undoing")
+	assert.Equal(t, []byte{0x01, 0x00}, textBlock(t, service), "undo should revert to previous state")
+	err = service.ModifyModWith(restoredStack.Undo)
+	require.Nil(t, err, "no error expected undoing")
+	assert.Equal(t, []byte{0x00}, textBlock(t, service), "undo should revert to original state")
+}
+
+func TestProjectServiceDiscardsUndoHistoryOfChangedFiles(t *testing.T) {
+	modDir := t.TempDir()
+	filename := filepath.Join(modDir, "cybstrng.res")
+	givenTextListFile(t, filename)
+	givenModWithRecordedTexts(t, modDir, 0x01)
+	givenTextListFile(t, filename)
+
+	restoredStack := new(cmd.Stack)
+	service := givenProjectService()
+	service.SetUndoHistory(restoredStack)
+	err := service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading mod")
+
+	assert.False(t, restoredStack.CanUndo(), "history should be discarded")
+}
+
+func TestProjectServiceKeepsUnchangedUndoHistoryFile(t *testing.T) {
+	modDir := t.TempDir()
+	givenTextListFile(t, filepath.Join(modDir, "cybstrng.res"))
+	stack := givenModWithRecordedTexts(t, modDir, 0x01)
+	historyFilename := filepath.Join(modDir, edit.UndoHistoryFilename)
+	pastTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	err := os.Chtimes(historyFilename, pastTime, pastTime)
+	require.Nil(t, err, "no error expected changing file time")
+
+	service := givenProjectService()
+	service.SetUndoHistory(stack)
+	err = service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading mod")
+	err = service.SaveMod()
+	require.Nil(t, err, "no error expected saving")
+
+	info, err := os.Stat(historyFilename)
+	require.Nil(t, err, "history file expected")
+	assert.True(t, info.ModTime().Equal(pastTime), "history file should not be written again")
+}
+
+func givenModWithRecordedTexts(t *testing.T, modDir string, values ...byte) *cmd.Stack {
+	t.Helper()
+	stack := new(cmd.Stack)
+	service := givenProjectService()
+	service.SetUndoHistory(stack)
+	err := service.TryLoadModFrom([]string{modDir})
+	require.Nil(t, err, "no error expected loading mod")
+	for _, value := range values {
+		command := &cmd.RecordedCommand{Command: setTextCommand(value)}
+		command.Change, err = service.ModifyModRecorded(func(modder world.Modder) error {
+			return stack.Perform(command, modder)
+		})
+		require.Nil(t, err, "no error expected modifying")
+	}
+	err = service.SaveMod()
+	require.Nil(t, err, "no error expected saving")
+	require.FileExists(t, filepath.Join(modDir, edit.UndoHistoryFilename), "history should be saved")
+	return stack
+}
@@ -0,0 +1,39 @@
+package undoable
+
+import (
+	"regexp"
+
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/edit/undoable/cmd"
+	"github.com/inkyblackness/hacked/ss1/world"
+)
+
+// TextSearchService provides search and replace in all texts with undo capability.
+type TextSearchService struct {
+	wrapped   edit.TextSearchService
+	commander cmd.Commander
+}
+
+// NewTextSearchService returns a new instance of a service.
+func NewTextSearchService(wrapped edit.TextSearchService, commander cmd.Commander) TextSearchService {
+	return TextSearchService{
+		wrapped:   wrapped,
+		commander: commander,
+	}
+}
+
+// Search returns all texts that match the given pattern.
+func (service TextSearchService) Search(pattern *regexp.Regexp) []edit.TextSearchResult {
+	return service.wrapped.Search(pattern)
+}
+
+// RequestReplace queues the change to replace all occurrences of the pattern in all texts, as one command.
+func (service TextSearchService) RequestReplace(pattern *regexp.Regexp, replacement string, restoreFunc func()) {
+	reverse := service.wrapped.ReplaceRestoreFunc(pattern, replacement)
+	c := command{
+		forward: func(modder world.Modder) { service.wrapped.Replace(modder, pattern, replacement) },
+		reverse: func(modder world.Modder) { reverse(modder) },
+		restore: restoreFunc,
+	}
+	service.commander.Queue(c)
+}
@@ -0,0 +1,47 @@
+package undoable
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/text/po"
+	"github.com/inkyblackness/hacked/ss1/edit"
+	"github.com/inkyblackness/hacked/ss1/edit/undoable/cmd"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+)
+
+// TranslationService provides the exchange of translations with undo capability.
+type TranslationService struct {
+	wrapped   edit.TranslationService
+	commander cmd.Commander
+}
+
+// NewTranslationService returns a new instance of a service.
+func NewTranslationService(wrapped edit.TranslationService, commander cmd.Commander) TranslationService {
+	return TranslationService{
+		wrapped:   wrapped,
+		commander: commander,
+	}
+}
+
+// Catalog returns a catalog of all texts in the source language, with their translations in the target language.
+func (service TranslationService) Catalog(source, target resource.Language, template bool) po.Catalog {
+	return service.wrapped.Catalog(source, target, template)
+}
+
+// RequestTranslate queues the change to store all translations of the catalog in the target language,
+// as one command. The returned report lists which entries are applied and which are skipped.
+// No command is queued if no entry is applied.
+func (service TranslationService) RequestTranslate(catalog po.Catalog, target resource.Language,
+	restoreFunc func()) edit.TranslationReport {
+	report := service.wrapped.Report(catalog)
+	if len(report.Applied) == 0 {
+		return report
+	}
+	reverse := service.wrapped.RestoreFunc(catalog, target)
+	c := command{
+		forward: func(modder world.Modder) { service.wrapped.Translate(modder, catalog, target) },
+		reverse: func(modder world.Modder) { reverse(modder) },
+		restore: restoreFunc,
+	}
+	service.commander.Queue(c)
+	return report
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "github.com/inkyblackness/hacked/ss1/world"
+
+// RecordedCommand combines a command with the change it caused in the mod.
+// The change is the persistable form of the command: If the command is not available,
+// for example because the command was restored from a previous session, the change is applied instead.
+type RecordedCommand struct {
+	Command Command
+	Change  world.ModChange
+}
+
+// Do performs the command, or applies the change if there is no command.
+func (recorded *RecordedCommand) Do(modder world.Modder) error {
+	if recorded.Command != nil {
+		return recorded.Command.Do(modder)
+	}
+	recorded.Change.Do(modder)
+	return nil
+}
+
+// Undo reverts the command, or the change if there is no command.
+func (recorded *RecordedCommand) Undo(modder world.Modder) error {
+	if recorded.Command != nil {
+		return recorded.Command.Undo(modder)
+	}
+	recorded.Change.Undo(modder)
+	return nil
+}
@@ -88,6 +88,45 @@ func (stack *Stack) Redo(modder world.Modder) error {
 	return nil
 }
 
+// RecordedChanges returns the changes of the commands on the stack, each list starting with the most recent.
+// Only recorded commands are considered: A list ends before the first command that is not recorded,
+// as neither it, nor the commands beyond it, can be reproduced. Commands without change are skipped.
+func (stack *Stack) RecordedChanges() (undo []world.ModChange, redo []world.ModChange) {
+	return recordedChangesOf(stack.undoList), recordedChangesOf(stack.redoList)
+}
+
+func recordedChangesOf(entry *stackEntry) []world.ModChange {
+	var changes []world.ModChange
+	for ; entry != nil; entry = entry.link {
+		recorded, isRecorded := entry.cmd.(*RecordedCommand)
+		if !isRecorded {
+			break
+		}
+		if !recorded.Change.IsEmpty() {
+			changes = append(changes, recorded.Change)
+		}
+	}
+	return changes
+}
+
+// RestoreRecordedChanges replaces the content of the stack with recorded commands of given changes.
+// Both lists are expected to start with the most recent change, as returned by RecordedChanges().
+func (stack *Stack) RestoreRecordedChanges(undo []world.ModChange, redo []world.ModChange) {
+	stack.lock("RestoreRecordedChanges")
+	defer stack.unlock()
+
+	stack.undoList = entriesOfRecordedChanges(undo)
+	stack.redoList = entriesOfRecordedChanges(redo)
+}
+
+func entriesOfRecordedChanges(changes []world.ModChange) *stackEntry {
+	var list *stackEntry
+	for index := len(changes) - 1; index >= 0; index-- {
+		list = &stackEntry{link: list, cmd: &RecordedCommand{Change: changes[index]}}
+	}
+	return list
+}
+
 func (stack *Stack) lock(by string) {
 	if stack.lockedBy != "" {
 		panic("Stack already in use by <" + stack.lockedBy + ">")
@@ -176,6 +176,34 @@ func (suite *StackSuite) TestPerformDropsPendingRedoStack() {
 	suite.thenCommandShouldHaveBeenExecutedTimes("cmd1", 1)
 }
 
+func (suite *StackSuite) TestRecordedChangesEndAtFirstCommandThatIsNotRecorded() {
+	suite.givenCommandWasPerformed("cmd1")
+	suite.whenPerforming(&cmd.RecordedCommand{Command: suite.aCommand("cmd2"), Change: aChange(2)})
+	suite.whenPerforming(&cmd.RecordedCommand{Command: suite.aCommand("cmd3")})
+	suite.whenPerforming(&cmd.RecordedCommand{Command: suite.aCommand("cmd4"), Change: aChange(4)})
+	suite.givenUndoWasCalledTimes(1)
+
+	undo, redo := suite.stack.RecordedChanges()
+	assert.Equal(suite.T(), []world.ModChange{aChange(2)}, undo, "undo list should skip empty changes")
+	assert.Equal(suite.T(), []world.ModChange{aChange(4)}, redo, "redo list should contain recorded change")
+}
+
+func (suite *StackSuite) TestRestoreRecordedChangesReplacesStack() {
+	suite.givenCommandWasPerformed("cmd1")
+	suite.givenUndoWasCalledTimes(1)
+
+	suite.stack.RestoreRecordedChanges([]world.ModChange{aChange(2), aChange(1)}, nil)
+	suite.thenStackShouldSupportUndo()
+	suite.thenStackShouldNotSupportRedo()
+	var trans world.ModTransaction
+	err := suite.stack.Undo(&trans)
+	require.Nil(suite.T(), err, "no error expected")
+
+	undo, redo := suite.stack.RecordedChanges()
+	assert.Equal(suite.T(), []world.ModChange{aChange(1)}, undo, "older change should remain")
+	assert.Equal(suite.T(), []world.ModChange{aChange(2)}, redo, "undone change should be redoable")
+}
+
 func (suite *StackSuite) TestPerformPanicsIfStackIsInUse() {
 	callPerform := func(name string) func() {
 		var times int
@@ -346,3 +374,7 @@ func (suite *StackSuite) pastCommand(name string) *TestCommand {
 	}
 	return cmd
 }
+
+func aChange(index int) world.ModChange {
+	return world.ModChange{TextureProperties: []world.TexturePropertiesChange{{Index: index}}}
+}
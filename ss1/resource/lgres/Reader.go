@@ -20,6 +20,7 @@ type Reader struct {
 	firstResourceOffset uint32
 	directoryOffset     uint32
 	directory           []resourceDirectoryEntry
+	startOffsets        []uint32
 
 	cache map[uint16]resource.View
 }
@@ -51,14 +52,32 @@ func ReaderFrom(source io.ReaderAt) (reader *Reader, err error) {
 		return nil, err
 	}
 
-	reader = &Reader{
+	reader = newReader(source, firstResourceOffset, dirOffset, directory, resourceStartOffsets(firstResourceOffset, directory))
+
+	return
+}
+
+func newReader(source io.ReaderAt, firstResourceOffset uint32, dirOffset uint32,
+	directory []resourceDirectoryEntry, startOffsets []uint32) *Reader {
+	return &Reader{
 		source:              source,
 		firstResourceOffset: firstResourceOffset,
 		directoryOffset:     dirOffset,
 		directory:           directory,
+		startOffsets:        startOffsets,
 		cache:               make(map[uint16]resource.View)}
+}
 
-	return
+// resourceStartOffsets returns the offsets of the resource data, following the sequence of the directory.
+func resourceStartOffsets(firstResourceOffset uint32, directory []resourceDirectoryEntry) []uint32 {
+	offsets := make([]uint32, len(directory))
+	startOffset := firstResourceOffset
+	for index, entry := range directory {
+		offsets[index] = startOffset
+		startOffset += entry.packedLength()
+		startOffset += (format.BoundarySize - (startOffset % format.BoundarySize)) % format.BoundarySize
+	}
+	return offsets
 }
 
 // IDs returns the resource identifier available via this reader.
@@ -97,6 +116,16 @@ func (reader *Reader) View(id resource.ID) (retrievedResource resource.View, err
 	return
 }
 
+// StoredLength returns the amount of bytes the identified resource occupies in the source.
+// For compressed resources, this is the length of the compressed data.
+func (reader *Reader) StoredLength(id resource.ID) (uint32, error) {
+	_, entry := reader.findEntry(id.Value())
+	if entry == nil {
+		return 0, resource.ErrNotFound(id)
+	}
+	return entry.packedLength(), nil
+}
+
 func readAndVerifyHeader(source io.ReadSeeker) (dirOffset uint32, err error) {
 	coder := serial.NewPositioningDecoder(source)
 	data := make([]byte, format.ResourceDirectoryFileOffsetPos)
@@ -137,17 +166,12 @@ func readDirectoryAt(dirOffset uint32, source io.ReaderAt) (firstResourceOffset
 }
 
 func (reader *Reader) findEntry(id uint16) (startOffset uint32, entry *resourceDirectoryEntry) {
-	startOffset = reader.firstResourceOffset
-	for index := 0; (index < len(reader.directory)) && (entry == nil); index++ {
-		cur := &reader.directory[index]
-		if cur.ID == id {
-			entry = cur
-		} else {
-			startOffset += cur.packedLength()
-			startOffset += (format.BoundarySize - (startOffset % format.BoundarySize)) % format.BoundarySize
+	for index := range reader.directory {
+		if reader.directory[index].ID == id {
+			return reader.startOffsets[index], &reader.directory[index]
 		}
 	}
-	return
+	return 0, nil
 }
 
 type blockListEntry struct {
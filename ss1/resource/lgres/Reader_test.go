@@ -153,3 +153,14 @@ func verifyBlockContent(t *testing.T, blockProvider resource.BlockProvider, bloc
 	assert.Nil(t, dataErr, "no error expected reading data")
 	assert.Equal(t, expected, data)
 }
+
+func TestReaderStoredLength(t *testing.T) {
+	reader, _ := lgres.ReaderFrom(bytes.NewReader(exampleResourceFile()))
+
+	length, err := reader.StoredLength(exampleResourceIDSingleBlockResource)
+	assert.Nil(t, err, "no error expected")
+	assert.Equal(t, uint32(3), length)
+
+	_, err = reader.StoredLength(resource.ID(0x1111))
+	assert.NotNil(t, err, "error expected for unknown ID")
+}
@@ -0,0 +1,289 @@
+package lgres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres/internal/compression"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres/internal/format"
+)
+
+// Inconsistency describes a problem found in the serialized form of a resource file.
+type Inconsistency struct {
+	// Offset is the position within the file at which the problem was found.
+	Offset int64
+	// ResourceAffected is set if the problem concerns a single resource.
+	ResourceAffected bool
+	// ID identifies the affected resource. It is only valid if ResourceAffected is set.
+	ID resource.ID
+	// Description explains the problem.
+	Description string
+}
+
+// String returns a textual representation of the inconsistency.
+func (inc Inconsistency) String() string {
+	if inc.ResourceAffected {
+		return fmt.Sprintf("0x%08X: resource %v: %s", inc.Offset, inc.ID, inc.Description)
+	}
+	return fmt.Sprintf("0x%08X: %s", inc.Offset, inc.Description)
+}
+
+// Verify walks the header, the directory, and the data of all resources of the given source and
+// reports every inconsistency it finds. The size is the total length of the source in bytes.
+// An empty result means that the source is a consistent resource file.
+func Verify(source io.ReaderAt, size int64) []Inconsistency {
+	v := verifier{source: source, size: size}
+	_, _ = v.verify()
+	return v.inconsistencies
+}
+
+// SalvageReaderFrom verifies the given source (see Verify) and returns a reader for all resources
+// that are intact. Damaged resources are not provided by the returned reader.
+// An error is returned if the source does not start with the header of a resource file, or if its directory
+// is not completely within the source. Such sources are not considered to be resource files at all.
+func SalvageReaderFrom(source io.ReaderAt, size int64) (*Reader, []Inconsistency, error) {
+	if source == nil {
+		return nil, nil, ErrSourceNil
+	}
+	v := verifier{source: source, size: size, salvaging: true}
+	reader, err := v.verify()
+	return reader, v.inconsistencies, err
+}
+
+// VerifyLayoutOf checks the header, the directory, and the block tables of all resources of the given source,
+// as SalvageReaderFrom() does, yet without decompressing any data. The returned reader provides the resources
+// with an intact layout. Damaged compressed data is not detected this way, yet the check is fast enough to
+// be done whenever a file is loaded.
+func VerifyLayoutOf(source io.ReaderAt, size int64) (*Reader, []Inconsistency, error) {
+	if source == nil {
+		return nil, nil, ErrSourceNil
+	}
+	v := verifier{source: source, size: size, salvaging: true, layoutOnly: true}
+	reader, err := v.verify()
+	return reader, v.inconsistencies, err
+}
+
+type verifier struct {
+	source          io.ReaderAt
+	size            int64
+	inconsistencies []Inconsistency
+
+	// salvaging requires the header and the directory to be intact, instead of reporting as much as possible.
+	salvaging bool
+	// layoutOnly skips the decompression of data.
+	layoutOnly bool
+}
+
+func (v *verifier) report(offset int64, text string, args ...interface{}) {
+	v.inconsistencies = append(v.inconsistencies, Inconsistency{
+		Offset:      offset,
+		Description: fmt.Sprintf(text, args...),
+	})
+}
+
+func (v *verifier) reportFor(id uint16, offset int64, text string, args ...interface{}) {
+	v.inconsistencies = append(v.inconsistencies, Inconsistency{
+		Offset:           offset,
+		ResourceAffected: true,
+		ID:               resource.ID(id),
+		Description:      fmt.Sprintf(text, args...),
+	})
+}
+
+func (v *verifier) verify() (*Reader, error) {
+	headerSize := int64(format.ResourceDirectoryFileOffsetPos + 4)
+	if v.size < headerSize {
+		v.report(0, "file too short for header: %d bytes", v.size)
+		return nil, ErrFormatMismatch
+	}
+	dirOffset, err := readAndVerifyHeader(io.NewSectionReader(v.source, 0, headerSize))
+	if err == ErrFormatMismatch {
+		v.report(0, "header string mismatch")
+		if v.salvaging {
+			return nil, err
+		}
+		dirOffset, err = v.readUint32(format.ResourceDirectoryFileOffsetPos)
+	}
+	if err != nil {
+		v.report(0, "header not readable: %v", err)
+		return nil, err
+	}
+
+	var header resourceDirectoryHeader
+	dirHeaderSize := int64(binary.Size(&header))
+	if (int64(dirOffset) < headerSize) || (int64(dirOffset)+dirHeaderSize > v.size) {
+		v.report(format.ResourceDirectoryFileOffsetPos, "directory offset 0x%08X outside of file (size 0x%08X)", dirOffset, v.size)
+		return nil, ErrFormatMismatch
+	}
+	firstResourceOffset, directory, err := readDirectoryAt(dirOffset, io.NewSectionReader(v.source, 0, v.size))
+	if (err != nil) && (directory == nil) {
+		v.report(int64(dirOffset), "directory header not readable: %v", err)
+		return nil, err
+	}
+	if (int64(firstResourceOffset) < headerSize) || (firstResourceOffset > dirOffset) {
+		v.report(int64(dirOffset)+2, "first resource offset 0x%08X outside of data area, assuming 0x%08X",
+			firstResourceOffset, headerSize)
+		firstResourceOffset = uint32(headerSize)
+	}
+	entrySize := int64(binary.Size(resourceDirectoryEntry{}))
+	entriesOffset := int64(dirOffset) + dirHeaderSize
+	if availableEntries := int((v.size - entriesOffset) / entrySize); len(directory) > availableEntries {
+		v.report(int64(dirOffset), "directory lists %d resources, yet only %d entries fit into the file",
+			len(directory), availableEntries)
+		if v.salvaging {
+			return nil, ErrFormatMismatch
+		}
+		directory, err = v.readEntries(entriesOffset, availableEntries)
+		if err != nil {
+			v.report(entriesOffset, "directory entries not readable: %v", err)
+			return nil, err
+		}
+	}
+
+	startOffsets := resourceStartOffsets(firstResourceOffset, directory)
+	var intactDirectory []resourceDirectoryEntry
+	var intactOffsets []uint32
+	knownIDs := make(map[uint16]bool)
+	for index, entry := range directory {
+		entryOffset := entriesOffset + int64(index)*entrySize
+		if knownIDs[entry.ID] {
+			v.reportFor(entry.ID, entryOffset, "duplicate directory entry, only the first entry is used")
+			continue
+		}
+		knownIDs[entry.ID] = true
+		if v.verifyResource(entry, entryOffset, startOffsets[index], dirOffset, len(directory) == 1) {
+			intactDirectory = append(intactDirectory, entry)
+			intactOffsets = append(intactOffsets, startOffsets[index])
+		}
+	}
+	return newReader(v.source, firstResourceOffset, dirOffset, intactDirectory, intactOffsets), nil
+}
+
+func (v *verifier) verifyResource(entry resourceDirectoryEntry, entryOffset int64, startOffset uint32, dirOffset uint32, isOnly bool) bool {
+	startPos := int64(startOffset)
+	endPos := startPos + int64(entry.packedLength())
+	if endPos > v.size {
+		v.reportFor(entry.ID, entryOffset, "data at 0x%08X with length %d exceeds file (size 0x%08X)",
+			startPos, entry.packedLength(), v.size)
+		return false
+	}
+	isCutscene := isOnly && (resource.ContentType(entry.contentType()) == resource.Movie)
+	if (startPos < int64(dirOffset)) && (endPos > int64(dirOffset)) && !isCutscene {
+		v.reportFor(entry.ID, entryOffset, "data at 0x%08X with length %d overlaps directory at 0x%08X",
+			startPos, entry.packedLength(), dirOffset)
+		return false
+	}
+	resourceType := entry.resourceType()
+	compressed := (resourceType & format.ResourceTypeFlagCompressed) != 0
+	isCompound := (resourceType & format.ResourceTypeFlagCompound) != 0
+	if v.layoutOnly && !isCompound {
+		return true
+	}
+	data := make([]byte, entry.packedLength())
+	_, err := v.source.ReadAt(data, startPos)
+	if err != nil {
+		v.reportFor(entry.ID, startPos, "data not readable: %v", err)
+		return false
+	}
+	if isCompound {
+		return v.verifyCompoundData(entry, entryOffset, startPos, data, compressed)
+	}
+	return v.verifySingleData(entry, entryOffset, startPos, data, compressed)
+}
+
+func (v *verifier) verifySingleData(entry resourceDirectoryEntry, entryOffset int64, startPos int64, data []byte, compressed bool) bool {
+	if !compressed {
+		if entry.unpackedLength() != entry.packedLength() {
+			v.reportFor(entry.ID, entryOffset, "unpacked length %d differs from packed length %d of uncompressed data",
+				entry.unpackedLength(), entry.packedLength())
+		}
+		return true
+	}
+	decompressed, err := decompressAtMost(data, int64(entry.unpackedLength()))
+	if err != nil {
+		v.reportFor(entry.ID, startPos, "compressed data damaged: %v", err)
+		return false
+	}
+	if len(decompressed) < int(entry.unpackedLength()) {
+		v.reportFor(entry.ID, startPos, "compressed data provides %d bytes, expected %d",
+			len(decompressed), entry.unpackedLength())
+		return false
+	}
+	return true
+}
+
+func (v *verifier) verifyCompoundData(entry resourceDirectoryEntry, entryOffset int64, startPos int64, data []byte, compressed bool) bool {
+	if len(data) < 6 {
+		v.reportFor(entry.ID, startPos, "data too short for block table: %d bytes", len(data))
+		return false
+	}
+	blockCount := int(binary.LittleEndian.Uint16(data[0:2]))
+	tableSize := 2 + (blockCount+1)*4
+	if tableSize > len(data) {
+		v.reportFor(entry.ID, startPos, "block table for %d blocks exceeds data length %d", blockCount, len(data))
+		return false
+	}
+	offsets := make([]uint32, blockCount+1)
+	for index := range offsets {
+		offsets[index] = binary.LittleEndian.Uint32(data[2+index*4:])
+	}
+	if offsets[0] < uint32(tableSize) {
+		v.reportFor(entry.ID, startPos+2, "first block offset %d within block table of size %d", offsets[0], tableSize)
+		return false
+	}
+	for index := 1; index < len(offsets); index++ {
+		if offsets[index] < offsets[index-1] {
+			v.reportFor(entry.ID, startPos+2+int64(index)*4, "end offset %d of block %d before its start offset %d",
+				offsets[index], index-1, offsets[index-1])
+			return false
+		}
+	}
+
+	lastEnd := offsets[blockCount]
+	available := int64(len(data))
+	if compressed {
+		if int(offsets[0]) > len(data) {
+			v.reportFor(entry.ID, startPos+2, "first block offset %d exceeds data length %d", offsets[0], len(data))
+			return false
+		}
+		available = int64(lastEnd)
+		if !v.layoutOnly {
+			decompressed, err := decompressAtMost(data[offsets[0]:], int64(lastEnd-offsets[0]))
+			if err != nil {
+				v.reportFor(entry.ID, startPos+int64(offsets[0]), "compressed data damaged: %v", err)
+				return false
+			}
+			available = int64(offsets[0]) + int64(len(decompressed))
+		}
+	}
+	if int64(lastEnd) > available {
+		v.reportFor(entry.ID, startPos+2+int64(blockCount)*4, "blocks end at %d, yet only %d bytes are available",
+			lastEnd, available)
+		return false
+	}
+	if lastEnd != entry.unpackedLength() {
+		v.reportFor(entry.ID, entryOffset, "unpacked length %d differs from end of blocks %d", entry.unpackedLength(), lastEnd)
+	}
+	return true
+}
+
+func (v *verifier) readUint32(offset int64) (uint32, error) {
+	var value uint32
+	err := binary.Read(io.NewSectionReader(v.source, offset, 4), binary.LittleEndian, &value)
+	return value, err
+}
+
+func (v *verifier) readEntries(offset int64, count int) ([]resourceDirectoryEntry, error) {
+	entries := make([]resourceDirectoryEntry, count)
+	err := binary.Read(io.NewSectionReader(v.source, offset, int64(binary.Size(entries))), binary.LittleEndian, entries)
+	return entries, err
+}
+
+// decompressAtMost decompresses the given data, yet stops after the given limit.
+func decompressAtMost(data []byte, limit int64) ([]byte, error) {
+	return ioutil.ReadAll(io.LimitReader(compression.NewDecompressor(bytes.NewReader(data)), limit))
+}
@@ -0,0 +1,132 @@
+package lgres_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres/internal/format"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyReportsNothingForConsistentFiles(t *testing.T) {
+	for _, data := range [][]byte{emptyResourceFile(), exampleResourceFile()} {
+		result := lgres.Verify(bytes.NewReader(data), int64(len(data)))
+		assert.Empty(t, result, "no inconsistencies expected")
+	}
+}
+
+func TestVerifyReportsHeaderStringMismatch(t *testing.T) {
+	data := exampleResourceFile()
+	data[3] = 'X'
+
+	result := lgres.Verify(bytes.NewReader(data), int64(len(data)))
+	require.Len(t, result, 1, "one inconsistency expected")
+	assert.Equal(t, int64(0), result[0].Offset)
+	assert.False(t, result[0].ResourceAffected, "no resource should be affected")
+}
+
+func TestVerifyReportsDamagedBlockTableWithOffset(t *testing.T) {
+	data := exampleResourceFile()
+	tableOffset := compoundBlockTableOffset(t, data)
+	binary.LittleEndian.PutUint32(data[tableOffset+2+4:], 0x20)
+
+	result := lgres.Verify(bytes.NewReader(data), int64(len(data)))
+	require.Len(t, result, 1, "one inconsistency expected")
+	assert.Equal(t, int64(tableOffset+2+2*4), result[0].Offset)
+	assert.True(t, result[0].ResourceAffected, "resource should be affected")
+	assert.Equal(t, exampleResourceIDCompoundResource, result[0].ID)
+}
+
+func TestVerifyReportsDirectoryExceedingFile(t *testing.T) {
+	data := exampleResourceFile()
+	dirOffset := binary.LittleEndian.Uint32(data[format.ResourceDirectoryFileOffsetPos:])
+	binary.LittleEndian.PutUint16(data[dirOffset:], 10)
+
+	result := lgres.Verify(bytes.NewReader(data), int64(len(data)))
+	require.Len(t, result, 1, "one inconsistency expected")
+	assert.Equal(t, int64(dirOffset), result[0].Offset)
+}
+
+func TestSalvageReaderFromProvidesIntactResources(t *testing.T) {
+	data := exampleResourceFile()
+	tableOffset := compoundBlockTableOffset(t, data)
+	binary.LittleEndian.PutUint32(data[tableOffset+2+4:], 0x20)
+
+	reader, result, err := lgres.SalvageReaderFrom(bytes.NewReader(data), int64(len(data)))
+	require.Nil(t, err, "no error expected")
+	assert.Len(t, result, 1, "one inconsistency expected")
+	assert.Equal(t, []resource.ID{exampleResourceIDSingleBlockResource, exampleResourceIDSingleBlockResourceCompressed,
+		exampleResourceIDCompoundResourceCompressed}, reader.IDs())
+	view, err := reader.View(exampleResourceIDCompoundResourceCompressed)
+	require.Nil(t, err, "no error expected for intact resource")
+	verifyBlockContent(t, view, 1, []byte{0x41, 0x41, 0x41, 0x41})
+}
+
+func TestVerifyLayoutOfProvidesResourcesWithIntactLayout(t *testing.T) {
+	data := exampleResourceFile()
+	tableOffset := compoundBlockTableOffset(t, data)
+	binary.LittleEndian.PutUint32(data[tableOffset+2+4:], 0x20)
+
+	reader, result, err := lgres.VerifyLayoutOf(bytes.NewReader(data), int64(len(data)))
+	require.Nil(t, err, "no error expected")
+	assert.Len(t, result, 1, "one inconsistency expected")
+	assert.Equal(t, []resource.ID{exampleResourceIDSingleBlockResource, exampleResourceIDSingleBlockResourceCompressed,
+		exampleResourceIDCompoundResourceCompressed}, reader.IDs())
+}
+
+func TestSalvageReaderFromReturnsErrorForMissingDirectory(t *testing.T) {
+	data := exampleResourceFile()
+	dirOffset := binary.LittleEndian.Uint32(data[format.ResourceDirectoryFileOffsetPos:])
+	truncated := data[:dirOffset]
+
+	reader, result, err := lgres.SalvageReaderFrom(bytes.NewReader(truncated), int64(len(truncated)))
+	assert.NotNil(t, err, "error expected")
+	assert.Nil(t, reader, "no reader expected")
+	assert.Len(t, result, 1, "one inconsistency expected")
+}
+
+func TestSalvageReaderFromReturnsErrorForHeaderStringMismatch(t *testing.T) {
+	data := exampleResourceFile()
+	data[3] = 'X'
+
+	reader, result, err := lgres.SalvageReaderFrom(bytes.NewReader(data), int64(len(data)))
+	assert.NotNil(t, err, "error expected")
+	assert.Nil(t, reader, "no reader expected")
+	assert.Len(t, result, 1, "one inconsistency expected")
+}
+
+func TestSalvageReaderFromReturnsErrorForDirectoryExceedingFile(t *testing.T) {
+	data := exampleResourceFile()
+	dirOffset := binary.LittleEndian.Uint32(data[format.ResourceDirectoryFileOffsetPos:])
+	binary.LittleEndian.PutUint16(data[dirOffset:], 10)
+
+	reader, _, err := lgres.SalvageReaderFrom(bytes.NewReader(data), int64(len(data)))
+	assert.NotNil(t, err, "error expected")
+	assert.Nil(t, reader, "no reader expected")
+}
+
+func TestSalvageReaderFromRejectsRandomData(t *testing.T) {
+	random := rand.New(rand.NewSource(0)) // nolint: gosec
+	for attempt := 0; attempt < 200; attempt++ {
+		data := make([]byte, 16+random.Intn(1024))
+		_, _ = random.Read(data)
+
+		reader, _, err := lgres.SalvageReaderFrom(bytes.NewReader(data), int64(len(data)))
+		require.NotNil(t, err, "error expected for attempt %d", attempt)
+		require.Nil(t, reader, "no reader expected for attempt %d", attempt)
+	}
+}
+
+func compoundBlockTableOffset(t *testing.T, data []byte) int {
+	t.Helper()
+	table := []byte{0x02, 0x00, 0x0E, 0x00, 0x00, 0x00, 0x12, 0x00, 0x00, 0x00, 0x15, 0x00, 0x00, 0x00}
+	offset := bytes.Index(data, table)
+	require.True(t, offset > 0, "block table not found")
+	return offset
+}
@@ -0,0 +1,18 @@
+package unpacked
+
+import "github.com/inkyblackness/hacked/ss1"
+
+const (
+	// IndexFilename is the name of the file listing the resources of a directory in their order.
+	IndexFilename = "index.json"
+	// PropertiesFilename is the name of the file describing the properties of a single resource.
+	PropertiesFilename = "resource.json"
+	// BlockFileExtension is the extension of the files containing block data.
+	BlockFileExtension = ".bin"
+)
+
+const (
+	errNotADirectory       ss1.StringError = "not a directory"
+	errBlockCountInvalid   ss1.StringError = "invalid block count"
+	errResourceIDMalformed ss1.StringError = "malformed resource ID"
+)
@@ -0,0 +1,39 @@
+package unpacked
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+type index struct {
+	Language resource.Language
+	IDs      []string
+}
+
+type properties struct {
+	ContentType resource.ContentType
+	Compound    bool
+	Compressed  bool
+	BlockCount  int
+}
+
+func resourceDirName(id resource.ID) string {
+	return id.String()
+}
+
+func blockFilename(index int) string {
+	return fmt.Sprintf("%04d", index) + BlockFileExtension
+}
+
+func parseResourceID(name string) (resource.ID, error) {
+	if len(name) != 4 {
+		return 0, errResourceIDMalformed
+	}
+	value, err := strconv.ParseUint(name, 16, 16)
+	if err != nil {
+		return 0, errResourceIDMalformed
+	}
+	return resource.ID(value), nil
+}
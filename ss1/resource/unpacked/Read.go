@@ -0,0 +1,135 @@
+package unpacked
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// Read loads all the resources stored in the directory with given name.
+//
+// The order of the resources is taken from the index file. Resources without an entry in the index
+// are added in ascending order of their identifier. This allows adding resources manually.
+func Read(dir string) (resource.Store, error) {
+	lang := resource.LangAny
+	return readStore(dir, &lang)
+}
+
+// ReadLocalized loads all the resources like Read(), and returns them with their language.
+// The ID of the returned entry is the base name of the directory.
+func ReadLocalized(dir string) (resource.LocalizedResources, error) {
+	localized := resource.LocalizedResources{
+		ID:       filepath.Base(dir),
+		Language: resource.LangAny,
+	}
+	store, err := readStore(dir, &localized.Language)
+	localized.Viewer = store
+	return localized, err
+}
+
+// IsUnpacked returns true if the given name refers to a directory with an index file.
+func IsUnpacked(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, IndexFilename))
+	return (err == nil) && !info.IsDir()
+}
+
+func readStore(dir string, lang *resource.Language) (resource.Store, error) {
+	var store resource.Store
+	info, err := os.Stat(dir)
+	if err != nil {
+		return store, err
+	}
+	if !info.IsDir() {
+		return store, errNotADirectory
+	}
+	ids, err := resourceIDs(dir, lang)
+	if err != nil {
+		return store, err
+	}
+	for _, id := range ids {
+		res, resErr := readResource(filepath.Join(dir, resourceDirName(id)))
+		if resErr != nil {
+			return store, resErr
+		}
+		err = store.Put(id, res)
+		if err != nil {
+			return store, err
+		}
+	}
+	return store, nil
+}
+
+func resourceIDs(dir string, lang *resource.Language) ([]resource.ID, error) {
+	idx := index{Language: *lang}
+	err := readJSON(filepath.Join(dir, IndexFilename), &idx)
+	if (err != nil) && !os.IsNotExist(err) {
+		return nil, err
+	}
+	*lang = idx.Language
+	var result []resource.ID
+	listed := make(map[resource.ID]bool)
+	for _, name := range idx.IDs {
+		id, idErr := parseResourceID(name)
+		if idErr != nil {
+			return nil, idErr
+		}
+		result = append(result, id)
+		listed[id] = true
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var unlisted []resource.ID
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		id, idErr := parseResourceID(info.Name())
+		if (idErr != nil) || listed[id] {
+			continue
+		}
+		unlisted = append(unlisted, id)
+	}
+	sort.Slice(unlisted, func(a, b int) bool { return unlisted[a] < unlisted[b] })
+	return append(result, unlisted...), nil
+}
+
+func readResource(dir string) (resource.Resource, error) {
+	var props properties
+	err := readJSON(filepath.Join(dir, PropertiesFilename), &props)
+	if err != nil {
+		return resource.Resource{}, err
+	}
+	if (props.BlockCount < 0) || (!props.Compound && (props.BlockCount != 1)) {
+		return resource.Resource{}, errBlockCountInvalid
+	}
+	data := make([][]byte, props.BlockCount)
+	for blockIndex := 0; blockIndex < props.BlockCount; blockIndex++ {
+		data[blockIndex], err = ioutil.ReadFile(filepath.Join(dir, blockFilename(blockIndex)))
+		if err != nil {
+			return resource.Resource{}, err
+		}
+	}
+	return resource.Resource{
+		Properties: resource.Properties{
+			Compound:    props.Compound,
+			ContentType: props.ContentType,
+			Compressed:  props.Compressed,
+		},
+		Blocks: resource.BlocksFrom(data),
+	}, nil
+}
+
+func readJSON(filename string, value interface{}) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
@@ -0,0 +1,105 @@
+package unpacked
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// Write serializes the resources from given source into the directory with given name.
+// The directory is created if it does not exist. Any previously stored resources in the
+// directory are removed, other files are left untouched.
+// The resources are marked to be language agnostic.
+func Write(dir string, source resource.Viewer) error {
+	return WriteLocalized(dir, resource.LangAny, source)
+}
+
+// WriteLocalized serializes the resources like Write(), and marks them to be of given language.
+func WriteLocalized(dir string, lang resource.Language, source resource.Viewer) error {
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return err
+	}
+	err = removeResources(dir)
+	if err != nil {
+		return err
+	}
+
+	idx := index{Language: lang}
+	for _, id := range source.IDs() {
+		view, viewErr := source.View(id)
+		if viewErr != nil {
+			return viewErr
+		}
+		resourceErr := writeResource(filepath.Join(dir, resourceDirName(id)), view)
+		if resourceErr != nil {
+			return resourceErr
+		}
+		idx.IDs = append(idx.IDs, resourceDirName(id))
+	}
+	return writeJSON(filepath.Join(dir, IndexFilename), idx)
+}
+
+func removeResources(dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		if _, idErr := parseResourceID(info.Name()); idErr != nil {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeResource(dir string, view resource.View) error {
+	err := os.Mkdir(dir, 0750)
+	if err != nil {
+		return err
+	}
+	props := properties{
+		ContentType: view.ContentType(),
+		Compound:    view.Compound(),
+		Compressed:  view.Compressed(),
+		BlockCount:  view.BlockCount(),
+	}
+	for blockIndex := 0; blockIndex < props.BlockCount; blockIndex++ {
+		reader, blockErr := view.Block(blockIndex)
+		if blockErr != nil {
+			return blockErr
+		}
+		blockErr = writeBlock(filepath.Join(dir, blockFilename(blockIndex)), reader)
+		if blockErr != nil {
+			return blockErr
+		}
+	}
+	return writeJSON(filepath.Join(dir, PropertiesFilename), props)
+}
+
+func writeBlock(filename string, reader io.Reader) error {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0640)
+}
+
+func writeJSON(filename string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0640)
+}
@@ -0,0 +1,143 @@
+package unpacked_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/resource/unpacked"
+	"github.com/inkyblackness/hacked/ss1/serial"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCreatesFilePerBlock(t *testing.T) {
+	dir := t.TempDir()
+	store := exampleStore()
+
+	err := unpacked.Write(dir, store)
+	require.Nil(t, err, "no error expected writing")
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "0003", "0001.bin"))
+	require.Nil(t, err, "no error expected reading block file")
+	assert.Equal(t, []byte{0x22, 0x23}, data)
+	_, err = os.Stat(filepath.Join(dir, "0003", unpacked.PropertiesFilename))
+	assert.Nil(t, err, "properties file expected")
+}
+
+func TestWriteRemovesPreviousResources(t *testing.T) {
+	dir := t.TempDir()
+	err := unpacked.Write(dir, exampleStore())
+	require.Nil(t, err, "no error expected writing")
+
+	var store resource.Store
+	_ = store.Put(resource.ID(2), aResource(false, resource.Bitmap, false, [][]byte{{0x11}}))
+	err = unpacked.Write(dir, store)
+	require.Nil(t, err, "no error expected writing again")
+
+	_, err = os.Stat(filepath.Join(dir, "0003"))
+	assert.True(t, os.IsNotExist(err), "previous resource should be removed")
+}
+
+func TestWriteAndReadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := exampleStore()
+
+	err := unpacked.Write(dir, store)
+	require.Nil(t, err, "no error expected writing")
+	restored, err := unpacked.Read(dir)
+	require.Nil(t, err, "no error expected reading")
+
+	assert.Equal(t, store.IDs(), restored.IDs(), "order of IDs should be kept")
+	assert.Equal(t, resFileData(t, store), resFileData(t, restored), "serialized data should be identical")
+}
+
+func TestReadAddsUnlistedResourcesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	err := unpacked.Write(dir, exampleStore())
+	require.Nil(t, err, "no error expected writing")
+	err = os.Remove(filepath.Join(dir, unpacked.IndexFilename))
+	require.Nil(t, err, "no error expected removing index")
+
+	restored, err := unpacked.Read(dir)
+	require.Nil(t, err, "no error expected reading")
+	assert.Equal(t, []resource.ID{1, 2, 3, 4}, restored.IDs())
+}
+
+func TestReadReturnsErrorForMissingBlock(t *testing.T) {
+	dir := t.TempDir()
+	err := unpacked.Write(dir, exampleStore())
+	require.Nil(t, err, "no error expected writing")
+	err = os.Remove(filepath.Join(dir, "0004", "0000.bin"))
+	require.Nil(t, err, "no error expected removing block")
+
+	_, err = unpacked.Read(dir)
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestReadReturnsErrorForFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "file.res")
+	err := ioutil.WriteFile(filename, []byte{0x00}, 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	_, err = unpacked.Read(filename)
+	assert.NotNil(t, err, "error expected")
+}
+
+func exampleStore() resource.Store {
+	var store resource.Store
+	_ = store.Put(resource.ID(1), aResource(false, resource.Bitmap, false, [][]byte{{0x11}}))
+	_ = store.Put(resource.ID(3), aResource(false, resource.Font, true, [][]byte{{0x21}, {0x22, 0x23}, {}}))
+	_ = store.Put(resource.ID(2), aResource(true, resource.Geometry, false, [][]byte{{0x31}}))
+	_ = store.Put(resource.ID(4), aResource(true, resource.Archive, true, [][]byte{{0x41}, {0x42, 0x43}}))
+	return store
+}
+
+func aResource(compressed bool, contentType resource.ContentType, compound bool, data [][]byte) resource.View {
+	return resource.Resource{
+		Properties: resource.Properties{
+			Compressed:  compressed,
+			ContentType: contentType,
+			Compound:    compound,
+		},
+		Blocks: resource.BlocksFrom(data),
+	}
+}
+
+func resFileData(t *testing.T, viewer resource.Viewer) []byte {
+	t.Helper()
+	target := serial.NewByteStore()
+	err := lgres.Write(target, viewer)
+	require.Nil(t, err, "no error expected serializing")
+	return target.Data()
+}
+
+func TestWriteLocalizedKeepsLanguage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "gerstrng.res")
+	err := unpacked.WriteLocalized(dir, resource.LangGerman, exampleStore())
+	require.Nil(t, err, "no error expected writing")
+
+	assert.True(t, unpacked.IsUnpacked(dir), "directory should be detected")
+	localized, err := unpacked.ReadLocalized(dir)
+	require.Nil(t, err, "no error expected reading")
+	assert.Equal(t, resource.LangGerman, localized.Language)
+	assert.Equal(t, "gerstrng.res", localized.ID)
+}
+
+func TestReadLocalizedDefaultsToAnyLanguageWithoutIndex(t *testing.T) {
+	dir := t.TempDir()
+	err := unpacked.WriteLocalized(dir, resource.LangFrench, exampleStore())
+	require.Nil(t, err, "no error expected writing")
+	err = os.Remove(filepath.Join(dir, unpacked.IndexFilename))
+	require.Nil(t, err, "no error expected removing index")
+
+	assert.False(t, unpacked.IsUnpacked(dir), "directory should not be detected")
+	localized, err := unpacked.ReadLocalized(dir)
+	require.Nil(t, err, "no error expected reading")
+	assert.Equal(t, resource.LangAny, localized.Language)
+}
@@ -0,0 +1,6 @@
+/*
+Package unpacked implements serialization of resources as a directory tree.
+Each resource is stored in a sub-directory, with one file per block and a small metadata file.
+This form is meant to be used with version control and external tools.
+*/
+package unpacked
@@ -0,0 +1,31 @@
+package serial
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DecodeError describes where decoding failed.
+type DecodeError struct {
+	// Offset is the position, in bytes, at which the failing read started.
+	Offset uint32
+	// Path lists the names of the fields that were being decoded, outermost first.
+	// It is empty if the decoded structures do not report their fields.
+	Path []string
+	// Err is the error that caused the failure.
+	Err error
+}
+
+// Error returns the textual representation.
+func (err *DecodeError) Error() string {
+	text := fmt.Sprintf("%v at offset 0x%X", err.Err, err.Offset)
+	if len(err.Path) > 0 {
+		text += " in " + strings.Join(err.Path, ".")
+	}
+	return text
+}
+
+// Unwrap returns the nested error.
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
@@ -7,10 +7,12 @@ import (
 )
 
 // Decoder is for decoding from a reader.
+// Errors are reported as *DecodeError, providing the offset and the path of fields at which decoding failed.
 type Decoder struct {
 	source     io.Reader
 	firstError error
 	offset     uint32
+	path       []string
 }
 
 // NewDecoder creates a new Decoder from given source.
@@ -36,7 +38,37 @@ func (coder *Decoder) Code(value interface{}) {
 	if codable, isCodable := value.(Codable); isCodable {
 		codable.Code(coder)
 	} else {
-		coder.firstError = binary.Read(coder, binary.LittleEndian, value)
+		startOffset := coder.offset
+		coder.setError(startOffset, binary.Read(decoderSource{coder: coder}, binary.LittleEndian, value))
+	}
+}
+
+// EnterField marks the begin of decoding the named field.
+func (coder *Decoder) EnterField(name string) {
+	coder.path = append(coder.path, name)
+}
+
+// LeaveField marks the end of decoding the most recently entered field.
+func (coder *Decoder) LeaveField() {
+	if len(coder.path) > 0 {
+		coder.path = coder.path[:len(coder.path)-1]
+	}
+}
+
+// setError sets the first error, wrapped in a DecodeError, if there is none yet.
+func (coder *Decoder) setError(offset uint32, err error) {
+	if (coder.firstError != nil) || (err == nil) {
+		return
+	}
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		coder.firstError = err
+		return
+	}
+	coder.firstError = &DecodeError{
+		Offset: offset,
+		Path:   append([]string{}, coder.path...),
+		Err:    err,
 	}
 }
 
@@ -45,14 +77,25 @@ func (coder *Decoder) Read(data []byte) (read int, err error) {
 	if coder.firstError != nil {
 		return 0, coder.firstError
 	}
-	read, err = coder.source.Read(data)
-	coder.offset += uint32(read)
+	startOffset := coder.offset
+	read, err = decoderSource{coder: coder}.Read(data)
 
 	isErrEOF := errors.Is(err, io.EOF)
 	expectedAmountReturned := read == len(data)
 	errorCanBeIgnored := isErrEOF && expectedAmountReturned
-	if (coder.firstError == nil) && !errorCanBeIgnored {
-		coder.firstError = err
+	if !errorCanBeIgnored {
+		coder.setError(startOffset, err)
 	}
 	return
 }
+
+// decoderSource reads from the source of the decoder, keeping track of the offset, without recording errors.
+type decoderSource struct {
+	coder *Decoder
+}
+
+func (source decoderSource) Read(data []byte) (read int, err error) {
+	read, err = source.coder.source.Read(data)
+	source.coder.offset += uint32(read)
+	return
+}
@@ -2,12 +2,14 @@ package serial_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"testing"
 
 	"github.com/inkyblackness/hacked/ss1/serial"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -76,7 +78,7 @@ func (suite *DecoderSuite) TestFirstErrorReturnsFirstError() {
 	suite.errorBuf.errorOnNextCall = true
 	suite.coder.Code(uint16(0))
 
-	assert.EqualError(suite.T(), suite.coder.FirstError(), "errorBuffer on call number 2")
+	assert.EqualError(suite.T(), suite.coder.FirstError(), "errorBuffer on call number 2 at offset 0x4")
 }
 
 func (suite *DecoderSuite) TestFirstErrorIgnoresFurtherErrors() {
@@ -88,7 +90,7 @@ func (suite *DecoderSuite) TestFirstErrorIgnoresFurtherErrors() {
 	suite.coder.Code(uint32(0))
 
 	assert.Equal(suite.T(), suite.errorBuf.callCounter, 1)
-	assert.EqualError(suite.T(), suite.coder.FirstError(), "errorBuffer on call number 1")
+	assert.EqualError(suite.T(), suite.coder.FirstError(), "errorBuffer on call number 1 at offset 0x0")
 }
 
 func (suite *DecoderSuite) TestImplementsReaderInterface() {
@@ -136,3 +138,19 @@ func (suite *DecoderSuite) whenDecodingWithErrors() {
 	suite.errorBuf = new(errorBuffer)
 	suite.coder = serial.NewDecoder(suite.errorBuf)
 }
+
+func (suite *DecoderSuite) TestFirstErrorProvidesOffsetAndPathOfFailure() {
+	suite.whenDecodingFrom([]byte{0x01, 0x02, 0x03})
+
+	var first, second uint16
+	serial.CodeField(suite.coder, "first", &first)
+	suite.coder.EnterField("outer")
+	serial.CodeField(suite.coder, "second", &second)
+	suite.coder.LeaveField()
+
+	var decodeErr *serial.DecodeError
+	require.True(suite.T(), errors.As(suite.coder.FirstError(), &decodeErr), "decode error expected")
+	assert.Equal(suite.T(), uint32(2), decodeErr.Offset, "offset mismatch")
+	assert.Equal(suite.T(), []string{"outer", "second"}, decodeErr.Path, "path mismatch")
+	assert.True(suite.T(), errors.Is(decodeErr, io.ErrUnexpectedEOF), "cause should be wrapped")
+}
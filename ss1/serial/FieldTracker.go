@@ -0,0 +1,21 @@
+package serial
+
+// FieldTracker is implemented by coders that keep track of the fields being coded.
+// Codable implementations report the fields they code so that errors can be attributed to them.
+type FieldTracker interface {
+	// EnterField marks the begin of coding the named field.
+	EnterField(name string)
+	// LeaveField marks the end of coding the most recently entered field.
+	LeaveField()
+}
+
+// CodeField serializes given value as the named field.
+// The name is reported to the coder if it is a FieldTracker.
+func CodeField(coder Coder, name string, value interface{}) {
+	tracker, isTracker := coder.(FieldTracker)
+	if isTracker {
+		tracker.EnterField(name)
+		defer tracker.LeaveField()
+	}
+	coder.Code(value)
+}
@@ -19,8 +19,9 @@ func (coder *PositioningDecoder) SetCurPos(offset uint32) {
 	if coder.firstError != nil {
 		return
 	}
-	_, coder.firstError = coder.seeker.Seek(int64(offset), io.SeekStart)
-	if coder.firstError != nil {
+	_, err := coder.seeker.Seek(int64(offset), io.SeekStart)
+	if err != nil {
+		coder.setError(offset, err)
 		return
 	}
 	coder.offset = offset
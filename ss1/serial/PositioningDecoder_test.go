@@ -40,7 +40,7 @@ func TestPositioningDecoderFirstErrorBySetCurPos(t *testing.T) {
 	target.errorOnNextCall = true
 	coder.SetCurPos(0)
 
-	assert.EqualError(t, coder.FirstError(), "errorBuffer on call number 2")
+	assert.EqualError(t, coder.FirstError(), "errorBuffer on call number 2 at offset 0x0")
 }
 
 func TestPositioningDecoderSetCurPosDoesNothingOnPreviousError(t *testing.T) {
@@ -52,7 +52,7 @@ func TestPositioningDecoderSetCurPosDoesNothingOnPreviousError(t *testing.T) {
 	coder.SetCurPos(0)
 
 	assert.Equal(t, target.callCounter, 1)
-	assert.EqualError(t, coder.FirstError(), "errorBuffer on call number 1")
+	assert.EqualError(t, coder.FirstError(), "errorBuffer on call number 1 at offset 0x0")
 }
 
 func TestCurPosIsNotChangedOnSetError(t *testing.T) {
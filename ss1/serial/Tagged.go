@@ -0,0 +1,421 @@
+package serial
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1"
+)
+
+const (
+	errNotAStructPointer ss1.StringError = "value is not a pointer to a struct"
+	errUnsupportedType   ss1.StringError = "unsupported type"
+	errInvalidTag        ss1.StringError = "invalid tag"
+	errMissingSize       ss1.StringError = "missing size"
+	errCountMismatch     ss1.StringError = "length does not match count"
+	errValueOutOfRange   ss1.StringError = "value out of range for its size"
+	errStringTooLong     ss1.StringError = "string too long for its size"
+)
+
+// maxDecodeChunk limits the number of slice entries that are allocated ahead of decoding them.
+// The count of a slice is taken from the data, so the slice grows only as far as there is data.
+const maxDecodeChunk = 1024
+
+// TagError is reported for fields that can not be serialized as they are declared.
+type TagError struct {
+	Field string
+	Err   error
+}
+
+// Error returns the textual representation.
+func (err TagError) Error() string {
+	return fmt.Sprintf("field %s: %v", err.Field, err.Err)
+}
+
+// Unwrap returns the nested error.
+func (err TagError) Unwrap() error {
+	return err.Err
+}
+
+// failingCoder is implemented by coders that can take up errors of others.
+type failingCoder interface {
+	fail(err error)
+}
+
+func (coder *Encoder) fail(err error) {
+	if coder.firstError == nil {
+		coder.firstError = err
+	}
+}
+
+func (coder *Decoder) fail(err error) {
+	coder.setError(coder.offset, err)
+}
+
+// Tagged returns a Codable for given pointer to a struct. The struct is serialized field by field,
+// in order of declaration, as described by the struct tags with the key "serial".
+// The tag value is a comma separated list of the following options:
+//
+//	"-"           skips the field.
+//	"size=N"      for int and uint fields, the field is serialized with N bytes (1, 2, 4, or 8).
+//	              For strings, the field is serialized as N bytes, padded with zeroes.
+//	              For slices, the field has N entries.
+//	"count=Field" for slices, the number of entries is taken from the named integer field, which must come before.
+//	"big"         numbers are serialized in big endian order. The default is little endian.
+//	"pad=N"       the field is followed by N bytes of padding.
+//
+// Nested structs are serialized the same way, unless they implement Codable themselves.
+// Fields named "_" are serialized as padding of their size. Other unexported fields are skipped.
+// When decoding, slices are resized to their count. When encoding, their length must match the count,
+// and integers must fit into their size.
+//
+// Errors in the declaration, and of values that can not be encoded, are reported via the FirstError()
+// of the coder for the coders of this package. Use CodeTagged() to receive them for other coders.
+func Tagged(value interface{}) Codable {
+	return taggedCodable{value: value}
+}
+
+// CodeTagged serializes given pointer to a struct with given coder, as described by Tagged().
+// This function can be used to implement the Codable interface for structs.
+// It returns errors in the declaration, and of values that can not be encoded. Errors of the coder itself
+// are provided via its FirstError().
+func CodeTagged(coder Coder, value interface{}) error {
+	if coder.FirstError() != nil {
+		return nil
+	}
+	err := codeTagged(coder, value)
+	if failing, canFail := coder.(failingCoder); canFail && (err != nil) {
+		failing.fail(err)
+	}
+	return err
+}
+
+type taggedCodable struct {
+	value interface{}
+}
+
+func (codable taggedCodable) Code(coder Coder) {
+	_ = CodeTagged(coder, codable.value)
+}
+
+func codeTagged(coder Coder, value interface{}) error {
+	reflected := reflect.ValueOf(value)
+	if (reflected.Kind() != reflect.Ptr) || (reflected.Elem().Kind() != reflect.Struct) {
+		return errNotAStructPointer
+	}
+	return codeStruct(coder, reflected.Elem())
+}
+
+// isDecoding returns true if the coder reads the values, rather than writing them.
+func isDecoding(coder Coder) bool {
+	_, isReader := coder.(io.Reader)
+	return isReader
+}
+
+type fieldSpec struct {
+	sized     bool
+	size      int
+	count     string
+	bigEndian bool
+	padding   int
+}
+
+func parseFieldSpec(tag string) (spec fieldSpec, skip bool, err error) {
+	if len(tag) == 0 {
+		return
+	}
+	for _, option := range strings.Split(tag, ",") {
+		key, argument := option, ""
+		if separator := strings.Index(option, "="); separator >= 0 {
+			key, argument = option[:separator], option[separator+1:]
+		}
+		switch key {
+		case "-":
+			skip = true
+		case "big":
+			spec.bigEndian = true
+		case "size":
+			spec.sized = true
+			spec.size, err = strconv.Atoi(argument)
+		case "pad":
+			spec.padding, err = strconv.Atoi(argument)
+		case "count":
+			spec.count = argument
+		default:
+			err = errInvalidTag
+		}
+		if (err == nil) && ((spec.size < 0) || (spec.padding < 0) || ((key == "count") && (len(argument) == 0))) {
+			err = errInvalidTag
+		}
+		if err != nil {
+			return spec, skip, errInvalidTag
+		}
+	}
+	return
+}
+
+func codeStruct(coder Coder, value reflect.Value) error {
+	valueType := value.Type()
+	for index := 0; index < valueType.NumField(); index++ {
+		if coder.FirstError() != nil {
+			return nil
+		}
+		field := valueType.Field(index)
+		spec, skip, err := parseFieldSpec(field.Tag.Get("serial"))
+		if err != nil {
+			return TagError{Field: field.Name, Err: err}
+		}
+		if skip {
+			continue
+		}
+		if field.Name == "_" {
+			codePadding(coder, int(field.Type.Size()))
+		} else if field.PkgPath == "" {
+			err = codeTrackedField(coder, field.Name, value, value.Field(index), spec)
+			if err != nil {
+				return TagError{Field: field.Name, Err: err}
+			}
+		}
+		codePadding(coder, spec.padding)
+	}
+	return nil
+}
+
+func codeTrackedField(coder Coder, name string, parent reflect.Value, field reflect.Value, spec fieldSpec) error {
+	tracker, isTracker := coder.(FieldTracker)
+	if isTracker {
+		tracker.EnterField(name)
+		defer tracker.LeaveField()
+	}
+	return codeField(coder, parent, field, spec)
+}
+
+func codeField(coder Coder, parent reflect.Value, field reflect.Value, spec fieldSpec) error {
+	if (field.Kind() == reflect.Slice) && (len(spec.count) > 0) {
+		countField := parent.FieldByName(spec.count)
+		count, isInteger := integerOf(countField)
+		if !isInteger || (count < 0) {
+			return errInvalidTag
+		}
+		spec.sized = true
+		spec.size = int(count)
+	}
+	return codeValue(coder, field, spec)
+}
+
+func integerOf(value reflect.Value) (int64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+func codeValue(coder Coder, value reflect.Value, spec fieldSpec) error {
+	if codable, isCodable := value.Addr().Interface().(Codable); isCodable {
+		coder.Code(codable)
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return codeStruct(coder, value)
+	case reflect.Array:
+		return codeElements(coder, value, spec)
+	case reflect.Slice:
+		if !spec.sized {
+			return errMissingSize
+		}
+		if isDecoding(coder) {
+			return decodeSlice(coder, value, spec)
+		}
+		if value.Len() != spec.size {
+			return errCountMismatch
+		}
+		return codeElements(coder, value, spec)
+	case reflect.String:
+		return codeString(coder, value, spec)
+	case reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return codeNumber(coder, value, spec)
+	case reflect.Int, reflect.Uint:
+		if !spec.sized {
+			return errMissingSize
+		}
+		return codeNumber(coder, value, spec)
+	default:
+		return errUnsupportedType
+	}
+}
+
+func codeElements(coder Coder, value reflect.Value, spec fieldSpec) error {
+	if value.Type().Elem().Kind() == reflect.Uint8 {
+		if value.Kind() == reflect.Array {
+			coder.Code(value.Addr().Interface())
+		} else {
+			coder.Code(value.Interface())
+		}
+		return nil
+	}
+	elementSpec := fieldSpec{bigEndian: spec.bigEndian}
+	for index := 0; (index < value.Len()) && (coder.FirstError() == nil); index++ {
+		err := codeValue(coder, value.Index(index), elementSpec)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeSlice decodes the entries of a slice in chunks, so that only as many entries are allocated
+// as there is data for.
+func decodeSlice(coder Coder, value reflect.Value, spec fieldSpec) error {
+	decoded := reflect.MakeSlice(value.Type(), 0, 0)
+	for (decoded.Len() < spec.size) && (coder.FirstError() == nil) {
+		offset := decoded.Len()
+		chunkSize := spec.size - offset
+		if chunkSize > maxDecodeChunk {
+			chunkSize = maxDecodeChunk
+		}
+		chunk := reflect.MakeSlice(value.Type(), chunkSize, chunkSize)
+		if offset < value.Len() {
+			reflect.Copy(chunk, value.Slice(offset, value.Len()))
+		}
+		err := codeElements(coder, chunk, spec)
+		if err != nil {
+			return err
+		}
+		decoded = reflect.AppendSlice(decoded, chunk)
+	}
+	value.Set(decoded)
+	return nil
+}
+
+func codeString(coder Coder, value reflect.Value, spec fieldSpec) error {
+	if !spec.sized {
+		return errMissingSize
+	}
+	if !isDecoding(coder) && (len(value.String()) > spec.size) {
+		return errStringTooLong
+	}
+	buffer := make([]byte, spec.size)
+	copy(buffer, value.String())
+	coder.Code(buffer)
+	if isDecoding(coder) {
+		if end := bytes.IndexByte(buffer, 0x00); end >= 0 {
+			buffer = buffer[:end]
+		}
+		value.SetString(string(buffer))
+	}
+	return nil
+}
+
+func codeNumber(coder Coder, value reflect.Value, spec fieldSpec) error {
+	naturalSize := int(value.Type().Size())
+	size := naturalSize
+	if spec.sized {
+		if (value.Kind() == reflect.Float32) || (value.Kind() == reflect.Float64) || (value.Kind() == reflect.Bool) {
+			return errInvalidTag
+		}
+		size = spec.size
+	}
+	if (size != 1) && (size != 2) && (size != 4) && (size != 8) {
+		return errInvalidTag
+	}
+	if !isDecoding(coder) && !fitsSize(value, size) {
+		return errValueOutOfRange
+	}
+	if !spec.bigEndian && (size == naturalSize) && (value.Kind() != reflect.Int) && (value.Kind() != reflect.Uint) {
+		coder.Code(value.Addr().Interface())
+		return nil
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if spec.bigEndian {
+		order = binary.BigEndian
+	}
+	buffer := make([]byte, 8)
+	bits := bitsOf(value)
+	if order == binary.BigEndian {
+		order.PutUint64(buffer, bits<<(uint(8-size)*8))
+	} else {
+		order.PutUint64(buffer, bits)
+	}
+	buffer = buffer[:size]
+	coder.Code(buffer)
+	if isDecoding(coder) {
+		full := make([]byte, 8)
+		if order == binary.BigEndian {
+			copy(full[8-size:], buffer)
+		} else {
+			copy(full, buffer)
+		}
+		setBits(value, order.Uint64(full), size)
+	}
+	return nil
+}
+
+func fitsSize(value reflect.Value, size int) bool {
+	if size >= 8 {
+		return true
+	}
+	bits := uint(size) * 8
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		limit := int64(1) << (bits - 1)
+		return (value.Int() >= -limit) && (value.Int() < limit)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint() < (uint64(1) << bits)
+	default:
+		return true
+	}
+}
+
+func bitsOf(value reflect.Value) uint64 {
+	switch value.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Float32:
+		return uint64(math.Float32bits(float32(value.Float())))
+	case reflect.Float64:
+		return math.Float64bits(value.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(value.Int())
+	default:
+		return value.Uint()
+	}
+}
+
+func setBits(value reflect.Value, bits uint64, size int) {
+	switch value.Kind() {
+	case reflect.Bool:
+		value.SetBool(bits != 0)
+	case reflect.Float32:
+		value.SetFloat(float64(math.Float32frombits(uint32(bits))))
+	case reflect.Float64:
+		value.SetFloat(math.Float64frombits(bits))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		shift := uint(8-size) * 8
+		value.SetInt(int64(bits<<shift) >> shift)
+	default:
+		value.SetUint(bits)
+	}
+}
+
+func codePadding(coder Coder, size int) {
+	if size > 0 {
+		coder.Code(make([]byte, size))
+	}
+}
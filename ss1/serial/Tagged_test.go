@@ -0,0 +1,226 @@
+package serial_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/serial"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type taggedHeader struct {
+	Magic   uint16 `serial:"big"`
+	Version int    `serial:"size=1"`
+	Name    string `serial:"size=6"`
+	_       [2]byte
+	Count   uint8
+	Entries []taggedEntry `serial:"count=Count"`
+	Scale   float32       `serial:"pad=1"`
+	Cached  int           `serial:"-"`
+	hidden  int
+}
+
+type taggedEntry struct {
+	Value int16
+	Flag  bool
+}
+
+func TestTaggedEncodesFields(t *testing.T) {
+	header := taggedHeader{
+		Magic:   0x1234,
+		Version: 3,
+		Name:    "abc",
+		Count:   2,
+		Entries: []taggedEntry{{Value: -2, Flag: true}, {Value: 0x0102}},
+		Scale:   1.0,
+		Cached:  10,
+		hidden:  20,
+	}
+	buf := bytes.NewBuffer(nil)
+	encoder := serial.NewEncoder(buf)
+	encoder.Code(serial.Tagged(&header))
+
+	require.Nil(t, encoder.FirstError(), "no error expected")
+	assert.Equal(t, []byte{
+		0x12, 0x34,
+		0x03,
+		'a', 'b', 'c', 0x00, 0x00, 0x00,
+		0x00, 0x00,
+		0x02,
+		0xFE, 0xFF, 0x01,
+		0x02, 0x01, 0x00,
+		0x00, 0x00, 0x80, 0x3F,
+		0x00,
+	}, buf.Bytes())
+}
+
+func TestTaggedDecodesWhatWasEncoded(t *testing.T) {
+	original := taggedHeader{
+		Magic:   0xABCD,
+		Version: -1,
+		Name:    "name",
+		Count:   3,
+		Entries: []taggedEntry{{Value: 1}, {Value: 2, Flag: true}, {Value: -3}},
+		Scale:   -0.5,
+	}
+	buf := bytes.NewBuffer(nil)
+	serial.NewEncoder(buf).Code(serial.Tagged(&original))
+
+	var decoded taggedHeader
+	decoder := serial.NewDecoder(bytes.NewReader(buf.Bytes()))
+	decoder.Code(serial.Tagged(&decoded))
+
+	require.Nil(t, decoder.FirstError(), "no error expected")
+	assert.Equal(t, original, decoded)
+}
+
+func TestTaggedFailsEncodingSlicesThatDoNotMatchTheirCount(t *testing.T) {
+	header := taggedHeader{Count: 1, Entries: []taggedEntry{{Value: 1}, {Value: 2}}}
+	encoder := serial.NewEncoder(bytes.NewBuffer(nil))
+	encoder.Code(serial.Tagged(&header))
+
+	var tagErr serial.TagError
+	require.True(t, errors.As(encoder.FirstError(), &tagErr), "tag error expected")
+	assert.Equal(t, "Entries", tagErr.Field)
+	assert.Equal(t, []taggedEntry{{Value: 1}, {Value: 2}}, header.Entries, "slice should not be changed")
+}
+
+func TestTaggedFailsEncodingIntegersThatDoNotFitTheirSize(t *testing.T) {
+	header := taggedHeader{Version: 0x100}
+	encoder := serial.NewEncoder(bytes.NewBuffer(nil))
+	encoder.Code(serial.Tagged(&header))
+
+	var tagErr serial.TagError
+	require.True(t, errors.As(encoder.FirstError(), &tagErr), "tag error expected")
+	assert.Equal(t, "Version", tagErr.Field)
+}
+
+func TestTaggedFailsEncodingStringsThatDoNotFitTheirSize(t *testing.T) {
+	header := taggedHeader{Name: "toolong"}
+	encoder := serial.NewEncoder(bytes.NewBuffer(nil))
+	encoder.Code(serial.Tagged(&header))
+
+	var tagErr serial.TagError
+	require.True(t, errors.As(encoder.FirstError(), &tagErr), "tag error expected")
+	assert.Equal(t, "Name", tagErr.Field)
+}
+
+func TestTaggedReplacesAllValuesWhenDecoding(t *testing.T) {
+	header := taggedHeader{Magic: 0x1234, Version: 0x101, Name: "abcdefgh"}
+	decoder := serial.NewDecoder(bytes.NewReader([]byte{
+		0x12, 0x34, 0x01, 'a', 'b', 'c', 'd', 'e', 'f', 0, 0,
+		0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00,
+	}))
+	decoder.Code(serial.Tagged(&header))
+
+	require.Nil(t, decoder.FirstError(), "no error expected")
+	assert.Equal(t, 1, header.Version, "version mismatch")
+	assert.Equal(t, "abcdef", header.Name, "name mismatch")
+}
+
+func TestTaggedResizesSlicesToCountWhenDecoding(t *testing.T) {
+	header := taggedHeader{Entries: []taggedEntry{{Value: 1}, {Value: 2}}}
+	decoder := serial.NewDecoder(bytes.NewReader([]byte{
+		0x12, 0x34, 0x01, 'a', 'b', 'c', 0, 0, 0, 0, 0,
+		0x01, 0x05, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x00, 0x00,
+	}))
+	decoder.Code(serial.Tagged(&header))
+
+	require.Nil(t, decoder.FirstError(), "no error expected")
+	assert.Equal(t, []taggedEntry{{Value: 5, Flag: true}}, header.Entries)
+}
+
+func TestTaggedDecodesCountsLargerThanTheData(t *testing.T) {
+	var value struct {
+		Count uint32
+		Data  []byte   `serial:"count=Count"`
+		Words []uint16 `serial:"count=Count"`
+	}
+	decoder := serial.NewDecoder(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x01, 0x02}))
+	decoder.Code(serial.Tagged(&value))
+
+	var decodeErr *serial.DecodeError
+	require.True(t, errors.As(decoder.FirstError(), &decodeErr), "decode error expected")
+	assert.Equal(t, []string{"Data"}, decodeErr.Path, "path mismatch")
+}
+
+func TestTaggedWorksWithPositioningCoder(t *testing.T) {
+	original := taggedEntry{Value: 0x0304, Flag: true}
+	store := serial.NewByteStore()
+	encoder := serial.NewPositioningEncoder(store)
+	encoder.SetCurPos(2)
+	serial.CodeTagged(encoder, &original)
+	require.Nil(t, encoder.FirstError(), "no error expected encoding")
+
+	var decoded taggedEntry
+	decoder := serial.NewPositioningDecoder(serial.NewByteStoreFromData(store.Data()))
+	decoder.SetCurPos(2)
+	serial.CodeTagged(decoder, &decoded)
+	require.Nil(t, decoder.FirstError(), "no error expected decoding")
+	assert.Equal(t, original, decoded)
+}
+
+func TestTaggedReportsInvalidDeclarations(t *testing.T) {
+	tt := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "int without size", value: &struct{ Value int }{}},
+		{name: "slice without size", value: &struct{ Values []byte }{}},
+		{name: "unknown option", value: &struct {
+			Value byte `serial:"unknown"`
+		}{}},
+		{name: "invalid size", value: &struct {
+			Value int `serial:"size=3"`
+		}{}},
+		{name: "unsupported type", value: &struct{ Value map[int]int }{}},
+	}
+	for _, tc := range tt {
+		td := tc
+		t.Run(td.name, func(t *testing.T) {
+			encoder := serial.NewEncoder(bytes.NewBuffer(nil))
+			encoder.Code(serial.Tagged(td.value))
+			var tagErr serial.TagError
+			assert.True(t, errors.As(encoder.FirstError(), &tagErr), "tag error expected")
+		})
+	}
+}
+
+func TestCodeTaggedReturnsErrorsForOtherCoders(t *testing.T) {
+	coder := &plainCoder{}
+	err := serial.CodeTagged(coder, &struct{ Value int }{})
+
+	var tagErr serial.TagError
+	assert.True(t, errors.As(err, &tagErr), "tag error expected")
+}
+
+type plainCoder struct{}
+
+func (coder *plainCoder) FirstError() error {
+	return nil
+}
+
+func (coder *plainCoder) Code(value interface{}) {
+}
+
+func TestTaggedRequiresStructPointer(t *testing.T) {
+	encoder := serial.NewEncoder(bytes.NewBuffer(nil))
+	encoder.Code(serial.Tagged(taggedEntry{}))
+	assert.NotNil(t, encoder.FirstError(), "error expected")
+}
+
+func TestTaggedReportsPathOfFailingField(t *testing.T) {
+	var header taggedHeader
+	decoder := serial.NewDecoder(bytes.NewReader([]byte{0x12, 0x34, 0x01, 'a', 'b', 'c', 0, 0, 0, 0, 0, 0x02, 0x01, 0x00, 0x00}))
+	decoder.Code(serial.Tagged(&header))
+
+	var decodeErr *serial.DecodeError
+	require.True(t, errors.As(decoder.FirstError(), &decodeErr), "decode error expected")
+	assert.Equal(t, []string{"Entries", "Value"}, decodeErr.Path, "path mismatch")
+	assert.Equal(t, uint32(15), decodeErr.Offset, "offset mismatch")
+}
@@ -0,0 +1,248 @@
+package rle
+
+import (
+	"io"
+	"math"
+)
+
+// CompressOptimal compresses the given byte array into the given writer, producing the smallest possible output.
+// The optional reference array is used as a delta basis, the same as with Compress().
+//
+// While Compress() decides greedily which operation to use next, this function considers all possible
+// sequences of skips, constant runs, and raw copies. It requires more time and memory, and is meant for
+// data that is stored, rather than data that is encoded frequently.
+func CompressOptimal(writer io.Writer, data []byte, reference []byte) error {
+	for _, op := range optimalOperations(data, reference) {
+		err := op.write(writer, data)
+		if err != nil {
+			return err
+		}
+	}
+	return writeExtended(writer, 0x0000)
+}
+
+type operationKind byte
+
+const (
+	shortSkip operationKind = iota
+	extendedSkip
+	shortConstant
+	extendedConstant
+	shortRaw
+	extendedRaw
+)
+
+// operationLimits describe the encoding of each operation: The range of lengths it supports,
+// the bytes it requires in the stream for itself, and whether it is followed by the bytes it copies.
+var operationLimits = [...]struct {
+	minLength int
+	maxLength int
+	cost      int
+	isRaw     bool
+}{
+	shortSkip:        {minLength: 1, maxLength: 0x7F, cost: 1},
+	extendedSkip:     {minLength: 1, maxLength: 0x7FFF, cost: 3},
+	shortConstant:    {minLength: 1, maxLength: 0xFF, cost: 3},
+	extendedConstant: {minLength: 0x100, maxLength: 0x3FFF, cost: 4},
+	shortRaw:         {minLength: 1, maxLength: 0x7F, cost: 1, isRaw: true},
+	extendedRaw:      {minLength: 1, maxLength: 0x3FFF, cost: 3, isRaw: true},
+}
+
+type operation struct {
+	kind   operationKind
+	start  int
+	length int
+}
+
+func (op operation) write(writer io.Writer, data []byte) error {
+	var err error
+	switch op.kind {
+	case shortSkip:
+		_, err = writer.Write([]byte{byte(0x80 + op.length)})
+	case extendedSkip:
+		err = writeExtended(writer, uint16(op.length))
+	case shortConstant:
+		_, err = writer.Write([]byte{0x00, byte(op.length), data[op.start]})
+	case extendedConstant:
+		err = writeExtended(writer, 0xC000+uint16(op.length), data[op.start])
+	case shortRaw:
+		_, err = writer.Write([]byte{byte(op.length)})
+		if err == nil {
+			_, err = writer.Write(data[op.start : op.start+op.length])
+		}
+	case extendedRaw:
+		err = writeExtended(writer, 0x8000+uint16(op.length), data[op.start:op.start+op.length]...)
+	}
+	return err
+}
+
+// optimalOperations determines the sequence of operations that encodes the data with the least amount of bytes.
+// The cost to encode the data from each position to the end is calculated backwards from the end.
+// As the cost of each operation is either fixed, or grows linearly with its length, the best follow-up position
+// for an operation is the one with the minimum cost (plus position) within the range the operation can reach.
+func optimalOperations(data []byte, reference []byte) []operation {
+	end := len(data)
+	identicalEnd := make([]int, end+1)
+	constantEnd := make([]int, end+1)
+	identicalEnd[end] = end
+	constantEnd[end] = end
+	for index := end - 1; index >= 0; index-- {
+		identicalEnd[index] = index
+		if (index < len(reference) && (data[index] == reference[index])) ||
+			((index >= len(reference)) && (data[index] == 0x00)) {
+			identicalEnd[index] = identicalEnd[index+1]
+		}
+		constantEnd[index] = index + 1
+		if (index+1 < end) && (data[index] == data[index+1]) {
+			constantEnd[index] = constantEnd[index+1]
+		}
+	}
+	// Trailing identical bytes need not be encoded at all.
+	unchangedFrom := end
+	for (unchangedFrom > 0) && (identicalEnd[unchangedFrom-1] == end) {
+		unchangedFrom--
+	}
+
+	costs := newMinimumTree(end + 1)
+	for index := unchangedFrom; index <= end; index++ {
+		costs.set(index, 0)
+	}
+	// The ranges of raw copies move along with the position, which allows for a faster lookup.
+	rawCosts := map[operationKind]*slidingMinimum{shortRaw: {}, extendedRaw: {}}
+	for index := end; index >= unchangedFrom; index-- {
+		for _, window := range rawCosts {
+			window.add(index, index)
+		}
+	}
+	choices := make([]operation, end)
+	for index := unchangedFrom - 1; index >= 0; index-- {
+		bestCost := math.MaxInt32
+		consider := func(kind operationKind, limit int) {
+			limits := operationLimits[kind]
+			from := index + limits.minLength
+			to := index + limits.maxLength
+			if to > limit {
+				to = limit
+			}
+			if from > to {
+				return
+			}
+			var cost, next int
+			if limits.isRaw {
+				cost, next = rawCosts[kind].minimum(to)
+				cost -= index
+			} else {
+				cost, next = costs.minimum(from, to)
+			}
+			cost += limits.cost
+			if cost < bestCost {
+				bestCost = cost
+				choices[index] = operation{kind: kind, start: index, length: next - index}
+			}
+		}
+		consider(shortSkip, identicalEnd[index])
+		consider(extendedSkip, identicalEnd[index])
+		consider(shortConstant, constantEnd[index])
+		consider(extendedConstant, constantEnd[index])
+		consider(shortRaw, end)
+		consider(extendedRaw, end)
+		costs.set(index, bestCost)
+		for _, window := range rawCosts {
+			window.add(index, bestCost+index)
+		}
+	}
+
+	var ops []operation
+	for index := 0; index < unchangedFrom; index += choices[index].length {
+		ops = append(ops, choices[index])
+	}
+	return ops
+}
+
+// minimumTree is a segment tree that provides the minimum value, and its position, within a range of positions.
+type minimumTree struct {
+	size   int
+	values []int
+	at     []int
+}
+
+func newMinimumTree(count int) *minimumTree {
+	size := 1
+	for size < count {
+		size *= 2
+	}
+	tree := &minimumTree{
+		size:   size,
+		values: make([]int, 2*size),
+		at:     make([]int, 2*size),
+	}
+	for index := range tree.values {
+		tree.values[index] = math.MaxInt32
+	}
+	for index := 0; index < size; index++ {
+		tree.at[size+index] = index
+	}
+	return tree
+}
+
+func (tree *minimumTree) set(position int, value int) {
+	node := tree.size + position
+	tree.values[node] = value
+	for node > 1 {
+		node /= 2
+		left, right := 2*node, 2*node+1
+		if tree.values[right] < tree.values[left] {
+			tree.values[node], tree.at[node] = tree.values[right], tree.at[right]
+		} else {
+			tree.values[node], tree.at[node] = tree.values[left], tree.at[left]
+		}
+	}
+}
+
+// minimum returns the minimum value, and its position, within the inclusive range of positions.
+func (tree *minimumTree) minimum(from, to int) (value int, at int) {
+	value, at = math.MaxInt32, from
+	for left, right := tree.size+from, tree.size+to+1; left < right; left, right = left/2, right/2 {
+		if (left & 1) != 0 {
+			if tree.values[left] < value {
+				value, at = tree.values[left], tree.at[left]
+			}
+			left++
+		}
+		if (right & 1) != 0 {
+			right--
+			if tree.values[right] < value {
+				value, at = tree.values[right], tree.at[right]
+			}
+		}
+	}
+	return value, at
+}
+
+// slidingMinimum provides the minimum value of a range of positions that moves towards the start.
+// Positions must be added in descending order, and the ranges must end at descending positions.
+type slidingMinimum struct {
+	entries []slidingEntry
+	oldest  int
+}
+
+type slidingEntry struct {
+	position int
+	value    int
+}
+
+func (window *slidingMinimum) add(position int, value int) {
+	for (len(window.entries) > window.oldest) && (window.entries[len(window.entries)-1].value >= value) {
+		window.entries = window.entries[:len(window.entries)-1]
+	}
+	window.entries = append(window.entries, slidingEntry{position: position, value: value})
+}
+
+// minimum returns the minimum value, and its position, of all the added positions up to the given one.
+func (window *slidingMinimum) minimum(to int) (value int, at int) {
+	for window.entries[window.oldest].position > to {
+		window.oldest++
+	}
+	entry := window.entries[window.oldest]
+	return entry.value, entry.position
+}
@@ -0,0 +1,100 @@
+package rle_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inkyblackness/hacked/ss1/serial/rle"
+)
+
+func TestCompressOptimalEmptyArrayResultsInTerminator(t *testing.T) {
+	writer := bytes.NewBuffer(nil)
+	err := rle.CompressOptimal(writer, nil, nil)
+	assert.Nil(t, err, "no error expected")
+	assert.Equal(t, []byte{0x80, 0x00, 0x00}, writer.Bytes())
+}
+
+func TestCompressOptimalWithIdenticalReference(t *testing.T) {
+	writer := bytes.NewBuffer(nil)
+	input := []byte{0xAA, 0xBB, 0xCC, 0xDD}
+	err := rle.CompressOptimal(writer, input, input)
+	assert.Nil(t, err, "no error expected")
+	assert.Equal(t, []byte{0x80, 0x00, 0x00}, writer.Bytes())
+}
+
+func TestCompressOptimalUsesShortSkipsForMediumDistances(t *testing.T) {
+	writer := bytes.NewBuffer(nil)
+	input := make([]byte, 201)
+	input[200] = 0x01
+	err := rle.CompressOptimal(writer, input, nil)
+	assert.Nil(t, err, "no error expected")
+	assert.Equal(t, 7, writer.Len(), "two short skips expected, instead of an extended one")
+	verifyRoundTrip(t, writer.Bytes(), input, nil)
+}
+
+func TestCompressOptimalCombinesShortRunsIntoRawCopies(t *testing.T) {
+	writer := bytes.NewBuffer(nil)
+	input := []byte{0x01, 0x02, 0x02, 0x02, 0x02, 0x03, 0x04}
+	err := rle.CompressOptimal(writer, input, nil)
+	assert.Nil(t, err, "no error expected")
+	assert.Equal(t, 11, writer.Len(), "no gain expected from a constant run")
+	verifyRoundTrip(t, writer.Bytes(), input, nil)
+}
+
+func TestCompressOptimalIsNeverLargerThanCompress(t *testing.T) {
+	inputs := [][]byte{
+		bytes.Repeat([]byte{0x01, 0x02, 0x02, 0x02, 0x02, 0x03}, 100),
+		bytes.Repeat([]byte{0xAA}, 0x5000),
+		append(make([]byte, 0x9000), 0x01),
+	}
+	for _, input := range inputs {
+		greedy := bytes.NewBuffer(nil)
+		optimal := bytes.NewBuffer(nil)
+		require.Nil(t, rle.Compress(greedy, input, nil), "no error expected")
+		require.Nil(t, rle.CompressOptimal(optimal, input, nil), "no error expected")
+		assert.LessOrEqual(t, optimal.Len(), greedy.Len())
+		verifyRoundTrip(t, optimal.Bytes(), input, nil)
+	}
+}
+
+func FuzzCompressRoundTrip(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, reference []byte) {
+		buf := bytes.NewBuffer(nil)
+		require.Nil(t, rle.Compress(buf, data, reference), "no error expected")
+		verifyRoundTrip(t, buf.Bytes(), data, reference)
+	})
+}
+
+func FuzzCompressOptimalRoundTrip(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, reference []byte) {
+		optimal := bytes.NewBuffer(nil)
+		require.Nil(t, rle.CompressOptimal(optimal, data, reference), "no error expected")
+		verifyRoundTrip(t, optimal.Bytes(), data, reference)
+
+		greedy := bytes.NewBuffer(nil)
+		require.Nil(t, rle.Compress(greedy, data, reference), "no error expected")
+		assert.LessOrEqual(t, optimal.Len(), greedy.Len(), "optimal compression must not be larger")
+	})
+}
+
+func addFuzzSeeds(f *testing.F) {
+	f.Add([]byte{}, []byte{})
+	f.Add([]byte{0x00, 0x00, 0x01}, []byte{})
+	f.Add([]byte{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0xFF}, []byte{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0x0A})
+	f.Add(bytes.Repeat([]byte{0x10, 0x10, 0x10, 0x10, 0x20}, 300), bytes.Repeat([]byte{0x10}, 700))
+	f.Add(append(make([]byte, 0x8100), 0x01, 0x02), []byte{0x01})
+}
+
+func verifyRoundTrip(t *testing.T, compressed []byte, data []byte, reference []byte) {
+	t.Helper()
+	output := make([]byte, len(data))
+	copy(output, reference)
+	err := rle.Decompress(bytes.NewReader(compressed), output)
+	require.Nil(t, err, "no error expected decompressing")
+	assert.Equal(t, data, output, "data mismatch")
+}
@@ -2,6 +2,7 @@ package rle_test
 
 import (
 	"bytes"
+	"io"
 	"math/rand"
 	"testing"
 	"time"
@@ -9,7 +10,19 @@ import (
 	"github.com/inkyblackness/hacked/ss1/serial/rle"
 )
 
+type compressFunc func(writer io.Writer, data []byte, reference []byte) error
+
 func benchmarkRecompression(b *testing.B, size int, chanceLimit byte, nameSuffix string, seed int64) {
+	b.Helper()
+	benchmarkRecompressionWith(b, rle.Compress, size, chanceLimit, nameSuffix, seed)
+}
+
+func benchmarkOptimalRecompression(b *testing.B, size int, chanceLimit byte, nameSuffix string, seed int64) {
+	b.Helper()
+	benchmarkRecompressionWith(b, rle.CompressOptimal, size, chanceLimit, nameSuffix, seed)
+}
+
+func benchmarkRecompressionWith(b *testing.B, compress compressFunc, size int, chanceLimit byte, nameSuffix string, seed int64) {
 	b.Helper()
 	input := make([]byte, size)
 	reference := make([]byte, size)
@@ -31,7 +44,7 @@ func benchmarkRecompression(b *testing.B, size int, chanceLimit byte, nameSuffix
 		buf := bytes.NewBuffer(nil)
 		b.StartTimer()
 
-		err := rle.Compress(buf, input, reference)
+		err := compress(buf, input, reference)
 		if err != nil {
 			b.Errorf("Failed compression for %s in run %d of seed %v", nameSuffix, run, seed)
 		}
@@ -81,3 +94,23 @@ func BenchmarkRecompression128B_128(b *testing.B) {
 func BenchmarkRecompression128B_255(b *testing.B) {
 	benchmarkRecompression(b, 128, 255, "128B,255", time.Now().UnixNano())
 }
+
+func BenchmarkOptimalRecompression64KB_10(b *testing.B) {
+	benchmarkOptimalRecompression(b, 1024*64, 10, "64KB,10", time.Now().UnixNano())
+}
+
+func BenchmarkOptimalRecompression64KB_128(b *testing.B) {
+	benchmarkOptimalRecompression(b, 1024*64, 128, "64KB,128", time.Now().UnixNano())
+}
+
+func BenchmarkOptimalRecompression1KB_10(b *testing.B) {
+	benchmarkOptimalRecompression(b, 1024, 10, "1KB,10", time.Now().UnixNano())
+}
+
+func BenchmarkOptimalRecompression1KB_128(b *testing.B) {
+	benchmarkOptimalRecompression(b, 1024, 128, "1KB,128", time.Now().UnixNano())
+}
+
+func BenchmarkOptimalRecompression128B_128(b *testing.B) {
+	benchmarkOptimalRecompression(b, 128, 128, "128B,128", time.Now().UnixNano())
+}
@@ -3,10 +3,12 @@ package world
 import (
 	"archive/zip"
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/inkyblackness/hacked/ss1/content/texture"
 	"github.com/inkyblackness/hacked/ss1/resource"
 	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/resource/unpacked"
 	"github.com/inkyblackness/hacked/ss1/serial"
 	"github.com/inkyblackness/hacked/ss1/world/ids"
 )
@@ -46,6 +49,7 @@ type fileLoader struct {
 	resultMutex sync.Mutex
 
 	allowZips bool
+	salvage   bool
 
 	result FileLoadResult
 }
@@ -53,20 +57,142 @@ type fileLoader struct {
 // FileLoadResult contains all the information of a LoadFiles attempt.
 type FileLoadResult struct {
 	FailedFiles int
-	Savegames   map[FileLocation]resource.Viewer
-	Resources   map[FileLocation]resource.Viewer
+	// Failures contains the errors of the files that could not be loaded.
+	// Decoding errors are of type *serial.DecodeError, describing where the data is broken.
+	Failures  map[FileLocation]error
+	Savegames map[FileLocation]resource.Viewer
+	Resources map[FileLocation]resource.Viewer
+	// Unpacked contains the languages of those resources that were loaded from unpacked directories.
+	Unpacked map[FileLocation]resource.Language
+	// Salvaged contains the inconsistencies of damaged resource files, as loaded by SalvageFiles().
+	// The resources of such files contain only those that were intact.
+	Salvaged map[FileLocation][]lgres.Inconsistency
+	// Hashes contains the content hashes of all loaded resource and property files.
+	// Resources loaded from unpacked directories have no hash.
+	Hashes map[FileLocation]FileHash
 
 	ObjectProperties  object.PropertiesTable
 	TextureProperties texture.PropertiesList
+
+	// archive is the path of the zip archive the files were loaded from, if any.
+	archive string
 }
 
 // LoadFiles attempts to load compatible files from the given set of filenames.
+// Damaged resource files are not loaded, they are listed as failures.
 func LoadFiles(allowZips bool, names []string) FileLoadResult {
+	return loadFiles(allowZips, false, names)
+}
+
+// SalvageFiles attempts to load compatible files from the given set of filenames, like LoadFiles().
+// From damaged resource files, the intact resources are loaded. Their inconsistencies are listed in the result.
+// Saving such resources again loses the damaged ones, so salvaging should only happen on request.
+func SalvageFiles(allowZips bool, names []string) FileLoadResult {
+	return loadFiles(allowZips, true, names)
+}
+
+// Problems describes the files that could not be loaded completely, one line per problem, ordered by file.
+// Files that failed to load are listed with their error, salvaged files with each of their inconsistencies.
+func (result FileLoadResult) Problems() []string {
+	var problems []string
+	failed := make([]FileLocation, 0, len(result.Failures))
+	for location := range result.Failures {
+		failed = append(failed, location)
+	}
+	for _, location := range sortedLocations(failed) {
+		problems = append(problems, fmt.Sprintf("%s: %v", location.path(), result.Failures[location]))
+	}
+	salvaged := make([]FileLocation, 0, len(result.Salvaged))
+	for location := range result.Salvaged {
+		salvaged = append(salvaged, location)
+	}
+	for _, location := range sortedLocations(salvaged) {
+		for _, inconsistency := range result.Salvaged[location] {
+			problems = append(problems, fmt.Sprintf("%s: salvaged, %v", location.path(), inconsistency))
+		}
+	}
+	return problems
+}
+
+// Paths returns the paths on disk that the result was loaded from, sorted. These are all files that were
+// considered, including those that failed to load, and unpacked directories.
+// For files loaded from a zip archive, this is the path of the archive.
+func (result FileLoadResult) Paths() []string {
+	if len(result.archive) > 0 {
+		return []string{result.archive}
+	}
+	unique := make(map[string]bool)
+	for location := range result.Failures {
+		unique[location.path()] = true
+	}
+	for location := range result.Savegames {
+		unique[location.path()] = true
+	}
+	for location := range result.Resources {
+		unique[location.path()] = true
+	}
+	for location := range result.Hashes {
+		unique[location.path()] = true
+	}
+	paths := make([]string, 0, len(unique))
+	for path := range unique {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func sortedLocations(locations []FileLocation) []FileLocation {
+	sort.Slice(locations, func(a, b int) bool { return locations[a].path() < locations[b].path() })
+	return locations
+}
+
+// FileProblemsError lists the problems of files that could not be loaded completely.
+// It is returned if no usable data could be loaded from such files.
+type FileProblemsError struct {
+	// Problems are those of FileLoadResult.Problems().
+	Problems []string
+}
+
+// Error lists the problems, one per line.
+func (err FileProblemsError) Error() string {
+	return "files could not be loaded:\n" + strings.Join(err.Problems, "\n")
+}
+
+// NothingLoadedError returns the error to report if nothing usable was loaded.
+// This is a FileProblemsError if files had problems, or a generic error otherwise.
+func (result FileLoadResult) NothingLoadedError() error {
+	if problems := result.Problems(); len(problems) > 0 {
+		return FileProblemsError{Problems: problems}
+	}
+	return errNoResourcesFound
+}
+
+// DamagedFileError is returned for resource files of which at least one resource is damaged.
+// Such files can be loaded with SalvageFiles().
+type DamagedFileError struct {
+	Inconsistencies []lgres.Inconsistency
+}
+
+// Error returns the first inconsistency, together with the count of the others.
+func (err DamagedFileError) Error() string {
+	if len(err.Inconsistencies) == 1 {
+		return fmt.Sprintf("damaged resource file, %v", err.Inconsistencies[0])
+	}
+	return fmt.Sprintf("damaged resource file, %v (and %d more)", err.Inconsistencies[0], len(err.Inconsistencies)-1)
+}
+
+func loadFiles(allowZips bool, salvage bool, names []string) FileLoadResult {
 	loader := fileLoader{
 		allowZips: allowZips,
+		salvage:   salvage,
 		result: FileLoadResult{
 			Resources: make(map[FileLocation]resource.Viewer),
 			Savegames: make(map[FileLocation]resource.Viewer),
+			Unpacked:  make(map[FileLocation]resource.Language),
+			Salvaged:  make(map[FileLocation][]lgres.Inconsistency),
+			Hashes:    make(map[FileLocation]FileHash),
+			Failures:  make(map[FileLocation]error),
 		},
 	}
 	loader.loadAll(names)
@@ -91,14 +217,15 @@ func (loader *fileLoader) loadList(names []string, isOnlyRequestedFile bool) {
 }
 
 func (loader *fileLoader) load(name string, isOnlyRequestedFile bool) {
+	location := FileLocation{DirPath: filepath.Dir(name), Name: filepath.Base(name)}
 	fileInfo, err := os.Stat(name)
 	if err != nil {
-		loader.markFailedFile()
+		loader.markFailedFile(location, err)
 		return
 	}
 	file, err := os.Open(name)
 	if err != nil {
-		loader.markFailedFile()
+		loader.markFailedFile(location, err)
 		return
 	}
 	defer func() {
@@ -106,7 +233,9 @@ func (loader *fileLoader) load(name string, isOnlyRequestedFile bool) {
 	}()
 
 	if fileInfo.IsDir() {
-		if isOnlyRequestedFile {
+		if unpacked.IsUnpacked(name) {
+			loader.loadUnpacked(name, isOnlyRequestedFile)
+		} else if isOnlyRequestedFile {
 			subNames, _ := file.Readdirnames(0)
 			joinedSubNames := make([]string, len(subNames))
 			for index, subName := range subNames {
@@ -123,6 +252,7 @@ func (loader *fileLoader) load(name string, isOnlyRequestedFile bool) {
 				_, _ = file.Seek(0, io.SeekStart)
 				tryDirect = true
 			} else {
+				loader.modify(func() { loader.result.archive = name })
 				loader.loadFileArchive(zipReader)
 			}
 		}
@@ -155,24 +285,30 @@ func (loader *fileLoader) loadFileArchive(zipReader *zip.Reader) {
 }
 
 func (loader *fileLoader) loadFile(name string, isOnlyStagedFile bool, file io.Reader) {
+	filename := filepath.Base(name)
+	location := FileLocation{DirPath: filepath.Dir(name), Name: filename}
 	fileData, err := ioutil.ReadAll(file)
 	if err != nil {
-		loader.markFailedFile()
+		loader.markFailedFile(location, err)
 	}
 
-	reader, err := lgres.ReaderFrom(bytes.NewReader(fileData))
-	filename := filepath.Base(name)
-	if (err == nil) && (isOnlyStagedFile || fileAllowlist.Matches(filename)) {
-		location := FileLocation{DirPath: filepath.Dir(name), Name: filename}
-		loader.modify(func() {
-			if stateView, stateErr := reader.View(ids.GameState); (stateErr == nil) && archive.IsSavegame(stateView) {
-				loader.result.Savegames[location] = reader
-			} else {
-				loader.result.Resources[location] = reader
-			}
-		})
+	isResourceFile := isOnlyStagedFile || fileAllowlist.Matches(filename)
+	if isResourceFile {
+		var reader *lgres.Reader
+		reader, err = lgres.ReaderFrom(bytes.NewReader(fileData))
+		if (err == nil) || loader.salvage {
+			reader, err = loader.intactReader(location, fileData, reader)
+		}
+		if err == nil {
+			loader.addResources(location, reader)
+		}
+	}
+	lowercaseName := strings.ToLower(filename)
+	if isResourceFile || (lowercaseName == ObjectPropertiesFilename) || (lowercaseName == TexturePropertiesFilename) {
+		hash := FileHashOf(fileData)
+		loader.modify(func() { loader.result.Hashes[location] = hash })
 	}
-	if strings.ToLower(filename) == ObjectPropertiesFilename {
+	if lowercaseName == ObjectPropertiesFilename {
 		decoder := serial.NewDecoder(bytes.NewReader(fileData))
 		properties := object.StandardPropertiesTable()
 		properties.Code(decoder)
@@ -181,7 +317,7 @@ func (loader *fileLoader) loadFile(name string, isOnlyStagedFile bool, file io.R
 			loader.modify(func() { loader.result.ObjectProperties = properties })
 		}
 	}
-	if strings.ToLower(filename) == TexturePropertiesFilename && (len(fileData) > 4) {
+	if lowercaseName == TexturePropertiesFilename && (len(fileData) > 4) {
 		decoder := serial.NewDecoder(bytes.NewReader(fileData))
 		entryCount := (len(fileData) - 4) / texture.PropertiesSize
 		properties := make(texture.PropertiesList, entryCount)
@@ -193,12 +329,74 @@ func (loader *fileLoader) loadFile(name string, isOnlyStagedFile bool, file io.R
 	}
 
 	if err != nil {
-		loader.markFailedFile()
+		loader.markFailedFile(location, err)
+	}
+}
+
+// intactReader verifies the resources of given reader. If resources are damaged, they are either salvaged,
+// leaving out the damaged ones, or the file is rejected with a DamagedFileError.
+// Unless salvaging is requested, only the layout of the file is verified, and the given reader is kept, which
+// reads the resources on demand. The reader is nil if the file could not be read as a resource file.
+func (loader *fileLoader) intactReader(location FileLocation, fileData []byte, reader *lgres.Reader) (*lgres.Reader, error) {
+	verify := lgres.VerifyLayoutOf
+	if loader.salvage {
+		verify = lgres.SalvageReaderFrom
+	}
+	intact, inconsistencies, err := verify(bytes.NewReader(fileData), int64(len(fileData)))
+	if err != nil {
+		return nil, err
+	}
+	if (reader != nil) && (len(intact.IDs()) == len(uniqueIDsOf(reader))) {
+		return reader, nil
+	}
+	if !loader.salvage {
+		return nil, DamagedFileError{Inconsistencies: inconsistencies}
+	}
+	loader.modify(func() { loader.result.Salvaged[location] = inconsistencies })
+	return intact, nil
+}
+
+// uniqueIDsOf returns the identifiers of the reader, without duplicate directory entries.
+func uniqueIDsOf(reader *lgres.Reader) map[resource.ID]struct{} {
+	unique := make(map[resource.ID]struct{})
+	for _, id := range reader.IDs() {
+		unique[id] = struct{}{}
+	}
+	return unique
+}
+
+func (loader *fileLoader) loadUnpacked(name string, isOnlyStagedFile bool) {
+	filename := filepath.Base(name)
+	if !isOnlyStagedFile && !fileAllowlist.Matches(filename) {
+		return
+	}
+	location := FileLocation{DirPath: filepath.Dir(name), Name: filename}
+	localized, err := unpacked.ReadLocalized(name)
+	if err != nil {
+		loader.markFailedFile(location, err)
+		return
 	}
+	loader.addResources(location, localized.Viewer)
+	loader.modify(func() { loader.result.Unpacked[location] = localized.Language })
 }
 
-func (loader *fileLoader) markFailedFile() {
-	loader.modify(func() { loader.result.FailedFiles++ })
+func (loader *fileLoader) addResources(location FileLocation, viewer resource.Viewer) {
+	loader.modify(func() {
+		if stateView, stateErr := viewer.View(ids.GameState); (stateErr == nil) && archive.IsSavegame(stateView) {
+			loader.result.Savegames[location] = viewer
+		} else {
+			loader.result.Resources[location] = viewer
+		}
+	})
+}
+
+func (loader *fileLoader) markFailedFile(location FileLocation, err error) {
+	loader.modify(func() {
+		loader.result.FailedFiles++
+		if _, known := loader.result.Failures[location]; !known {
+			loader.result.Failures[location] = err
+		}
+	})
 }
 
 func (loader *fileLoader) modify(modifier func()) {
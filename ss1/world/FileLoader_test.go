@@ -0,0 +1,257 @@
+package world_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/resource/unpacked"
+	"github.com/inkyblackness/hacked/ss1/serial"
+	"github.com/inkyblackness/hacked/ss1/world"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFilesReadsUnpackedResources(t *testing.T) {
+	modDir := t.TempDir()
+	store := aStoreWithTexts()
+	err := unpacked.WriteLocalized(filepath.Join(modDir, "gerstrng.res"), resource.LangGerman, store)
+	require.Nil(t, err, "no error expected writing")
+
+	result := world.LoadFiles(false, []string{modDir})
+
+	location := world.FileLocation{DirPath: modDir, Name: "gerstrng.res"}
+	require.Contains(t, result.Resources, location, "resources expected")
+	assert.Equal(t, resource.LangGerman, result.Unpacked[location])
+	assert.Equal(t, 0, result.FailedFiles)
+}
+
+func TestLoadFilesOfUnpackedResourcesIsIdenticalToResourceFile(t *testing.T) {
+	resDir := t.TempDir()
+	unpackedDir := t.TempDir()
+	store := aStoreWithTexts()
+	resFile, err := os.Create(filepath.Join(resDir, "cybstrng.res"))
+	require.Nil(t, err, "no error expected creating file")
+	err = lgres.Write(resFile, store)
+	require.Nil(t, err, "no error expected writing file")
+	_ = resFile.Close()
+	err = unpacked.WriteLocalized(filepath.Join(unpackedDir, "cybstrng.res"), resource.LangDefault, store)
+	require.Nil(t, err, "no error expected writing unpacked")
+
+	fromFile := world.LoadFiles(false, []string{resDir})
+	fromUnpacked := world.LoadFiles(false, []string{unpackedDir})
+
+	assert.Equal(t,
+		serialized(t, fromFile.Resources[world.FileLocation{DirPath: resDir, Name: "cybstrng.res"}]),
+		serialized(t, fromUnpacked.Resources[world.FileLocation{DirPath: unpackedDir, Name: "cybstrng.res"}]))
+}
+
+func TestLoadFilesIgnoresUnknownUnpackedDirectoriesInMod(t *testing.T) {
+	modDir := t.TempDir()
+	err := unpacked.Write(filepath.Join(modDir, "other.res"), aStoreWithTexts())
+	require.Nil(t, err, "no error expected writing")
+
+	result := world.LoadFiles(false, []string{modDir})
+
+	assert.Empty(t, result.Resources, "no resources expected")
+}
+
+func TestLoadFilesProvidesPathsOfLoadedFiles(t *testing.T) {
+	modDir := t.TempDir()
+	err := unpacked.WriteLocalized(filepath.Join(modDir, "gerstrng.res"), resource.LangGerman, aStoreWithTexts())
+	require.Nil(t, err, "no error expected writing")
+	err = ioutil.WriteFile(filepath.Join(modDir, "cybstrng.res"), []byte{0x01}, 0640)
+	require.Nil(t, err, "no error expected writing file")
+	err = ioutil.WriteFile(filepath.Join(modDir, "readme.txt"), []byte{0x01}, 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	result := world.LoadFiles(false, []string{modDir})
+
+	assert.Equal(t, []string{filepath.Join(modDir, "cybstrng.res"), filepath.Join(modDir, "gerstrng.res")},
+		result.Paths())
+}
+
+func TestLoadFilesDoesNotLoadDamagedResourceFiles(t *testing.T) {
+	modDir := aModWithDamagedTexts(t)
+
+	result := world.LoadFiles(false, []string{modDir})
+
+	location := world.FileLocation{DirPath: modDir, Name: "cybstrng.res"}
+	assert.NotContains(t, result.Resources, location, "no resources expected")
+	var damaged world.DamagedFileError
+	assert.True(t, errors.As(result.Failures[location], &damaged), "damaged file error expected")
+	assert.Empty(t, result.Salvaged, "nothing should be salvaged")
+}
+
+func TestLoadFilesLoadsResourceFilesWithDuplicateDirectoryEntries(t *testing.T) {
+	modDir := t.TempDir()
+	data := serialized(t, aStoreWithTexts())
+	first := directoryEntryOffset(t, data, 0x0869)
+	second := directoryEntryOffset(t, data, 0x003C)
+	require.True(t, first < second, "resource 0x0869 expected first in directory")
+	binary.LittleEndian.PutUint16(data[second:], 0x0869)
+	err := ioutil.WriteFile(filepath.Join(modDir, "cybstrng.res"), data, 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	result := world.LoadFiles(false, []string{modDir})
+
+	location := world.FileLocation{DirPath: modDir, Name: "cybstrng.res"}
+	assert.Contains(t, result.Resources, location, "resources expected")
+	assert.Empty(t, result.Failures, "no failures expected")
+}
+
+func TestLoadFilesDefersReadingCompressedData(t *testing.T) {
+	modDir := t.TempDir()
+	data := serialized(t, aStoreWithTexts())
+	entry := directoryEntryOffset(t, data, 0x003C)
+	data[entry+2] = 0xFF // unpacked length, which the compressed data can not provide
+	err := ioutil.WriteFile(filepath.Join(modDir, "cybstrng.res"), data, 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	strict := world.LoadFiles(false, []string{modDir})
+	salvaged := world.SalvageFiles(false, []string{modDir})
+
+	location := world.FileLocation{DirPath: modDir, Name: "cybstrng.res"}
+	require.Contains(t, strict.Resources, location, "resources expected")
+	assert.Len(t, strict.Resources[location].IDs(), 2, "all resources expected without salvaging")
+	require.Contains(t, salvaged.Resources, location, "resources expected")
+	assert.Equal(t, []resource.ID{0x0869}, salvaged.Resources[location].IDs(), "damaged resource should be left out")
+}
+
+func TestSalvageManifestEntryFromListsProblems(t *testing.T) {
+	modDir := aModWithDamagedTexts(t)
+
+	_, strictErr := world.NewManifestEntryFrom([]string{modDir})
+	entry, err := world.SalvageManifestEntryFrom([]string{modDir})
+
+	assert.NotNil(t, strictErr, "error expected without salvaging")
+	require.Nil(t, err, "no error expected salvaging")
+	assert.Len(t, entry.Problems, 1, "one problem expected")
+}
+
+func TestSalvageFilesSalvagesDamagedResourceFiles(t *testing.T) {
+	modDir := aModWithDamagedTexts(t)
+
+	result := world.SalvageFiles(false, []string{modDir})
+
+	location := world.FileLocation{DirPath: modDir, Name: "cybstrng.res"}
+	require.Contains(t, result.Resources, location, "resources expected")
+	assert.Equal(t, []resource.ID{0x003C}, result.Resources[location].IDs())
+	assert.Len(t, result.Salvaged[location], 1, "one inconsistency expected")
+	assert.Equal(t, 0, result.FailedFiles)
+	problems := result.Problems()
+	require.Len(t, problems, 1, "one problem expected")
+	assert.Contains(t, problems[0], "cybstrng.res", "file should be named")
+}
+
+func TestSalvageFilesDoesNotAcceptFilesWithoutResourceHeader(t *testing.T) {
+	modDir := t.TempDir()
+	data := serialized(t, aStoreWithTexts())
+	data[0] = 'X'
+	err := ioutil.WriteFile(filepath.Join(modDir, "cybstrng.res"), data, 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	result := world.SalvageFiles(false, []string{modDir})
+
+	location := world.FileLocation{DirPath: modDir, Name: "cybstrng.res"}
+	assert.NotContains(t, result.Resources, location, "no resources expected")
+	assert.Equal(t, 1, result.FailedFiles)
+}
+
+func TestLoadFilesReportsWhereDecodingFailed(t *testing.T) {
+	modDir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(modDir, "objprop.dat"), []byte{0x2D, 0x00, 0x00, 0x00, 0x01, 0x02}, 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	result := world.LoadFiles(false, []string{modDir})
+
+	location := world.FileLocation{DirPath: modDir, Name: "objprop.dat"}
+	require.Contains(t, result.Failures, location, "failure expected")
+	var decodeErr *serial.DecodeError
+	require.True(t, errors.As(result.Failures[location], &decodeErr), "decode error expected")
+	assert.Equal(t, uint32(6), decodeErr.Offset, "offset mismatch")
+	assert.Equal(t, []string{"Generic[0/0/1]"}, decodeErr.Path, "path mismatch")
+	assert.Nil(t, result.ObjectProperties, "no properties expected")
+}
+
+func TestLoadFilesDoesNotReportUnrelatedFiles(t *testing.T) {
+	modDir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(modDir, "readme.txt"), []byte("hello"), 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	result := world.LoadFiles(false, []string{modDir})
+
+	assert.Empty(t, result.Failures, "no failures expected")
+	assert.Equal(t, 0, result.FailedFiles)
+}
+
+func TestNewManifestEntryFromReportsWhereDecodingFailed(t *testing.T) {
+	modDir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(modDir, "objprop.dat"), []byte{0x2D, 0x00, 0x00, 0x00, 0x01, 0x02}, 0640)
+	require.Nil(t, err, "no error expected writing file")
+
+	_, err = world.NewManifestEntryFrom([]string{modDir})
+
+	var problemsErr world.FileProblemsError
+	require.True(t, errors.As(err, &problemsErr), "problems error expected")
+	require.Len(t, problemsErr.Problems, 1, "one problem expected")
+	assert.Contains(t, problemsErr.Problems[0], "objprop.dat", "file should be named")
+	assert.Contains(t, problemsErr.Problems[0], "at offset 0x6 in Generic[0/0/1]", "offset and path expected")
+}
+
+// aModWithDamagedTexts returns the path of a mod with a resource file, in which the block table of
+// resource 0x0869 is damaged.
+func aModWithDamagedTexts(t *testing.T) string {
+	t.Helper()
+	modDir := t.TempDir()
+	data := serialized(t, aStoreWithTexts())
+	dirOffset := binary.LittleEndian.Uint32(data[0x7C:])
+	firstResourceOffset := binary.LittleEndian.Uint32(data[dirOffset+2:])
+	binary.LittleEndian.PutUint16(data[firstResourceOffset:], 0xFFFF)
+	err := ioutil.WriteFile(filepath.Join(modDir, "cybstrng.res"), data, 0640)
+	require.Nil(t, err, "no error expected writing file")
+	return modDir
+}
+
+// directoryEntryOffset returns the offset of the directory entry of given resource.
+func directoryEntryOffset(t *testing.T, data []byte, id uint16) int {
+	t.Helper()
+	dirOffset := int(binary.LittleEndian.Uint32(data[0x7C:]))
+	count := int(binary.LittleEndian.Uint16(data[dirOffset:]))
+	for index := 0; index < count; index++ {
+		offset := dirOffset + 6 + index*10
+		if binary.LittleEndian.Uint16(data[offset:]) == id {
+			return offset
+		}
+	}
+	require.Fail(t, "directory entry not found")
+	return 0
+}
+
+func aStoreWithTexts() resource.Store {
+	var store resource.Store
+	_ = store.Put(resource.ID(0x0869), resource.Resource{
+		Properties: resource.Properties{Compound: true, ContentType: resource.Text},
+		Blocks:     resource.BlocksFrom([][]byte{{0x41, 0x00}, {}, {0x42, 0x00}}),
+	})
+	_ = store.Put(resource.ID(0x003C), resource.Resource{
+		Properties: resource.Properties{Compound: false, ContentType: resource.Text, Compressed: true},
+		Blocks:     resource.BlocksFrom([][]byte{{0x43, 0x43, 0x43, 0x43, 0x00}}),
+	})
+	return store
+}
+
+func serialized(t *testing.T, viewer resource.Viewer) []byte {
+	t.Helper()
+	require.NotNil(t, viewer, "viewer expected")
+	target := serial.NewByteStore()
+	err := lgres.Write(target, viewer)
+	require.Nil(t, err, "no error expected serializing")
+	return target.Data()
+}
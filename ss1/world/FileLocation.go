@@ -46,3 +46,7 @@ func (loc FileLocation) NestedRelativeTo(base string) string {
 	}
 	return filepath.Join(".", rel, loc.Name)
 }
+
+func (loc FileLocation) path() string {
+	return filepath.Join(loc.DirPath, loc.Name)
+}
@@ -0,0 +1,109 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileWatcher detects changes of files on disk.
+// It compares the modification time and size of the watched paths with the state they had when last checked.
+// Directories are watched with all the files they contain, except those with a skipped name.
+// A watcher can be used concurrently, for example to check for changes in the background.
+type FileWatcher struct {
+	mutex   sync.Mutex
+	states  map[string]fileState
+	skipped map[string]bool
+}
+
+type fileState struct {
+	exists    bool
+	fileCount int
+	size      int64
+	modTime   time.Time
+}
+
+// NewFileWatcher returns a new instance that watches nothing.
+// Files and directories with any of the given names are skipped within watched directories.
+func NewFileWatcher(skippedNames ...string) *FileWatcher {
+	skipped := make(map[string]bool)
+	for _, name := range skippedNames {
+		skipped[name] = true
+	}
+	return &FileWatcher{
+		states:  make(map[string]fileState),
+		skipped: skipped,
+	}
+}
+
+// Watch sets the paths to be watched.
+// Paths that are already watched keep their reference state, new paths take their current state as reference.
+// Any previously watched path that is not part of the given list is no longer watched.
+func (watcher *FileWatcher) Watch(paths []string) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+	newStates := make(map[string]fileState)
+	for _, path := range paths {
+		state, known := watcher.states[path]
+		if !known {
+			state = watcher.stateOf(path)
+		}
+		newStates[path] = state
+	}
+	watcher.states = newStates
+}
+
+// Refresh takes the current state of the given paths as new reference, should they be watched.
+// Use this function after having written the files oneself.
+func (watcher *FileWatcher) Refresh(paths ...string) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+	for _, path := range paths {
+		if _, known := watcher.states[path]; known {
+			watcher.states[path] = watcher.stateOf(path)
+		}
+	}
+}
+
+// Changed returns all watched paths that changed since the last check, in no particular order.
+// The current state of all the watched paths becomes the new reference.
+func (watcher *FileWatcher) Changed() []string {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+	var changed []string
+	for path, oldState := range watcher.states {
+		newState := watcher.stateOf(path)
+		if newState != oldState {
+			changed = append(changed, path)
+			watcher.states[path] = newState
+		}
+	}
+	return changed
+}
+
+func (watcher *FileWatcher) stateOf(path string) fileState {
+	var state fileState
+	_ = filepath.Walk(path, func(walkedPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if (walkedPath != path) && watcher.skipped[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		state.exists = true
+		if info.IsDir() {
+			return nil
+		}
+		state.fileCount++
+		state.size += info.Size()
+		if info.ModTime().After(state.modTime) {
+			state.modTime = info.ModTime()
+		}
+		return nil
+	})
+	return state
+}
@@ -0,0 +1,108 @@
+package world_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/inkyblackness/hacked/ss1/world"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatcherReportsNothingForUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := givenFile(t, filepath.Join(dir, "cybstrng.res"), []byte{0x01})
+	watcher := world.NewFileWatcher()
+	watcher.Watch([]string{filename, dir})
+
+	assert.Empty(t, watcher.Changed(), "no changes expected")
+}
+
+func TestFileWatcherReportsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := givenFile(t, filepath.Join(dir, "cybstrng.res"), []byte{0x01})
+	watcher := world.NewFileWatcher()
+	watcher.Watch([]string{filename})
+
+	givenFile(t, filename, []byte{0x01, 0x02})
+
+	assert.Equal(t, []string{filename}, watcher.Changed(), "change expected")
+	assert.Empty(t, watcher.Changed(), "change should be reported only once")
+}
+
+func TestFileWatcherReportsChangesWithinDirectories(t *testing.T) {
+	dir := t.TempDir()
+	givenFile(t, filepath.Join(dir, "cybstrng.res"), []byte{0x01})
+	watcher := world.NewFileWatcher()
+	watcher.Watch([]string{dir})
+
+	givenFile(t, filepath.Join(dir, "nested", "0001", "0000.bin"), []byte{0x02})
+
+	assert.Equal(t, []string{dir}, watcher.Changed(), "change expected")
+}
+
+func TestFileWatcherIgnoresSkippedNamesWithinDirectories(t *testing.T) {
+	dir := t.TempDir()
+	givenFile(t, filepath.Join(dir, "cybstrng.res"), []byte{0x01})
+	watcher := world.NewFileWatcher(".backup", "history.json")
+	watcher.Watch([]string{dir})
+
+	givenFile(t, filepath.Join(dir, ".backup", "cybstrng.res.1"), []byte{0x02})
+	givenFile(t, filepath.Join(dir, "history.json"), []byte{0x03})
+
+	assert.Empty(t, watcher.Changed(), "no changes expected")
+}
+
+func TestFileWatcherReportsRemovedFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := givenFile(t, filepath.Join(dir, "cybstrng.res"), []byte{0x01})
+	watcher := world.NewFileWatcher()
+	watcher.Watch([]string{filename})
+
+	err := os.Remove(filename)
+	require.Nil(t, err, "no error expected removing file")
+
+	assert.Equal(t, []string{filename}, watcher.Changed(), "change expected")
+}
+
+func TestFileWatcherIgnoresRefreshedFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := givenFile(t, filepath.Join(dir, "cybstrng.res"), []byte{0x01})
+	watcher := world.NewFileWatcher()
+	watcher.Watch([]string{filename})
+
+	givenFile(t, filename, []byte{0x01, 0x02})
+	watcher.Refresh(filename)
+
+	assert.Empty(t, watcher.Changed(), "no changes expected")
+}
+
+func TestFileWatcherKeepsReferenceOfAlreadyWatchedFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := givenFile(t, filepath.Join(dir, "cybstrng.res"), []byte{0x01})
+	otherFilename := givenFile(t, filepath.Join(dir, "gerstrng.res"), []byte{0x01})
+	watcher := world.NewFileWatcher()
+	watcher.Watch([]string{filename})
+
+	givenFile(t, filename, []byte{0x01, 0x02})
+	watcher.Watch([]string{filename, otherFilename})
+
+	assert.Equal(t, []string{filename}, watcher.Changed(), "change expected")
+}
+
+func givenFile(t *testing.T, filename string, data []byte) string {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(filename), 0750)
+	require.Nil(t, err, "no error expected creating directory")
+	err = ioutil.WriteFile(filename, data, 0640)
+	require.Nil(t, err, "no error expected writing file")
+	// Ensure a different modification time, as file systems have a limited resolution.
+	modTime := time.Now().Add(time.Duration(len(data)) * time.Second)
+	err = os.Chtimes(filename, modTime, modTime)
+	require.Nil(t, err, "no error expected changing file time")
+	return filename
+}
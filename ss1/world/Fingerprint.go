@@ -0,0 +1,239 @@
+package world
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// FileHash is the SHA-256 hash of the raw content of a file.
+type FileHash [sha256.Size]byte
+
+// FileHashOf returns the hash of given file content.
+func FileHashOf(data []byte) FileHash {
+	return sha256.Sum256(data)
+}
+
+// String returns the hexadecimal representation of the hash.
+func (hash FileHash) String() string {
+	return hex.EncodeToString(hash[:])
+}
+
+// IsZero returns true if the hash is not set. This is the case for files that were not loaded as a whole.
+func (hash FileHash) IsZero() bool {
+	return hash == FileHash{}
+}
+
+// Release identifies a published variant of the game data.
+type Release byte
+
+const (
+	// ReleaseUnknown is for data that could not be identified.
+	ReleaseUnknown Release = 0
+	// ReleaseFloppy is the original release on floppy disks.
+	ReleaseFloppy Release = 1
+	// ReleaseCD is the release on CD-ROM, with audio logs and high-res cutscenes.
+	ReleaseCD Release = 2
+	// ReleaseEnhancedEdition is the re-release that comes with a new engine.
+	ReleaseEnhancedEdition Release = 3
+)
+
+func (release Release) String() string {
+	switch release {
+	case ReleaseUnknown:
+		return "Unknown"
+	case ReleaseFloppy:
+		return "Floppy"
+	case ReleaseCD:
+		return "CD-ROM"
+	case ReleaseEnhancedEdition:
+		return "Enhanced Edition"
+	default:
+		return fmt.Sprintf("Unknown%02X", int(release))
+	}
+}
+
+// isCDBased returns true for releases that are based on the data of the CD-ROM release.
+func (release Release) isCDBased() bool {
+	return (release == ReleaseCD) || (release == ReleaseEnhancedEdition)
+}
+
+// KnownFile describes a file as it was published with a release.
+type KnownFile struct {
+	Release  Release
+	Language resource.Language
+}
+
+// FingerprintDatabase maps the hashes of files to the releases they were published with.
+type FingerprintDatabase map[FileHash]KnownFile
+
+// knownReleaseFiles contains the hashes of original files.
+// Only hashes that have been verified against original installations are to be added here.
+// Files that are not listed are identified by their content and name, as far as possible.
+// As long as it is empty, only the CD-ROM release can be guessed, and neither mismatching releases nor
+// mismatching languages are reported. The editor does not present releases until verified hashes are added.
+var knownReleaseFiles = FingerprintDatabase{}
+
+// ResourceFingerprint identifies a release by resources that only files of this release contain.
+type ResourceFingerprint struct {
+	Release Release
+	// StartID is the first ID of the resources (inclusive).
+	StartID resource.ID
+	// EndID is the last ID of the resources (exclusive).
+	EndID resource.ID
+}
+
+// Matches returns true if the viewer contains at least one of the resources.
+func (fingerprint ResourceFingerprint) Matches(viewer resource.Viewer) bool {
+	for _, id := range viewer.IDs() {
+		if (id >= fingerprint.StartID) && (id < fingerprint.EndID) {
+			return true
+		}
+	}
+	return false
+}
+
+// knownReleaseResources lists the resources that identify a release regardless of the name of their file.
+// The speech of the audio logs was only published with the CD-ROM release, and those based on it.
+var knownReleaseResources = []ResourceFingerprint{
+	{Release: ReleaseCD, StartID: ids.MailsAudioStart, EndID: ids.MailsAudioStart.Plus(47)},
+	{Release: ReleaseCD, StartID: ids.LogsAudioStart, EndID: ids.LogsAudioStart.Plus(224)},
+}
+
+// IdentifyContent refines the fingerprint of a file that is not known by its hash, using its resources.
+func IdentifyContent(fingerprint FileFingerprint, viewer resource.Viewer) FileFingerprint {
+	if fingerprint.Known || (viewer == nil) {
+		return fingerprint
+	}
+	for _, known := range knownReleaseResources {
+		if known.Matches(viewer) {
+			fingerprint.Release = known.Release
+			fingerprint.ByContent = true
+			return fingerprint
+		}
+	}
+	return fingerprint
+}
+
+// Identify returns the fingerprint for the file with given name and hash.
+// If the hash is not known, the fingerprint is based on the name alone: The language follows the typical
+// naming scheme, and files that only exist in the CD-ROM release identify that one.
+func (db FingerprintDatabase) Identify(filename string, hash FileHash) FileFingerprint {
+	fingerprint := FileFingerprint{
+		Filename: filename,
+		Hash:     hash,
+		Language: ids.LocalizeFilename(filename),
+	}
+	if known, isKnown := db[hash]; isKnown && !hash.IsZero() {
+		fingerprint.Known = true
+		fingerprint.Release = known.Release
+		fingerprint.Language = known.Language
+		return fingerprint
+	}
+	if cdOnlyFiles.Matches(filename) {
+		fingerprint.Release = ReleaseCD
+	}
+	return fingerprint
+}
+
+var cdOnlyFiles = ids.FilenameList{ids.CitALog, ids.SvgaIntr, ids.SvgaDeth, ids.SvgaEnd}
+
+// FileFingerprint describes which release a file belongs to.
+type FileFingerprint struct {
+	Filename string
+	// Hash is the hash of the file content. It is zero for files loaded from unpacked directories.
+	Hash     FileHash
+	Release  Release
+	Language resource.Language
+	// Known is set if the hash was found in the database. Otherwise, release and language are guessed.
+	Known bool
+	// ByContent is set if the release was guessed from the resources of the file, rather than its name.
+	ByContent bool
+}
+
+// String returns a short description of the fingerprint.
+func (fingerprint FileFingerprint) String() string {
+	text := fmt.Sprintf("%s (%v", fingerprint.Filename, fingerprint.Release)
+	if fingerprint.Language != resource.LangAny {
+		text += fmt.Sprintf(", %v", fingerprint.Language)
+	}
+	if fingerprint.ByContent {
+		text += ", by content"
+	} else if !fingerprint.Known && (fingerprint.Release != ReleaseUnknown) {
+		text += ", by name"
+	}
+	return text + ")"
+}
+
+// mismatches returns true if the two fingerprints identify releases that are not compatible with each other.
+// A release that was guessed by name only does not conflict with releases based on it.
+func (fingerprint FileFingerprint) mismatches(other FileFingerprint) bool {
+	if (fingerprint.Release == ReleaseUnknown) || (other.Release == ReleaseUnknown) ||
+		(fingerprint.Release == other.Release) {
+		return false
+	}
+	if !fingerprint.Known || !other.Known {
+		return !(fingerprint.Release.isCDBased() && other.Release.isCDBased())
+	}
+	return true
+}
+
+// languageWarning returns a description if the file contains data of a language that does not fit its name.
+func (fingerprint FileFingerprint) languageWarning() string {
+	if !fingerprint.Known || (fingerprint.Language == resource.LangAny) {
+		return ""
+	}
+	expected := ids.LocalizeFilename(fingerprint.Filename)
+	if (expected == resource.LangAny) || (expected == fingerprint.Language) {
+		return ""
+	}
+	return fmt.Sprintf("%s contains %v data, yet its name is for %v", fingerprint.Filename, fingerprint.Language, expected)
+}
+
+// entryFingerprint is a fingerprint together with the identifier of the manifest entry it is from.
+type entryFingerprint struct {
+	FileFingerprint
+	entryID string
+}
+
+func (fingerprint entryFingerprint) String() string {
+	return fingerprint.FileFingerprint.String() + " from " + fingerprint.entryID
+}
+
+// referenceFingerprint returns the fingerprint that best identifies the release of the list.
+// Fingerprints with known hashes take precedence over those guessed by name.
+func referenceFingerprint(list []entryFingerprint) (entryFingerprint, bool) {
+	guessed := -1
+	for index, fingerprint := range list {
+		if fingerprint.Release == ReleaseUnknown {
+			continue
+		}
+		if fingerprint.Known {
+			return fingerprint, true
+		}
+		if guessed < 0 {
+			guessed = index
+		}
+	}
+	if guessed < 0 {
+		return entryFingerprint{}, false
+	}
+	return list[guessed], true
+}
+
+func fingerprintWarnings(list []entryFingerprint) []string {
+	var warnings []string
+	reference, hasReference := referenceFingerprint(list)
+	for _, fingerprint := range list {
+		if hasReference && fingerprint.mismatches(reference.FileFingerprint) {
+			warnings = append(warnings, fmt.Sprintf("%v does not match %v", fingerprint, reference))
+		}
+		if warning := fingerprint.languageWarning(); len(warning) > 0 {
+			warnings = append(warnings, warning+" - from "+fingerprint.entryID)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,124 @@
+package world_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprintDatabaseIdentifiesKnownFiles(t *testing.T) {
+	hash := world.FileHashOf([]byte{0x01, 0x02})
+	db := world.FingerprintDatabase{hash: {Release: world.ReleaseFloppy, Language: resource.LangGerman}}
+
+	fingerprint := db.Identify("cybstrng.res", hash)
+
+	assert.True(t, fingerprint.Known, "file should be known")
+	assert.Equal(t, world.ReleaseFloppy, fingerprint.Release, "release mismatch")
+	assert.Equal(t, resource.LangGerman, fingerprint.Language, "language mismatch")
+}
+
+func TestFingerprintDatabaseIdentifiesUnknownFilesByName(t *testing.T) {
+	db := world.FingerprintDatabase{}
+	tt := []struct {
+		filename string
+		release  world.Release
+		lang     resource.Language
+	}{
+		{filename: "archive.dat", release: world.ReleaseUnknown, lang: resource.LangAny},
+		{filename: "frnstrng.res", release: world.ReleaseUnknown, lang: resource.LangFrench},
+		{filename: "CITALOG.RES", release: world.ReleaseCD, lang: resource.LangDefault},
+		{filename: "svgaend.res", release: world.ReleaseCD, lang: resource.LangAny},
+	}
+	for _, tc := range tt {
+		td := tc
+		t.Run(td.filename, func(t *testing.T) {
+			fingerprint := db.Identify(td.filename, world.FileHashOf([]byte{0x00}))
+			assert.False(t, fingerprint.Known, "file should not be known")
+			assert.Equal(t, td.release, fingerprint.Release, "release mismatch")
+			assert.Equal(t, td.lang, fingerprint.Language, "language mismatch")
+		})
+	}
+}
+
+func TestIdentifyContentRecognizesSpeechOfCDRelease(t *testing.T) {
+	store := &resource.Store{}
+	_ = store.Put(ids.LogsAudioStart.Plus(10), resource.Resource{Properties: resource.Properties{ContentType: resource.Movie}})
+	fingerprint := world.FingerprintDatabase{}.Identify("speech.res", world.FileHashOf([]byte{0x00}))
+
+	fingerprint = world.IdentifyContent(fingerprint, store)
+
+	assert.Equal(t, world.ReleaseCD, fingerprint.Release, "release mismatch")
+	assert.True(t, fingerprint.ByContent, "release should be identified by content")
+	assert.False(t, fingerprint.Known, "file should not be known")
+}
+
+func TestIdentifyContentKeepsFingerprintOfOtherContent(t *testing.T) {
+	store := &resource.Store{}
+	_ = store.Put(ids.PanelNameTexts, resource.Resource{Properties: resource.Properties{ContentType: resource.Text}})
+	fingerprint := world.FingerprintDatabase{}.Identify("cybstrng.res", world.FileHashOf([]byte{0x00}))
+
+	identified := world.IdentifyContent(fingerprint, store)
+
+	assert.Equal(t, fingerprint, identified)
+}
+
+func TestIdentifyContentPrefersKnownFiles(t *testing.T) {
+	store := &resource.Store{}
+	_ = store.Put(ids.MailsAudioStart, resource.Resource{Properties: resource.Properties{ContentType: resource.Movie}})
+	hash := world.FileHashOf([]byte{0x01})
+	db := world.FingerprintDatabase{hash: {Release: world.ReleaseEnhancedEdition, Language: resource.LangDefault}}
+
+	fingerprint := world.IdentifyContent(db.Identify("citalog.res", hash), store)
+
+	assert.Equal(t, world.ReleaseEnhancedEdition, fingerprint.Release, "release mismatch")
+	assert.False(t, fingerprint.ByContent, "known file should not be identified by content")
+}
+
+func TestManifestEntryReleasePrefersKnownFiles(t *testing.T) {
+	entry := world.ManifestEntry{
+		ID: "entry",
+		Fingerprints: []world.FileFingerprint{
+			{Filename: "archive.dat"},
+			{Filename: "citalog.res", Release: world.ReleaseCD},
+			{Filename: "cybstrng.res", Release: world.ReleaseEnhancedEdition, Known: true},
+		},
+	}
+
+	assert.Equal(t, world.ReleaseEnhancedEdition, entry.Release())
+}
+
+func TestManifestReleaseWarningsAreEmptyForMatchingReleases(t *testing.T) {
+	manifest := aManifestWithFingerprints(
+		[]world.FileFingerprint{{Filename: "cybstrng.res", Release: world.ReleaseEnhancedEdition, Known: true}},
+		[]world.FileFingerprint{{Filename: "citalog.res", Release: world.ReleaseCD}, {Filename: "archive.dat"}})
+
+	assert.Empty(t, manifest.ReleaseWarnings())
+}
+
+func TestManifestReleaseWarningsReportMismatchingReleases(t *testing.T) {
+	manifest := aManifestWithFingerprints(
+		[]world.FileFingerprint{{Filename: "archive.dat", Release: world.ReleaseFloppy, Known: true}},
+		[]world.FileFingerprint{{Filename: "citalog.res", Release: world.ReleaseCD}})
+
+	assert.Len(t, manifest.ReleaseWarnings(), 1)
+}
+
+func TestManifestReleaseWarningsReportMismatchingLanguages(t *testing.T) {
+	manifest := aManifestWithFingerprints(
+		[]world.FileFingerprint{{Filename: "gerstrng.res", Release: world.ReleaseCD, Language: resource.LangFrench, Known: true}})
+
+	assert.Len(t, manifest.ReleaseWarnings(), 1)
+}
+
+func aManifestWithFingerprints(lists ...[]world.FileFingerprint) *world.Manifest {
+	manifest := world.NewManifest(func([]resource.ID, []resource.ID) {})
+	for index, list := range lists {
+		entry := &world.ManifestEntry{ID: string(rune('a' + index)), Fingerprints: list}
+		_ = manifest.InsertEntry(index, entry)
+	}
+	return manifest
+}
@@ -35,6 +35,17 @@ func (manifest Manifest) Entry(at int) (*ManifestEntry, error) {
 	return manifest.entries[at], nil
 }
 
+// ReleaseWarnings returns descriptions of files that do not match the release of the rest.
+// The release is determined by the first entry that has identifiable files. Files containing data
+// of a language that does not fit their name are reported as well.
+func (manifest Manifest) ReleaseWarnings() []string {
+	var list []entryFingerprint
+	for _, entry := range manifest.entries {
+		list = append(list, entry.entryFingerprints()...)
+	}
+	return fingerprintWarnings(list)
+}
+
 // Reset clears the manifest by removing all entries and notifying of all the removed resource identifier.
 func (manifest *Manifest) Reset() {
 	oldEntries := manifest.entries
@@ -1,6 +1,9 @@
 package world
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/inkyblackness/hacked/ss1/content/object"
 	"github.com/inkyblackness/hacked/ss1/content/texture"
 	"github.com/inkyblackness/hacked/ss1/resource"
@@ -15,15 +18,30 @@ type ManifestEntry struct {
 
 	ObjectProperties  object.PropertiesTable
 	TextureProperties texture.PropertiesList
+
+	// Fingerprints identify the releases the files of the entry are from.
+	Fingerprints []FileFingerprint
+	// Problems describe the files of the entry that could not be loaded completely, see FileLoadResult.Problems().
+	Problems []string
+	// Files are the paths on disk that the entry was loaded from, see FileLoadResult.Paths().
+	Files []string
 }
 
 // NewManifestEntryFrom attempts to create a manifest in memory from the given set of files.
 // It uses LoadFiles() to load the files with given filenames into memory, allowing archives as well.
 func NewManifestEntryFrom(names []string) (*ManifestEntry, error) {
-	loaded := LoadFiles(true, names)
+	return manifestEntryFrom(names, LoadFiles(true, names))
+}
+
+// SalvageManifestEntryFrom attempts to create a manifest like NewManifestEntryFrom(), yet uses SalvageFiles()
+// to also take the intact resources of damaged files. The salvaged inconsistencies are listed as problems.
+func SalvageManifestEntryFrom(names []string) (*ManifestEntry, error) {
+	return manifestEntryFrom(names, SalvageFiles(true, names))
+}
 
+func manifestEntryFrom(names []string, loaded FileLoadResult) (*ManifestEntry, error) {
 	if len(loaded.Resources) == 0 {
-		return nil, errNoResourcesFound
+		return nil, loaded.NothingLoadedError()
 	}
 
 	entry := &ManifestEntry{
@@ -32,18 +50,60 @@ func NewManifestEntryFrom(names []string) (*ManifestEntry, error) {
 	}
 
 	for location, viewer := range loaded.Resources {
+		lang, isUnpacked := loaded.Unpacked[location]
+		if !isUnpacked {
+			lang = ids.LocalizeFilename(location.Name)
+		}
 		localized := resource.LocalizedResources{
 			ID:       location.Name,
-			Language: ids.LocalizeFilename(location.Name),
+			Language: lang,
 			Viewer:   viewer,
 		}
 		entry.Resources = append(entry.Resources, localized)
 	}
 	entry.ObjectProperties = loaded.ObjectProperties
 	entry.TextureProperties = loaded.TextureProperties
+	entry.Fingerprints = fingerprintsOf(loaded)
+	entry.Problems = loaded.Problems()
+	entry.Files = loaded.Paths()
 	return entry, nil
 }
 
+func fingerprintsOf(loaded FileLoadResult) []FileFingerprint {
+	var fingerprints []FileFingerprint
+	for location, hash := range loaded.Hashes {
+		if _, isSavegame := loaded.Savegames[location]; isSavegame {
+			continue
+		}
+		fingerprint := knownReleaseFiles.Identify(location.Name, hash)
+		fingerprints = append(fingerprints, IdentifyContent(fingerprint, loaded.Resources[location]))
+	}
+	for location := range loaded.Unpacked {
+		fingerprint := knownReleaseFiles.Identify(location.Name, FileHash{})
+		fingerprints = append(fingerprints, IdentifyContent(fingerprint, loaded.Resources[location]))
+	}
+	sort.Slice(fingerprints, func(a, b int) bool {
+		return strings.ToLower(fingerprints[a].Filename) < strings.ToLower(fingerprints[b].Filename)
+	})
+	return fingerprints
+}
+
+// Release returns the release that the files of the entry are identified to be from.
+// Files with known hashes take precedence over those identified by name.
+// Returns ReleaseUnknown if none of the files could be identified.
+func (entry ManifestEntry) Release() Release {
+	reference, _ := referenceFingerprint(entry.entryFingerprints())
+	return reference.Release
+}
+
+func (entry ManifestEntry) entryFingerprints() []entryFingerprint {
+	list := make([]entryFingerprint, len(entry.Fingerprints))
+	for index, fingerprint := range entry.Fingerprints {
+		list[index] = entryFingerprint{FileFingerprint: fingerprint, entryID: entry.ID}
+	}
+	return list
+}
+
 // LocalizedResources produces a selector to retrieve resources for a specific language from this entry.
 func (entry ManifestEntry) LocalizedResources(lang resource.Language) resource.Selector {
 	return resource.Selector{
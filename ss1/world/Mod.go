@@ -20,8 +20,10 @@ type ModResetCallback func()
 //
 // It is based on a "static" world and adds its own changes. The world data itself is not static, it is merely the
 // unchangeable background for the mod. Changes to the mod are kept in a separate layer, which can be loaded and saved.
+// Between the world and the changes of the mod, an ordered list of read-only parent layers can be placed.
 type Mod struct {
 	worldManifest    *Manifest
+	parentLayers     []*ModLayer
 	resourcesChanged resource.ModificationCallback
 	resetCallback    ModResetCallback
 
@@ -40,6 +42,7 @@ func NewMod(resourcesChanged resource.ModificationCallback, resetCallback ModRes
 	}
 	mod.worldManifest = NewManifest(mod.worldChanged)
 	mod.data.FileChangeCallback = mod.markFileChanged
+	mod.data.ResourceTemplate = mod.worldResourceTemplate
 
 	return mod
 }
@@ -104,15 +107,124 @@ func (mod Mod) ModifiedResource(lang resource.Language, id resource.ID) resource
 }
 
 func (mod Mod) modifiedResource(lang resource.Language, id resource.ID) *resource.Resource {
-	for _, entry := range mod.data.LocalizedResources {
-		if entry.Language == lang {
-			res, err := entry.Store.Resource(id)
-			if err == nil {
-				return res
+	return localizedResource(mod.data.LocalizedResources, lang, id)
+}
+
+// ResourceKeysOfType returns the keys of all resources with given content type, from the static world,
+// the parent layers, and the mod itself. The keys refer to the first block, and are sorted by ID and language.
+func (mod Mod) ResourceKeysOfType(contentType resource.ContentType) []resource.Key {
+	found := make(map[resource.Key]bool)
+	var keys []resource.Key
+	add := func(lang resource.Language, viewer resource.Viewer) {
+		for _, id := range viewer.IDs() {
+			key := resource.KeyOf(id, lang, 0)
+			if found[key] {
+				continue
 			}
+			if view, err := viewer.View(id); (err == nil) && (view.ContentType() == contentType) {
+				found[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	for at := 0; at < mod.worldManifest.EntryCount(); at++ {
+		entry, _ := mod.worldManifest.Entry(at)
+		for _, localized := range entry.Resources {
+			add(localized.Language, localized.Viewer)
+		}
+	}
+	for _, layer := range mod.parentLayers {
+		for _, localized := range layer.LocalizedResources {
+			add(localized.Language, localized.Store)
 		}
 	}
-	return nil
+	for _, localized := range mod.data.LocalizedResources {
+		add(localized.Language, localized.Store)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].ID != keys[b].ID {
+			return keys[a].ID < keys[b].ID
+		}
+		return keys[a].Lang < keys[b].Lang
+	})
+	return keys
+}
+
+// worldResourceTemplate finds the resource in the parent layers, or the static world, to be used as template
+// for a new resource. The top-most layer that has the resource is used, then the last entry of the world.
+func (mod *Mod) worldResourceTemplate(lang resource.Language, id resource.ID) (resource.Properties, string, bool) {
+	for index := len(mod.parentLayers) - 1; index >= 0; index-- {
+		for _, loc := range mod.parentLayers[index].LocalizedResources {
+			if loc.Language != lang {
+				continue
+			}
+			res, err := loc.Store.Resource(id)
+			if err != nil {
+				continue
+			}
+			return res.Properties, loc.Template, true
+		}
+	}
+	for at := mod.worldManifest.EntryCount() - 1; at >= 0; at-- {
+		entry, _ := mod.worldManifest.Entry(at)
+		for _, localized := range entry.Resources {
+			if localized.Language != lang {
+				continue
+			}
+			view, err := localized.Viewer.View(id)
+			if err != nil {
+				continue
+			}
+			properties := resource.Properties{
+				Compound:    view.Compound(),
+				ContentType: view.ContentType(),
+				Compressed:  view.Compressed(),
+			}
+			return properties, filepath.Base(localized.ID), true
+		}
+	}
+	return resource.Properties{}, "", false
+}
+
+// ModifiedResourceLayer retrieves the resource of given language and ID from the top-most layer that has it.
+// As with ModifiedResource(), there is no fallback lookup, and the static world is not considered.
+// The returned layer is the parent layer that supplies the resource. It is nil if the resource is from
+// the mod itself. Returns a nil view if no layer has the resource.
+func (mod Mod) ModifiedResourceLayer(lang resource.Language, id resource.ID) (resource.View, *ModLayer) {
+	if res := mod.modifiedResource(lang, id); res != nil {
+		return res, nil
+	}
+	for index := len(mod.parentLayers) - 1; index >= 0; index-- {
+		layer := mod.parentLayers[index]
+		if res := layer.resource(lang, id); res != nil {
+			return res, layer
+		}
+	}
+	return nil, nil
+}
+
+// ParentLayers returns the read-only layers the mod is based on, starting with the lowest.
+func (mod Mod) ParentLayers() []*ModLayer {
+	return mod.parentLayers
+}
+
+// SetParentLayers changes the read-only layers the mod is based on.
+// The layers are ordered starting with the lowest, the one closest to the world.
+func (mod *Mod) SetParentLayers(layers []*ModLayer) {
+	var modifiedIDs resource.IDMarkerMap
+	collectIDs := func(list []*ModLayer) {
+		for _, layer := range list {
+			for _, loc := range layer.LocalizedResources {
+				for _, id := range loc.Store.IDs() {
+					modifiedIDs.Add(id)
+				}
+			}
+		}
+	}
+	collectIDs(mod.parentLayers)
+	collectIDs(layers)
+	mod.parentLayers = layers
+	mod.resourcesChanged(modifiedIDs.ToList(), nil)
 }
 
 // CreateBlockPatch creates delta information for a block witch static data length.
@@ -194,12 +306,19 @@ func (mod Mod) blockCopy(data []byte) []byte {
 // Filter returns a list of resources that match the given parameters.
 func (mod Mod) Filter(lang resource.Language, id resource.ID) resource.List {
 	list := mod.worldManifest.Filter(lang, id)
-	if res := mod.modifiedResource(resource.LangAny, id); res != nil {
+	for _, layer := range mod.parentLayers {
+		list = filterLayer(list, layer.LocalizedResources, lang, id)
+	}
+	return filterLayer(list, mod.data.LocalizedResources, lang, id)
+}
+
+func filterLayer(list resource.List, layer []*LocalizedResources, lang resource.Language, id resource.ID) resource.List {
+	if res := localizedResource(layer, resource.LangAny, id); res != nil {
 		list = list.With(res)
 	}
 	for _, worldLang := range resource.Languages() {
 		if worldLang.Includes(lang) {
-			if res := mod.modifiedResource(lang, id); res != nil {
+			if res := localizedResource(layer, lang, id); res != nil {
 				list = list.With(res)
 			}
 		}
@@ -221,6 +340,21 @@ func (mod Mod) LocalizedResources(lang resource.Language) resource.Selector {
 func (mod *Mod) Modify(modifier func(Modder)) {
 	var trans ModTransaction
 	modifier(&trans)
+	mod.apply(&trans)
+}
+
+// ModifyRecorded requests to change the mod, the same as Modify(), and returns the resulting change.
+// The returned change contains only those parts that actually differ after the modification.
+func (mod *Mod) ModifyRecorded(modifier func(Modder)) ModChange {
+	var trans ModTransaction
+	modifier(&trans)
+	touches := mod.recordingTouches(trans.touches)
+	before := mod.stateOf(touches)
+	mod.apply(&trans)
+	return changeBetween(touches, before, mod.stateOf(touches))
+}
+
+func (mod *Mod) apply(trans *ModTransaction) {
 	mod.modifyAndNotify(func() {
 		for _, action := range trans.actions {
 			action(&mod.data)
@@ -233,6 +367,11 @@ func (mod *Mod) ObjectProperties() object.PropertiesTable {
 	if mod.HasModifiableObjectProperties() {
 		return mod.data.ObjectProperties
 	}
+	for index := len(mod.parentLayers) - 1; index >= 0; index-- {
+		if properties := mod.parentLayers[index].ObjectProperties; len(properties) > 0 {
+			return properties
+		}
+	}
 	return mod.worldManifest.ObjectProperties()
 }
 
@@ -246,6 +385,11 @@ func (mod *Mod) TextureProperties() texture.PropertiesList {
 	if mod.HasModifiableTextureProperties() {
 		return mod.data.TextureProperties
 	}
+	for index := len(mod.parentLayers) - 1; index >= 0; index-- {
+		if properties := mod.parentLayers[index].TextureProperties; len(properties) > 0 {
+			return properties
+		}
+	}
 	return mod.worldManifest.TextureProperties()
 }
 
@@ -295,6 +439,19 @@ func (mod *Mod) Reset(newResources []*LocalizedResources, objectProperties objec
 	mod.resourcesChanged(modifiedIDs.ToList(), nil)
 }
 
+// MarkAllFilesChanged marks all files of the mod as changed, so that they will be saved again.
+func (mod *Mod) MarkAllFilesChanged() {
+	for _, res := range mod.data.LocalizedResources {
+		mod.markFileChanged(res.File.Name)
+	}
+	if mod.HasModifiableObjectProperties() {
+		mod.markFileChanged(ObjectPropertiesFilename)
+	}
+	if mod.HasModifiableTextureProperties() {
+		mod.markFileChanged(TexturePropertiesFilename)
+	}
+}
+
 func (mod *Mod) markFileChanged(filename string) {
 	mod.changedFiles[filename] = struct{}{}
 	mod.lastChangeTime = time.Now()
@@ -0,0 +1,319 @@
+package world
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/content/texture"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/serial/rle"
+)
+
+// ModChange describes the difference of a mod before and after a modification.
+// It is plain data that can be serialized, and it can be applied in either direction with a Modder.
+//
+// The change is only valid for the state of the mod it was recorded for. Meta information of resources,
+// such as their content type, is part of changes of entire resources, so that re-created resources get it back.
+type ModChange struct {
+	Blocks            []BlockChange             `json:",omitempty"`
+	Resources         []ResourceChange          `json:",omitempty"`
+	TextureProperties []TexturePropertiesChange `json:",omitempty"`
+	ObjectProperties  []ObjectPropertiesChange  `json:",omitempty"`
+}
+
+// BlockChange describes the change of a single block of an existing resource.
+type BlockChange struct {
+	Language resource.Language
+	ID       resource.ID
+	Index    int
+	// Length is set if the block kept its length. In this case, Before and After are patches
+	// as produced by rle.Compress(). Otherwise, they contain the full block data.
+	Length int `json:",omitempty"`
+	Before []byte
+	After  []byte
+}
+
+// ResourceChange describes the change of all blocks of a resource.
+// Nil blocks mean that the resource does not exist in the mod.
+type ResourceChange struct {
+	Language resource.Language
+	ID       resource.ID
+	Before   [][]byte
+	After    [][]byte
+	// BeforeProperties and AfterProperties are the meta information of the resource.
+	// They are nil if the resource does not exist, or if the change was recorded without them.
+	BeforeProperties *resource.Properties `json:",omitempty"`
+	AfterProperties  *resource.Properties `json:",omitempty"`
+}
+
+// TexturePropertiesChange describes the change of the properties of one texture.
+type TexturePropertiesChange struct {
+	Index  int
+	Before texture.Properties
+	After  texture.Properties
+}
+
+// ObjectPropertiesChange describes the change of the properties of one object.
+type ObjectPropertiesChange struct {
+	Triple object.Triple
+	Before object.Properties
+	After  object.Properties
+}
+
+// IsEmpty returns true if the change does not modify anything.
+func (change ModChange) IsEmpty() bool {
+	return (len(change.Blocks) == 0) && (len(change.Resources) == 0) &&
+		(len(change.TextureProperties) == 0) && (len(change.ObjectProperties) == 0)
+}
+
+// Do applies the change to a mod that is in the state from before the modification.
+func (change ModChange) Do(modder Modder) {
+	change.apply(modder, true)
+}
+
+// Undo reverts the change of a mod that is in the state from after the modification.
+func (change ModChange) Undo(modder Modder) {
+	change.apply(modder, false)
+}
+
+func (change ModChange) apply(modder Modder, forward bool) {
+	pick := func(before, after []byte) []byte {
+		if forward {
+			return after
+		}
+		return before
+	}
+	for _, block := range change.Blocks {
+		data := pick(block.Before, block.After)
+		if block.Length > 0 {
+			modder.PatchResourceBlock(block.Language, block.ID, block.Index, block.Length, data)
+		} else {
+			modder.SetResourceBlock(block.Language, block.ID, block.Index, data)
+		}
+	}
+	for _, res := range change.Resources {
+		blocks, properties := res.Before, res.BeforeProperties
+		if forward {
+			blocks, properties = res.After, res.AfterProperties
+		}
+		if blocks == nil {
+			modder.DelResource(res.Language, res.ID)
+			continue
+		}
+		modder.SetResourceBlocks(res.Language, res.ID, blocks)
+		if properties != nil {
+			modder.SetResourceProperties(res.Language, res.ID, *properties)
+		}
+	}
+	for _, properties := range change.TextureProperties {
+		if forward {
+			modder.SetTextureProperties(properties.Index, properties.After)
+		} else {
+			modder.SetTextureProperties(properties.Index, properties.Before)
+		}
+	}
+	for _, properties := range change.ObjectProperties {
+		if forward {
+			modder.SetObjectProperties(properties.Triple, properties.After)
+		} else {
+			modder.SetObjectProperties(properties.Triple, properties.Before)
+		}
+	}
+}
+
+type resourceKey struct {
+	lang resource.Language
+	id   resource.ID
+}
+
+type blockKey struct {
+	resourceKey
+	index int
+}
+
+// modTouches lists the parts of a mod a transaction modifies, in order of their first modification.
+type modTouches struct {
+	blocks    []blockKey
+	resources []resourceKey
+	textures  []int
+	objects   []object.Triple
+}
+
+// modState is a copy of the parts of a mod that are subject to a modification.
+type modState struct {
+	resources  map[resourceKey][][]byte
+	properties map[resourceKey]resource.Properties
+	blocks     map[blockKey][]byte
+	textures   map[int]texture.Properties
+	objects    map[object.Triple]object.Properties
+}
+
+// recordingTouches determines how the touched parts are recorded. Blocks of resources that do not exist yet,
+// or that are extended, are recorded with their entire resource, as their block count changes.
+func (mod Mod) recordingTouches(touches modTouches) modTouches {
+	var result modTouches
+	wholeResources := make(map[resourceKey]bool)
+	addResource := func(key resourceKey) {
+		if !wholeResources[key] {
+			wholeResources[key] = true
+			result.resources = append(result.resources, key)
+		}
+	}
+	for _, key := range touches.resources {
+		addResource(key)
+	}
+	for _, key := range touches.blocks {
+		res := mod.modifiedResource(key.lang, key.id)
+		if (res == nil) || (key.index >= res.BlockCount()) {
+			addResource(key.resourceKey)
+		}
+	}
+	addedBlocks := make(map[blockKey]bool)
+	for _, key := range touches.blocks {
+		if !wholeResources[key.resourceKey] && !addedBlocks[key] {
+			addedBlocks[key] = true
+			result.blocks = append(result.blocks, key)
+		}
+	}
+	addedTextures := make(map[int]bool)
+	for _, index := range touches.textures {
+		if !addedTextures[index] {
+			addedTextures[index] = true
+			result.textures = append(result.textures, index)
+		}
+	}
+	addedObjects := make(map[object.Triple]bool)
+	for _, triple := range touches.objects {
+		if !addedObjects[triple] {
+			addedObjects[triple] = true
+			result.objects = append(result.objects, triple)
+		}
+	}
+	return result
+}
+
+func (mod Mod) stateOf(touches modTouches) modState {
+	state := modState{
+		resources:  make(map[resourceKey][][]byte),
+		properties: make(map[resourceKey]resource.Properties),
+		blocks:     make(map[blockKey][]byte),
+		textures:   make(map[int]texture.Properties),
+		objects:    make(map[object.Triple]object.Properties),
+	}
+	for _, key := range touches.resources {
+		state.resources[key] = mod.ModifiedBlocks(key.lang, key.id)
+		if res := mod.modifiedResource(key.lang, key.id); res != nil {
+			state.properties[key] = res.Properties
+		}
+	}
+	for _, key := range touches.blocks {
+		state.blocks[key] = mod.ModifiedBlock(key.lang, key.id, key.index)
+	}
+	for _, index := range touches.textures {
+		if (index >= 0) && (index < len(mod.data.TextureProperties)) {
+			state.textures[index] = mod.data.TextureProperties[index]
+		}
+	}
+	for _, triple := range touches.objects {
+		if properties, err := mod.data.ObjectProperties.ForObject(triple); err == nil {
+			state.objects[triple] = properties.Clone()
+		}
+	}
+	return state
+}
+
+func changeBetween(touches modTouches, before, after modState) ModChange {
+	var change ModChange
+	for _, key := range touches.resources {
+		oldBlocks, newBlocks := before.resources[key], after.resources[key]
+		oldProperties, newProperties := before.propertiesOf(key), after.propertiesOf(key)
+		if !blocksEqual(oldBlocks, newBlocks) || !propertiesEqual(oldProperties, newProperties) {
+			change.Resources = append(change.Resources, ResourceChange{
+				Language:         key.lang,
+				ID:               key.id,
+				Before:           oldBlocks,
+				After:            newBlocks,
+				BeforeProperties: oldProperties,
+				AfterProperties:  newProperties,
+			})
+		}
+	}
+	for _, key := range touches.blocks {
+		oldData, newData := before.blocks[key], after.blocks[key]
+		if !bytes.Equal(oldData, newData) {
+			change.Blocks = append(change.Blocks, blockChangeBetween(key, oldData, newData))
+		}
+	}
+	for _, index := range touches.textures {
+		oldProperties, existing := before.textures[index]
+		newProperties := after.textures[index]
+		if existing && (oldProperties != newProperties) {
+			change.TextureProperties = append(change.TextureProperties, TexturePropertiesChange{
+				Index:  index,
+				Before: oldProperties,
+				After:  newProperties,
+			})
+		}
+	}
+	for _, triple := range touches.objects {
+		oldProperties, existing := before.objects[triple]
+		newProperties := after.objects[triple]
+		if existing && !reflect.DeepEqual(oldProperties, newProperties) {
+			change.ObjectProperties = append(change.ObjectProperties, ObjectPropertiesChange{
+				Triple: triple,
+				Before: oldProperties,
+				After:  newProperties,
+			})
+		}
+	}
+	return change
+}
+
+func (state modState) propertiesOf(key resourceKey) *resource.Properties {
+	properties, existing := state.properties[key]
+	if !existing {
+		return nil
+	}
+	return &properties
+}
+
+func propertiesEqual(a, b *resource.Properties) bool {
+	if (a == nil) || (b == nil) {
+		return a == b
+	}
+	return *a == *b
+}
+
+func blockChangeBetween(key blockKey, oldData, newData []byte) BlockChange {
+	change := BlockChange{
+		Language: key.lang,
+		ID:       key.id,
+		Index:    key.index,
+		Before:   oldData,
+		After:    newData,
+	}
+	if len(oldData) != len(newData) {
+		return change
+	}
+	forward := bytes.NewBuffer(nil)
+	reverse := bytes.NewBuffer(nil)
+	if (rle.Compress(forward, newData, oldData) == nil) && (rle.Compress(reverse, oldData, newData) == nil) {
+		change.Length = len(oldData)
+		change.Before = reverse.Bytes()
+		change.After = forward.Bytes()
+	}
+	return change
+}
+
+func blocksEqual(a, b [][]byte) bool {
+	if (a == nil) != (b == nil) || (len(a) != len(b)) {
+		return false
+	}
+	for index := range a {
+		if !bytes.Equal(a[index], b[index]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package world_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/texture"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifyRecordedCreatesPatchesForBlocksOfSameLength(t *testing.T) {
+	mod := givenModWithText(t, [][]byte{{0x01, 0x02, 0x00}, {0x00}})
+
+	change := mod.ModifyRecorded(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangDefault, 0x0869, 0, []byte{0x01, 0x03, 0x00})
+	})
+
+	require.Equal(t, 1, len(change.Blocks), "one block change expected")
+	assert.Equal(t, 3, change.Blocks[0].Length, "block should be patched")
+	assert.Empty(t, change.Resources, "no resource change expected")
+
+	mod.Modify(change.Undo)
+	assert.Equal(t, [][]byte{{0x01, 0x02, 0x00}, {0x00}}, mod.ModifiedBlocks(resource.LangDefault, 0x0869), "undo")
+	mod.Modify(change.Do)
+	assert.Equal(t, [][]byte{{0x01, 0x03, 0x00}, {0x00}}, mod.ModifiedBlocks(resource.LangDefault, 0x0869), "redo")
+}
+
+func TestModifyRecordedCreatesResourceChangeForNewResources(t *testing.T) {
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+
+	change := mod.ModifyRecorded(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangDefault, 0x0869, 1, []byte{0x0A, 0x00})
+	})
+
+	require.Equal(t, 1, len(change.Resources), "one resource change expected")
+	assert.Nil(t, change.Resources[0].Before, "resource should not exist before")
+	assert.Empty(t, change.Blocks, "no block change expected")
+
+	mod.Modify(change.Undo)
+	assert.Nil(t, mod.ModifiedResource(resource.LangDefault, 0x0869), "resource should be removed again")
+	mod.Modify(change.Do)
+	assert.Equal(t, [][]byte{{}, {0x0A, 0x00}}, mod.ModifiedBlocks(resource.LangDefault, 0x0869), "redo")
+}
+
+func TestModifyRecordedOmitsUnchangedParts(t *testing.T) {
+	mod := givenModWithText(t, [][]byte{{0x01, 0x00}})
+
+	change := mod.ModifyRecorded(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangDefault, 0x0869, 0, []byte{0x01, 0x00})
+		modder.SetTextureProperties(0, texture.Properties{})
+	})
+
+	assert.True(t, change.IsEmpty(), "change should be empty")
+}
+
+func TestModChangeCanBeSerialized(t *testing.T) {
+	mod := givenModWithText(t, [][]byte{{0x01, 0x00}, {0x02, 0x00}})
+	change := mod.ModifyRecorded(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangDefault, 0x0869, 0, []byte{0x03, 0x04, 0x00})
+		modder.SetResourceBlock(resource.LangDefault, 0x0869, 1, []byte{0x05, 0x00})
+	})
+
+	data, err := json.Marshal(change)
+	require.Nil(t, err, "no error expected marshalling")
+	var restored world.ModChange
+	err = json.Unmarshal(data, &restored)
+	require.Nil(t, err, "no error expected unmarshalling")
+
+	mod.Modify(restored.Undo)
+	assert.Equal(t, [][]byte{{0x01, 0x00}, {0x02, 0x00}}, mod.ModifiedBlocks(resource.LangDefault, 0x0869), "undo")
+	mod.Modify(restored.Do)
+	assert.Equal(t, [][]byte{{0x03, 0x04, 0x00}, {0x05, 0x00}}, mod.ModifiedBlocks(resource.LangDefault, 0x0869), "redo")
+}
+
+func TestModChangeRestoresPropertiesOfResources(t *testing.T) {
+	mod := givenModWithText(t, [][]byte{{0x01, 0x00}})
+	properties := resource.Properties{Compound: true, ContentType: resource.Text, Compressed: true}
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceProperties(resource.LangDefault, 0x0869, properties)
+	})
+	change := mod.ModifyRecorded(func(modder world.Modder) {
+		modder.DelResource(resource.LangDefault, 0x0869)
+	})
+
+	data, err := json.Marshal(change)
+	require.Nil(t, err, "no error expected marshalling")
+	var restored world.ModChange
+	err = json.Unmarshal(data, &restored)
+	require.Nil(t, err, "no error expected unmarshalling")
+
+	mod.Modify(restored.Undo)
+	view := mod.ModifiedResource(resource.LangDefault, 0x0869)
+	require.NotNil(t, view, "resource should be restored")
+	assert.True(t, view.Compressed(), "compression should be restored")
+	assert.Equal(t, [][]byte{{0x01, 0x00}}, mod.ModifiedBlocks(resource.LangDefault, 0x0869), "blocks should be restored")
+}
+
+func TestModifyRecordedRecordsChangedProperties(t *testing.T) {
+	mod := givenModWithText(t, [][]byte{{0x01, 0x00}})
+
+	change := mod.ModifyRecorded(func(modder world.Modder) {
+		modder.SetResourceProperties(resource.LangDefault, 0x0869,
+			resource.Properties{Compound: true, ContentType: resource.Text, Compressed: true})
+	})
+
+	require.Equal(t, 1, len(change.Resources), "one resource change expected")
+	mod.Modify(change.Undo)
+	assert.False(t, mod.ModifiedResource(resource.LangDefault, 0x0869).Compressed(), "undo")
+	mod.Modify(change.Do)
+	assert.True(t, mod.ModifiedResource(resource.LangDefault, 0x0869).Compressed(), "redo")
+}
+
+func givenModWithText(t *testing.T, blocks [][]byte) *world.Mod {
+	t.Helper()
+	mod := world.NewMod(func([]resource.ID, []resource.ID) {}, func() {})
+	mod.Modify(func(modder world.Modder) {
+		modder.SetResourceBlocks(resource.LangDefault, 0x0869, blocks)
+	})
+	require.Equal(t, blocks, mod.ModifiedBlocks(resource.LangDefault, 0x0869), "text should be set")
+	return mod
+}
@@ -0,0 +1,343 @@
+package world
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/content/texture"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/diff"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// ModConflicts describes where two mods collide.
+// Two mods collide if both change the same data in different ways.
+type ModConflicts struct {
+	// Resources lists the resources both mods change, in ascending order of language and identifier.
+	Resources []ResourceConflict
+	// ObjectProperties lists the objects for which both mods change the properties.
+	ObjectProperties []object.Triple
+	// TextureProperties lists the indices of textures for which both mods change the properties.
+	TextureProperties []int
+	// Levels lists the details of conflicting level data, in ascending order of the level.
+	// Only levels with conflicting tiles or objects are listed.
+	Levels []LevelConflict
+	// Identical lists the resources both mods change in the same way, in ascending order of language and identifier.
+	// The blocks are those that both mods changed identically. These overlaps are not conflicts.
+	// They can only be determined with a base.
+	Identical []ResourceConflict
+}
+
+// IsEmpty returns true if no conflicts were found. Identical overlaps are not considered.
+func (conflicts ModConflicts) IsEmpty() bool {
+	return (len(conflicts.Resources) == 0) && (len(conflicts.ObjectProperties) == 0) &&
+		(len(conflicts.TextureProperties) == 0) && (len(conflicts.Levels) == 0)
+}
+
+// ResourceConflict describes a resource that is changed by both mods.
+type ResourceConflict struct {
+	Language resource.Language
+	ID       resource.ID
+	// Blocks lists the indices of the blocks that are changed by both mods.
+	Blocks []int
+}
+
+// LevelConflict describes the parts of an archive level that are changed by both mods.
+type LevelConflict struct {
+	// Level is the identifier of the level.
+	Level int
+	// Tiles lists the positions of the tiles both mods change.
+	Tiles []level.TilePosition
+	// Objects lists the identifier of the level objects both mods change.
+	Objects []level.ObjectID
+}
+
+type modResourceKey struct {
+	lang resource.Language
+	id   resource.ID
+}
+
+// FindModConflicts compares two mods, as loaded by LoadFiles(), and reports where they collide.
+//
+// The optional base is used to determine what the mods actually change: Data is only considered conflicting
+// if both mods differ from the base, and from each other. Without a base, any difference between the two
+// mods is considered a conflict, so a base should be provided whenever it is available.
+// Empty blocks are never considered a conflict, as they let underlying data through.
+func FindModConflicts(base *Manifest, first, second FileLoadResult) ModConflicts {
+	var conflicts ModConflicts
+	finder := modConflictFinder{base: base}
+	firstResources := modResourcesOf(first)
+	secondResources := modResourcesOf(second)
+	for _, key := range sortedModResourceKeys(firstResources) {
+		secondView, inBoth := secondResources[key]
+		if !inBoth {
+			continue
+		}
+		firstBlocks := resourceBlocks(firstResources[key])
+		secondBlocks := resourceBlocks(secondView)
+		baseBlocks := finder.baseBlocks(key)
+		dataAt := func(index int) ([]byte, []byte, []byte) {
+			return blockAt(baseBlocks, index), blockAt(firstBlocks, index), blockAt(secondBlocks, index)
+		}
+		if identical := finder.identicalIndices(len(firstBlocks), len(secondBlocks), dataAt); len(identical) > 0 {
+			conflicts.Identical = append(conflicts.Identical, ResourceConflict{Language: key.lang, ID: key.id, Blocks: identical})
+		}
+		blocks := conflictingIndices(len(firstBlocks), len(secondBlocks), dataAt, 1)
+		if len(blocks) == 0 {
+			continue
+		}
+		conflicts.Resources = append(conflicts.Resources, ResourceConflict{Language: key.lang, ID: key.id, Blocks: blocks})
+		if lvl, lvlID, isLevel := diff.LevelResource(key.id); isLevel && (key.lang == resource.LangAny) {
+			conflicts.Levels = finder.addLevelConflict(conflicts.Levels, lvl, lvlID,
+				blockAt(baseBlocks, 0), blockAt(firstBlocks, 0), blockAt(secondBlocks, 0))
+		}
+	}
+	conflicts.ObjectProperties = finder.objectPropertiesConflicts(first.ObjectProperties, second.ObjectProperties)
+	conflicts.TextureProperties = finder.texturePropertiesConflicts(first.TextureProperties, second.TextureProperties)
+	return conflicts
+}
+
+type modConflictFinder struct {
+	base *Manifest
+}
+
+func (finder modConflictFinder) baseBlocks(key modResourceKey) [][]byte {
+	if finder.base == nil {
+		return nil
+	}
+	view, err := finder.base.LocalizedResources(key.lang).Select(key.id)
+	if err != nil {
+		return nil
+	}
+	return resourceBlocks(view)
+}
+
+// identicalIndices returns the indices for which both sides changed the data of the base in the same way.
+// Without a base, no change can be determined.
+func (finder modConflictFinder) identicalIndices(firstCount, secondCount int, dataAt func(int) ([]byte, []byte, []byte)) []int {
+	if finder.base == nil {
+		return nil
+	}
+	count := firstCount
+	if secondCount < count {
+		count = secondCount
+	}
+	var result []int
+	for index := 0; index < count; index++ {
+		baseData, firstData, secondData := dataAt(index)
+		if (len(firstData) > 0) && bytes.Equal(firstData, secondData) && !bytes.Equal(baseData, firstData) {
+			result = append(result, index)
+		}
+	}
+	return result
+}
+
+func (finder modConflictFinder) addLevelConflict(levels []LevelConflict, lvl int, lvlID int,
+	baseData, firstData, secondData []byte) []LevelConflict {
+	entries := func(data []byte, entrySize int) func(int) []byte {
+		return func(index int) []byte {
+			if (index+1)*entrySize > len(data) {
+				return nil
+			}
+			return data[index*entrySize : (index+1)*entrySize]
+		}
+	}
+	countOf := func(data []byte, entrySize int) int {
+		return len(data) / entrySize
+	}
+	compare := func(entrySize int) []int {
+		baseEntry := entries(baseData, entrySize)
+		firstEntry := entries(firstData, entrySize)
+		secondEntry := entries(secondData, entrySize)
+		return conflictingIndices(countOf(firstData, entrySize), countOf(secondData, entrySize),
+			func(index int) ([]byte, []byte, []byte) {
+				return baseEntry(index), firstEntry(index), secondEntry(index)
+			}, 0)
+	}
+
+	var tiles []level.TilePosition
+	var objects []level.ObjectID
+	switch lvlID {
+	case lvlids.TileMap:
+		xShift := finder.levelXShift(lvl)
+		for _, index := range compare(binary.Size(level.TileMapEntry{})) {
+			tiles = append(tiles, level.TilePosition{
+				X: byte(index & ((1 << xShift) - 1)),
+				Y: byte(index >> xShift),
+			})
+		}
+	case lvlids.ObjectMainTable:
+		for _, index := range compare(level.ObjectMainEntrySize) {
+			objects = append(objects, level.ObjectID(index))
+		}
+	}
+	if (len(tiles) == 0) && (len(objects) == 0) {
+		return levels
+	}
+	var conflict *LevelConflict
+	for index := range levels {
+		if levels[index].Level == lvl {
+			conflict = &levels[index]
+		}
+	}
+	if conflict == nil {
+		levels = append(levels, LevelConflict{Level: lvl})
+		conflict = &levels[len(levels)-1]
+	}
+	conflict.Tiles = append(conflict.Tiles, tiles...)
+	conflict.Objects = append(conflict.Objects, objects...)
+	sort.Slice(levels, func(a, b int) bool { return levels[a].Level < levels[b].Level })
+	return levels
+}
+
+// levelXShift returns the horizontal extent of the level map, as per the base.
+// Without a base, the default size of a level is assumed.
+func (finder modConflictFinder) levelXShift(lvl int) int {
+	defaultInfo := level.DefaultBaseInfo(false)
+	data := blockAt(finder.baseBlocks(modResourceKey{
+		lang: resource.LangAny,
+		id:   ids.LevelResourcesStart.Plus(lvl*lvlids.PerLevel + lvlids.Information),
+	}), 0)
+	var info level.BaseInfo
+	if (data == nil) || (binary.Read(bytes.NewReader(data), binary.LittleEndian, &info) != nil) ||
+		(info.XShift <= 0) || (info.XShift > 8) {
+		return int(defaultInfo.XShift)
+	}
+	return int(info.XShift)
+}
+
+func (finder modConflictFinder) objectPropertiesConflicts(first, second object.PropertiesTable) []object.Triple {
+	if (len(first) == 0) || (len(second) == 0) {
+		return nil
+	}
+	var baseTable object.PropertiesTable
+	if finder.base != nil {
+		baseTable = finder.base.ObjectProperties()
+	}
+	encoded := func(table object.PropertiesTable, triple object.Triple) []byte {
+		prop, err := table.ForObject(triple)
+		if err != nil {
+			return nil
+		}
+		buf := bytes.NewBuffer(nil)
+		_ = binary.Write(buf, binary.LittleEndian, &prop.Common)
+		buf.Write(prop.Generic)
+		buf.Write(prop.Specific)
+		return buf.Bytes()
+	}
+	var result []object.Triple
+	first.Iterate(func(triple object.Triple, _ *object.Properties) bool {
+		if isConflict(encoded(baseTable, triple), encoded(first, triple), encoded(second, triple)) {
+			result = append(result, triple)
+		}
+		return true
+	})
+	return result
+}
+
+func (finder modConflictFinder) texturePropertiesConflicts(first, second texture.PropertiesList) []int {
+	if (len(first) == 0) || (len(second) == 0) {
+		return nil
+	}
+	var baseList texture.PropertiesList
+	if finder.base != nil {
+		baseList = finder.base.TextureProperties()
+	}
+	encoded := func(list texture.PropertiesList, index int) []byte {
+		if index >= len(list) {
+			return nil
+		}
+		buf := bytes.NewBuffer(nil)
+		_ = binary.Write(buf, binary.LittleEndian, &list[index])
+		return buf.Bytes()
+	}
+	return conflictingIndices(len(first), len(second), func(index int) ([]byte, []byte, []byte) {
+		return encoded(baseList, index), encoded(first, index), encoded(second, index)
+	}, 0)
+}
+
+// conflictingIndices returns the indices for which the data of both sides differ from the base, and each other.
+// Data shorter than given minimum length is not considered for a conflict.
+func conflictingIndices(firstCount, secondCount int, dataAt func(int) ([]byte, []byte, []byte), minLength int) []int {
+	count := firstCount
+	if secondCount < count {
+		count = secondCount
+	}
+	var result []int
+	for index := 0; index < count; index++ {
+		baseData, firstData, secondData := dataAt(index)
+		if (len(firstData) < minLength) || (len(secondData) < minLength) {
+			continue
+		}
+		if isConflict(baseData, firstData, secondData) {
+			result = append(result, index)
+		}
+	}
+	return result
+}
+
+func isConflict(baseData, firstData, secondData []byte) bool {
+	if bytes.Equal(firstData, secondData) {
+		return false
+	}
+	if baseData == nil {
+		return true
+	}
+	return !bytes.Equal(baseData, firstData) && !bytes.Equal(baseData, secondData)
+}
+
+func modResourcesOf(loaded FileLoadResult) map[modResourceKey]resource.View {
+	result := make(map[modResourceKey]resource.View)
+	for location, viewer := range loaded.Resources {
+		lang, isUnpacked := loaded.Unpacked[location]
+		if !isUnpacked {
+			lang = ids.LocalizeFilename(location.Name)
+		}
+		for _, id := range viewer.IDs() {
+			view, err := viewer.View(id)
+			if err != nil {
+				continue
+			}
+			result[modResourceKey{lang: lang, id: id}] = view
+		}
+	}
+	return result
+}
+
+func sortedModResourceKeys(resources map[modResourceKey]resource.View) []modResourceKey {
+	keys := make([]modResourceKey, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].lang != keys[b].lang {
+			return keys[a].lang < keys[b].lang
+		}
+		return keys[a].id < keys[b].id
+	})
+	return keys
+}
+
+func resourceBlocks(view resource.View) [][]byte {
+	blocks := make([][]byte, view.BlockCount())
+	for index := range blocks {
+		reader, err := view.Block(index)
+		if err != nil {
+			continue
+		}
+		blocks[index], _ = ioutil.ReadAll(reader)
+	}
+	return blocks
+}
+
+func blockAt(blocks [][]byte, index int) []byte {
+	if index >= len(blocks) {
+		return nil
+	}
+	return blocks[index]
+}
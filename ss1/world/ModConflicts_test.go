@@ -0,0 +1,148 @@
+package world_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/content/texture"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindModConflictsReportsDifferingBlocks(t *testing.T) {
+	first := aModWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x01}, {}, {0x03}, {0x04}})
+	second := aModWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x02}, {0x02}, {0x03}, {0x05}})
+
+	conflicts := world.FindModConflicts(nil, first, second)
+
+	require.Len(t, conflicts.Resources, 1, "one conflict expected")
+	assert.Equal(t, resource.LangDefault, conflicts.Resources[0].Language)
+	assert.Equal(t, resource.ID(0x0869), conflicts.Resources[0].ID)
+	assert.Equal(t, []int{0, 3}, conflicts.Resources[0].Blocks)
+}
+
+func TestFindModConflictsIgnoresResourcesOfOtherLanguages(t *testing.T) {
+	first := aModWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x01}})
+	second := aModWithBlocks("gerstrng.res", 0x0869, [][]byte{{0x02}})
+
+	conflicts := world.FindModConflicts(nil, first, second)
+
+	assert.True(t, conflicts.IsEmpty(), "no conflicts expected")
+}
+
+func TestFindModConflictsIgnoresChangesOfOnlyOneModAgainstBase(t *testing.T) {
+	base := aManifestWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x01}, {0x02}})
+	first := aModWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x01}, {0x20}})
+	second := aModWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x10}, {0x21}})
+
+	conflicts := world.FindModConflicts(base, first, second)
+
+	require.Len(t, conflicts.Resources, 1, "one conflict expected")
+	assert.Equal(t, []int{1}, conflicts.Resources[0].Blocks)
+}
+
+func TestFindModConflictsReportsLevelObjects(t *testing.T) {
+	id := ids.LevelResourcesStart.Plus(lvlids.PerLevel*3 + lvlids.ObjectMainTable)
+	firstData := make([]byte, level.ObjectMainEntrySize*4)
+	secondData := make([]byte, level.ObjectMainEntrySize*4)
+	firstData[level.ObjectMainEntrySize*1] = 0x01
+	secondData[level.ObjectMainEntrySize*1] = 0x02
+	secondData[level.ObjectMainEntrySize*2] = 0x02
+	firstData[level.ObjectMainEntrySize*3+5] = 0x03
+	secondData[level.ObjectMainEntrySize*3+5] = 0x03
+
+	conflicts := world.FindModConflicts(nil,
+		aModWithBlocks("archive.dat", id, [][]byte{firstData}),
+		aModWithBlocks("archive.dat", id, [][]byte{secondData}))
+
+	require.Len(t, conflicts.Levels, 1, "one level expected")
+	assert.Equal(t, 3, conflicts.Levels[0].Level)
+	assert.Equal(t, []level.ObjectID{1, 2}, conflicts.Levels[0].Objects)
+}
+
+func TestFindModConflictsReportsLevelTiles(t *testing.T) {
+	id := ids.LevelResourcesStart.Plus(lvlids.PerLevel*1 + lvlids.TileMap)
+	entrySize := 16
+	firstData := make([]byte, entrySize*64*64)
+	secondData := make([]byte, entrySize*64*64)
+	firstData[entrySize*(64*2+5)] = 0x01
+	secondData[entrySize*(64*2+5)] = 0x02
+
+	conflicts := world.FindModConflicts(nil,
+		aModWithBlocks("archive.dat", id, [][]byte{firstData}),
+		aModWithBlocks("archive.dat", id, [][]byte{secondData}))
+
+	require.Len(t, conflicts.Levels, 1, "one level expected")
+	assert.Equal(t, []level.TilePosition{{X: 5, Y: 2}}, conflicts.Levels[0].Tiles)
+}
+
+func TestFindModConflictsListsOnlyLevelsWithConflictingTilesOrObjects(t *testing.T) {
+	id := ids.LevelResourcesStart.Plus(lvlids.PerLevel*2 + lvlids.Information)
+
+	conflicts := world.FindModConflicts(nil,
+		aModWithBlocks("archive.dat", id, [][]byte{{0x01}}),
+		aModWithBlocks("archive.dat", id, [][]byte{{0x02}}))
+
+	assert.Len(t, conflicts.Resources, 1, "resource conflict expected")
+	assert.Empty(t, conflicts.Levels, "no level details expected")
+}
+
+func TestFindModConflictsReportsIdenticalChangesSeparately(t *testing.T) {
+	base := aManifestWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x01}, {0x02}, {0x03}})
+	first := aModWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x10}, {0x02}, {0x30}})
+	second := aModWithBlocks("cybstrng.res", 0x0869, [][]byte{{0x10}, {0x02}, {0x31}})
+
+	conflicts := world.FindModConflicts(base, first, second)
+
+	require.Len(t, conflicts.Identical, 1, "one identical overlap expected")
+	assert.Equal(t, []int{0}, conflicts.Identical[0].Blocks)
+	require.Len(t, conflicts.Resources, 1, "one conflict expected")
+	assert.Equal(t, []int{2}, conflicts.Resources[0].Blocks)
+}
+
+func TestFindModConflictsReportsTextureProperties(t *testing.T) {
+	first := world.FileLoadResult{TextureProperties: make(texture.PropertiesList, 3)}
+	second := world.FileLoadResult{TextureProperties: make(texture.PropertiesList, 3)}
+	first.TextureProperties[0].Climbable = 1
+	second.TextureProperties[2].Climbable = 1
+	second.TextureProperties[1].DistanceModifier = 10
+	first.TextureProperties[1].DistanceModifier = 20
+
+	conflicts := world.FindModConflicts(nil, first, second)
+
+	assert.Equal(t, []int{0, 1, 2}, conflicts.TextureProperties, "all differences expected without base")
+}
+
+func aModWithBlocks(filename string, id resource.ID, blocks [][]byte) world.FileLoadResult {
+	var store resource.Store
+	_ = store.Put(id, resource.Resource{
+		Properties: resource.Properties{Compound: len(blocks) > 1},
+		Blocks:     resource.BlocksFrom(blocks),
+	})
+	return world.FileLoadResult{
+		Resources: map[world.FileLocation]resource.Viewer{
+			{DirPath: "mod", Name: filename}: store,
+		},
+	}
+}
+
+func aManifestWithBlocks(filename string, id resource.ID, blocks [][]byte) *world.Manifest {
+	manifest := world.NewManifest(func([]resource.ID, []resource.ID) {})
+	var store resource.Store
+	_ = store.Put(id, resource.Resource{
+		Properties: resource.Properties{Compound: len(blocks) > 1},
+		Blocks:     resource.BlocksFrom(blocks),
+	})
+	_ = manifest.InsertEntry(0, &world.ManifestEntry{
+		ID: "base",
+		Resources: resource.LocalizedResourcesList{
+			{ID: filename, Language: ids.LocalizeFilename(filename), Viewer: store},
+		},
+	})
+	return manifest
+}
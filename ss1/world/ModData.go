@@ -28,6 +28,9 @@ type LocalizedResources struct {
 // ModData contains the core information about a mod.
 type ModData struct {
 	FileChangeCallback func(string)
+	// ResourceTemplate provides the meta information and filename for new resources that are not known by ids.Info().
+	// It is optional.
+	ResourceTemplate func(lang resource.Language, id resource.ID) (resource.Properties, string, bool)
 
 	LocalizedResources []*LocalizedResources
 	ObjectProperties   object.PropertiesTable
@@ -67,6 +70,20 @@ func (data *ModData) SetResourceBlocks(lang resource.Language, id resource.ID, b
 	data.notifyFileChanged(loc.File.Name)
 }
 
+// SetResourceProperties changes the meta information of an existing resource.
+func (data *ModData) SetResourceProperties(lang resource.Language, id resource.ID, properties resource.Properties) {
+	for _, loc := range data.LocalizedResources {
+		if loc.Language != lang {
+			continue
+		}
+		if res, err := loc.Store.Resource(id); err == nil {
+			res.Properties = properties
+			data.notifyFileChanged(loc.File.Name)
+			return
+		}
+	}
+}
+
 // DelResource removes a resource from the mod in the given language.
 func (data *ModData) DelResource(lang resource.Language, id resource.ID) {
 	for _, loc := range data.LocalizedResources {
@@ -118,6 +135,13 @@ func (data *ModData) newResource(lang resource.Language, id resource.ID) (*Local
 		contentType = info.ContentType
 		compressed = info.Compressed
 		filename = info.ResFile.For(lang)
+	} else if data.ResourceTemplate != nil {
+		if properties, templateFilename, found := data.ResourceTemplate(lang, id); found {
+			compound = properties.Compound
+			contentType = properties.ContentType
+			compressed = properties.Compressed
+			filename = templateFilename
+		}
 	}
 
 	loc := data.ensureStore(lang, filename)
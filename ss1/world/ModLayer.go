@@ -0,0 +1,40 @@
+package world
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/content/texture"
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// ModLayer is a read-only set of modifications a mod can be based on.
+// Layers are placed between the static world and the modifications of the mod itself.
+// This allows to share common changes between several mods.
+type ModLayer struct {
+	// Name identifies the layer, typically with the path it was loaded from.
+	Name string
+
+	LocalizedResources []*LocalizedResources
+	ObjectProperties   object.PropertiesTable
+	TextureProperties  texture.PropertiesList
+
+	// Problems describe the files of the layer that could not be loaded completely, see FileLoadResult.Problems().
+	Problems []string
+	// Files are the paths on disk that the layer was loaded from, see FileLoadResult.Paths().
+	Files []string
+}
+
+func (layer ModLayer) resource(lang resource.Language, id resource.ID) *resource.Resource {
+	return localizedResource(layer.LocalizedResources, lang, id)
+}
+
+func localizedResource(list []*LocalizedResources, lang resource.Language, id resource.ID) *resource.Resource {
+	for _, entry := range list {
+		if entry.Language == lang {
+			res, err := entry.Store.Resource(id)
+			if err == nil {
+				return res
+			}
+		}
+	}
+	return nil
+}
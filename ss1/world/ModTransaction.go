@@ -13,6 +13,7 @@ type modAction func(modder Modder)
 type ModTransaction struct {
 	actions     []modAction
 	modifiedIDs resource.IDMarkerMap
+	touches     modTouches
 }
 
 // SetResourceBlock changes the block data of a resource.
@@ -28,6 +29,7 @@ func (trans *ModTransaction) SetResourceBlock(lang resource.Language, id resourc
 		modder.SetResourceBlock(lang, id, index, data)
 	})
 	trans.modifiedIDs.Add(id)
+	trans.touches.blocks = append(trans.touches.blocks, blockKey{resourceKey: resourceKey{lang: lang, id: id}, index: index})
 }
 
 // PatchResourceBlock modifies an existing block.
@@ -38,6 +40,7 @@ func (trans *ModTransaction) PatchResourceBlock(lang resource.Language, id resou
 		modder.PatchResourceBlock(lang, id, index, expectedLength, patch)
 	})
 	trans.modifiedIDs.Add(id)
+	trans.touches.blocks = append(trans.touches.blocks, blockKey{resourceKey: resourceKey{lang: lang, id: id}, index: index})
 }
 
 // SetResourceBlocks sets the entire list of block data of a resource.
@@ -47,6 +50,16 @@ func (trans *ModTransaction) SetResourceBlocks(lang resource.Language, id resour
 		modder.SetResourceBlocks(lang, id, data)
 	})
 	trans.modifiedIDs.Add(id)
+	trans.touches.resources = append(trans.touches.resources, resourceKey{lang: lang, id: id})
+}
+
+// SetResourceProperties changes the meta information of an existing resource.
+func (trans *ModTransaction) SetResourceProperties(lang resource.Language, id resource.ID, properties resource.Properties) {
+	trans.actions = append(trans.actions, func(modder Modder) {
+		modder.SetResourceProperties(lang, id, properties)
+	})
+	trans.modifiedIDs.Add(id)
+	trans.touches.resources = append(trans.touches.resources, resourceKey{lang: lang, id: id})
 }
 
 // DelResource removes a resource from the mod in the given language.
@@ -57,6 +70,7 @@ func (trans *ModTransaction) DelResource(lang resource.Language, id resource.ID)
 		modder.DelResource(lang, id)
 	})
 	trans.modifiedIDs.Add(id)
+	trans.touches.resources = append(trans.touches.resources, resourceKey{lang: lang, id: id})
 }
 
 // SetTextureProperties updates the properties of a specific texture.
@@ -64,6 +78,7 @@ func (trans *ModTransaction) SetTextureProperties(textureIndex int, properties t
 	trans.actions = append(trans.actions, func(modder Modder) {
 		modder.SetTextureProperties(textureIndex, properties)
 	})
+	trans.touches.textures = append(trans.touches.textures, textureIndex)
 }
 
 // SetObjectProperties updates the properties of a specific object.
@@ -71,4 +86,5 @@ func (trans *ModTransaction) SetObjectProperties(triple object.Triple, propertie
 	trans.actions = append(trans.actions, func(modder Modder) {
 		modder.SetObjectProperties(triple, properties)
 	})
+	trans.touches.objects = append(trans.touches.objects, triple)
 }
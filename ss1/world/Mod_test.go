@@ -146,6 +146,163 @@ func (suite *ModSuite) TestModifiedBlocksCanBeRetrievedBulk() {
 	assert.Equal(suite.T(), [][]byte{{0xBB}, {0xCC}}, suite.mod.ModifiedBlocks(resource.LangAny, 0x0800))
 }
 
+func (suite *ModSuite) TestNewResourcesOfUnknownIDsUseWorldAsTemplate() {
+	worldResources := suite.someLocalizedResources(resource.LangAny, func(store *resource.Store) {
+		_ = store.Put(resource.ID(0x7F00), resource.Resource{
+			Properties: resource.Properties{ContentType: resource.Font, Compressed: true},
+			Blocks:     resource.BlocksFrom([][]byte{{0xAA}}),
+		})
+	})
+	worldResources.ID = "data/fonts.res"
+	suite.givenWorldHas(worldResources)
+	suite.whenModifyingBy(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangAny, 0x7F00, 0, []byte{0xBB})
+	})
+	res := suite.mod.ModifiedResource(resource.LangAny, 0x7F00)
+	require.NotNil(suite.T(), res, "resource expected")
+	assert.Equal(suite.T(), resource.Font, res.ContentType(), "content type should be taken from world")
+	assert.True(suite.T(), res.Compressed(), "compression should be taken from world")
+	assert.Equal(suite.T(), []string{"fonts.res"}, suite.mod.ModifiedFilenames(), "filename should be taken from world")
+}
+
+func (suite *ModSuite) TestNewResourcesOfUnknownIDsPreferParentLayersAsTemplate() {
+	worldResources := suite.someLocalizedResources(resource.LangAny, func(store *resource.Store) {
+		_ = store.Put(resource.ID(0x7F00), resource.Resource{
+			Properties: resource.Properties{ContentType: resource.Font},
+			Blocks:     resource.BlocksFrom([][]byte{{0xAA}}),
+		})
+	})
+	worldResources.ID = "data/fonts.res"
+	suite.givenWorldHas(worldResources)
+	layer := suite.aLayer("base", resource.LangAny, func(store *resource.Store) {
+		_ = store.Put(resource.ID(0x7F00), resource.Resource{
+			Properties: resource.Properties{ContentType: resource.Bitmap, Compressed: true},
+			Blocks:     resource.BlocksFrom([][]byte{{0xCC}}),
+		})
+	})
+	layer.LocalizedResources[0].Template = "extra.res"
+	suite.givenParentLayers(layer)
+	suite.whenModifyingBy(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangAny, 0x7F00, 0, []byte{0xBB})
+	})
+	res := suite.mod.ModifiedResource(resource.LangAny, 0x7F00)
+	require.NotNil(suite.T(), res, "resource expected")
+	assert.Equal(suite.T(), resource.Bitmap, res.ContentType(), "content type should be taken from layer")
+	assert.True(suite.T(), res.Compressed(), "compression should be taken from layer")
+	assert.Equal(suite.T(), []string{"extra.res"}, suite.mod.ModifiedFilenames(), "filename should be taken from layer")
+}
+
+func (suite *ModSuite) TestResourceKeysOfTypeListsResourcesOfAllSources() {
+	putFont := func(id int) func(*resource.Store) {
+		return func(store *resource.Store) {
+			_ = store.Put(resource.ID(id), resource.Resource{
+				Properties: resource.Properties{ContentType: resource.Font},
+				Blocks:     resource.BlocksFrom([][]byte{{0xAA}}),
+			})
+		}
+	}
+	suite.givenWorldHas(
+		suite.someLocalizedResources(resource.LangGerman, putFont(0x7F01), suite.storing(0x0800, [][]byte{{0xAA}})),
+		suite.someLocalizedResources(resource.LangAny, putFont(0x7F01)))
+	suite.givenModifiedBy(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangAny, 0x7F01, 0, []byte{0xBB})
+	})
+
+	keys := suite.mod.ResourceKeysOfType(resource.Font)
+
+	assert.Equal(suite.T(), []resource.Key{
+		resource.KeyOf(0x7F01, resource.LangGerman, 0),
+		resource.KeyOf(0x7F01, resource.LangAny, 0),
+	}, keys, "keys should be listed once")
+}
+
+func (suite *ModSuite) TestParentLayersOverlayTheWorld() {
+	suite.givenWorldHas(
+		suite.someLocalizedResources(resource.LangAny,
+			suite.storing(0x0800, [][]byte{{0xAA}})))
+	suite.givenParentLayers(
+		suite.aLayer("base", resource.LangAny, suite.storing(0x0800, [][]byte{{0xCC}})))
+	suite.thenResourceBlockShouldBe(resource.LangAny, 0x0800, 0, []byte{0xCC})
+}
+
+func (suite *ModSuite) TestHigherParentLayersOverlayLowerOnes() {
+	suite.givenParentLayers(
+		suite.aLayer("base", resource.LangAny, suite.storing(0x0800, [][]byte{{0xCC}})),
+		suite.aLayer("fixes", resource.LangAny, suite.storing(0x0800, [][]byte{{0xDD}})))
+	suite.thenResourceBlockShouldBe(resource.LangAny, 0x0800, 0, []byte{0xDD})
+}
+
+func (suite *ModSuite) TestModificationsOverlayParentLayers() {
+	suite.givenParentLayers(
+		suite.aLayer("base", resource.LangAny, suite.storing(0x0800, [][]byte{{0xCC}})))
+	suite.whenModifyingBy(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangAny, 0x0800, 0, []byte{0xBB})
+	})
+	suite.thenResourceBlockShouldBe(resource.LangAny, 0x0800, 0, []byte{0xBB})
+	view, layer := suite.mod.ModifiedResourceLayer(resource.LangAny, 0x0800)
+	assert.NotNil(suite.T(), view, "view expected")
+	assert.Nil(suite.T(), layer, "resource should be from the mod itself")
+}
+
+func (suite *ModSuite) TestModifiedResourceLayerReportsSupplyingParentLayer() {
+	suite.givenParentLayers(
+		suite.aLayer("base", resource.LangAny, suite.storing(0x0800, [][]byte{{0xCC}})),
+		suite.aLayer("fixes", resource.LangAny, suite.storing(0x0801, [][]byte{{0xDD}})))
+	view, layer := suite.mod.ModifiedResourceLayer(resource.LangAny, 0x0800)
+	assert.NotNil(suite.T(), view, "view expected")
+	require.NotNil(suite.T(), layer, "layer expected")
+	assert.Equal(suite.T(), "base", layer.Name)
+	assert.Nil(suite.T(), suite.mod.ModifiedResource(resource.LangAny, 0x0800), "mod itself should not have resource")
+
+	view, layer = suite.mod.ModifiedResourceLayer(resource.LangAny, 0x0802)
+	assert.Nil(suite.T(), view, "no view expected")
+	assert.Nil(suite.T(), layer, "no layer expected")
+}
+
+func (suite *ModSuite) TestSettingParentLayersIsNotified() {
+	suite.givenParentLayers(
+		suite.aLayer("base", resource.LangAny, suite.storing(0x0800, [][]byte{{0xCC}})))
+	suite.mod.SetParentLayers([]*world.ModLayer{
+		suite.aLayer("other", resource.LangAny, suite.storing(0x0801, [][]byte{{0xDD}}))})
+	suite.thenModifiedResourcesShouldBe(0x0800, 0x0801)
+}
+
+func (suite *ModSuite) TestResourceSourcesListsChainInOverrideOrder() {
+	suite.givenWorldHas(
+		suite.someLocalizedResources(resource.LangAny,
+			suite.storing(0x0800, [][]byte{{0xAA}})))
+	suite.givenWorldHas(
+		suite.someLocalizedResources(resource.LangGerman,
+			suite.storing(0x0800, [][]byte{{0xAB}})))
+	suite.givenParentLayers(
+		suite.aLayer("base", resource.LangAny, suite.storing(0x0800, [][]byte{{0xCC}})),
+		suite.aLayer("other", resource.LangAny, suite.storing(0x0801, [][]byte{{0xDD}})))
+	suite.givenModifiedBy(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangGerman, 0x0800, 0, []byte{0xBB})
+	})
+
+	sources := suite.mod.ResourceSources(resource.LangGerman, 0x0800)
+
+	require.Len(suite.T(), sources, 4, "four sources expected")
+	assert.Equal(suite.T(), "entry-0", sources[0].Entry.ID)
+	assert.Equal(suite.T(), "entry-1", sources[1].Entry.ID)
+	assert.Equal(suite.T(), resource.LangGerman, sources[1].Language)
+	require.NotNil(suite.T(), sources[2].Layer, "layer expected")
+	assert.Equal(suite.T(), []string{"base"}, sources[2].Origin)
+	assert.True(suite.T(), sources[3].IsMod(), "last source should be the mod")
+	assert.Equal(suite.T(), resource.LangGerman, sources[3].Language)
+}
+
+func (suite *ModSuite) TestResourceSourcesIgnoresOtherLanguages() {
+	suite.givenWorldHas(
+		suite.someLocalizedResources(resource.LangFrench,
+			suite.storing(0x0800, [][]byte{{0xAA}})))
+
+	sources := suite.mod.ResourceSources(resource.LangGerman, 0x0800)
+
+	assert.Empty(suite.T(), sources, "no sources expected")
+}
+
 func (suite *ModSuite) givenWorldHas(res ...resource.LocalizedResources) {
 	suite.whenWorldIsExtendedWith(res...)
 	suite.lastModifiedIDs = nil
@@ -233,3 +390,34 @@ func (suite *ModSuite) storing(id int, data [][]byte) func(*resource.Store) {
 		})
 	}
 }
+
+func (suite *ModSuite) TestMarkAllFilesChangedListsAllFiles() {
+	suite.givenModifiedBy(func(modder world.Modder) {
+		modder.SetResourceBlock(resource.LangAny, 0x0800, 0, []byte{0xBB})
+		modder.SetResourceBlock(resource.LangGerman, 0x0869, 0, []byte{0xCC})
+	})
+	suite.mod.MarkSave()
+
+	suite.mod.MarkAllFilesChanged()
+
+	filenames := suite.mod.ModifiedFilenames()
+	sort.Strings(filenames)
+	assert.Equal(suite.T(), []string{"gerstrng.res", "unknown.res"}, filenames)
+}
+
+func (suite *ModSuite) givenParentLayers(layers ...*world.ModLayer) {
+	suite.mod.SetParentLayers(layers)
+	suite.lastModifiedIDs = nil
+	suite.lastFailedIDs = nil
+}
+
+func (suite *ModSuite) aLayer(name string, lang resource.Language, modifiers ...func(*resource.Store)) *world.ModLayer {
+	localized := &world.LocalizedResources{Language: lang}
+	for _, modifier := range modifiers {
+		modifier(&localized.Store)
+	}
+	return &world.ModLayer{
+		Name:               name,
+		LocalizedResources: []*world.LocalizedResources{localized},
+	}
+}
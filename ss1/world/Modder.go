@@ -28,6 +28,9 @@ type Modder interface {
 	// This method is primarily meant for compound non-list resources (e.g. text pages).
 	SetResourceBlocks(lang resource.Language, id resource.ID, data [][]byte)
 
+	// SetResourceProperties changes the meta information of an existing resource.
+	SetResourceProperties(lang resource.Language, id resource.ID, properties resource.Properties)
+
 	// DelResource removes a resource from the mod in the given language.
 	//
 	// After the deletion, all the underlying data of the world will become visible again.
@@ -0,0 +1,96 @@
+package world
+
+import (
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// ResourceSource describes one place that defines a resource.
+// Sources are listed in override order: Later sources take precedence over earlier ones.
+type ResourceSource struct {
+	// Entry is the manifest entry of the world that defines the resource.
+	// It is nil if the source is a mod layer.
+	Entry *ManifestEntry
+	// Layer is the read-only parent layer that defines the resource.
+	// It is nil if the source is a manifest entry, or the mod itself.
+	Layer *ModLayer
+	// Filename is the name of the file within the entry or layer that contains the resource.
+	Filename string
+	// Language is the language of the file that contains the resource.
+	Language resource.Language
+	// Origin lists the filenames the source was loaded from.
+	// It is empty for the mod itself.
+	Origin []string
+	// View provides the resource as it is defined by this source alone.
+	View resource.View
+}
+
+// IsMod returns true if the source is the mod itself.
+func (source ResourceSource) IsMod() bool {
+	return (source.Entry == nil) && (source.Layer == nil)
+}
+
+// ResourceSources returns all manifest entries that define the resource with given identifier for given language.
+func (manifest Manifest) ResourceSources(lang resource.Language, id resource.ID) []ResourceSource {
+	var sources []ResourceSource
+	for _, entry := range manifest.entries {
+		for _, localized := range entry.Resources {
+			if !localized.Language.Includes(lang) {
+				continue
+			}
+			view, err := localized.Viewer.View(id)
+			if err != nil {
+				continue
+			}
+			sources = append(sources, ResourceSource{
+				Entry:    entry,
+				Filename: localized.ID,
+				Language: localized.Language,
+				Origin:   entry.Origin,
+				View:     view,
+			})
+		}
+	}
+	return sources
+}
+
+// ResourceSources returns the full chain of sources that define the resource with given identifier for given language.
+// The chain starts with the entries of the world, followed by the parent layers, and ends with the mod itself.
+// This is the same order in which the resources are combined by Filter().
+func (mod Mod) ResourceSources(lang resource.Language, id resource.ID) []ResourceSource {
+	sources := mod.worldManifest.ResourceSources(lang, id)
+	for _, layer := range mod.parentLayers {
+		sources = appendLayerSources(sources, layer, layer.LocalizedResources, lang, id)
+	}
+	return appendLayerSources(sources, nil, mod.data.LocalizedResources, lang, id)
+}
+
+func appendLayerSources(sources []ResourceSource, layer *ModLayer, list []*LocalizedResources,
+	lang resource.Language, id resource.ID) []ResourceSource {
+	add := func(entryLang resource.Language) {
+		for _, localized := range list {
+			if localized.Language != entryLang {
+				continue
+			}
+			res, err := localized.Store.Resource(id)
+			if err != nil {
+				continue
+			}
+			source := ResourceSource{
+				Layer:    layer,
+				Filename: localized.File.Name,
+				Language: localized.Language,
+				View:     res,
+			}
+			if layer != nil {
+				source.Origin = []string{layer.Name}
+			}
+			sources = append(sources, source)
+			return
+		}
+	}
+	add(resource.LangAny)
+	if lang != resource.LangAny {
+		add(lang)
+	}
+	return sources
+}
@@ -0,0 +1,37 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// Describe returns a short description of the meaning of the identified resource.
+// For level resources of the archive, it names the level and the level resource.
+// An empty string is returned for all other resources.
+func Describe(id resource.ID) string {
+	level, lvlID, isLevel := LevelResource(id)
+	if !isLevel {
+		return ""
+	}
+	return fmt.Sprintf("level %d %s", level, lvlids.Name(lvlID))
+}
+
+// LevelResource splits the given identifier into the level and the level-specific resource identifier (see lvlids).
+// The returned flag is false if the identifier does not refer to a level resource.
+// The first identifiers of the range, such as the archive name, are not considered level resources.
+func LevelResource(id resource.ID) (level int, lvlID int, isLevel bool) {
+	if id < ids.LevelResourcesStart {
+		return 0, 0, false
+	}
+	offset := int(id.Value() - ids.LevelResourcesStart.Value())
+	level = offset / lvlids.PerLevel
+	lvlID = offset % lvlids.PerLevel
+	if (level >= archive.MaxLevels) || (lvlID < lvlids.FirstUsed) {
+		return 0, 0, false
+	}
+	return level, lvlID, true
+}
@@ -0,0 +1,34 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/diff"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelResource(t *testing.T) {
+	tt := []struct {
+		id       resource.ID
+		isLevel  bool
+		level    int
+		lvlResID int
+	}{
+		{0x0FA1, false, 0, 0},
+		{4002, true, 0, 2},
+		{4099, true, 0, 99},
+		{4100, false, 0, 0},
+		{4105, true, 1, 5},
+		{5599, true, 15, 99},
+		{5600, false, 0, 0},
+	}
+
+	for _, tc := range tt {
+		level, lvlResID, isLevel := diff.LevelResource(tc.id)
+		assert.Equal(t, tc.isLevel, isLevel, "Wrong level flag for <"+tc.id.String()+">")
+		assert.Equal(t, tc.level, level, "Wrong level for <"+tc.id.String()+">")
+		assert.Equal(t, tc.lvlResID, lvlResID, "Wrong level resource for <"+tc.id.String()+">")
+	}
+}
@@ -0,0 +1,97 @@
+package diff
+
+import "github.com/inkyblackness/hacked/ss1/resource"
+
+// Report lists the differences between two sets of resources.
+type Report struct {
+	// Added lists the identifier of resources that only exist in the new set.
+	Added []resource.ID
+	// Removed lists the identifier of resources that only exist in the old set.
+	Removed []resource.ID
+	// Changed lists the resources that exist in both sets, yet differ.
+	Changed []ResourceChange
+}
+
+// IsEmpty returns true if the report contains no differences.
+func (report Report) IsEmpty() bool {
+	return (len(report.Added) == 0) && (len(report.Removed) == 0) && (len(report.Changed) == 0)
+}
+
+// ResourceChange describes how a resource differs.
+type ResourceChange struct {
+	// ID is the identifier of the resource.
+	ID resource.ID
+
+	// From are the properties of the old resource.
+	From resource.Properties
+	// To are the properties of the new resource.
+	To resource.Properties
+
+	// FromBlockCount is the number of blocks of the old resource.
+	FromBlockCount int
+	// ToBlockCount is the number of blocks of the new resource.
+	ToBlockCount int
+
+	// Blocks lists the changed blocks, in ascending order of their index.
+	Blocks []BlockChange
+}
+
+// CompoundChanged returns true if the resource changed between simple and compound.
+func (change ResourceChange) CompoundChanged() bool {
+	return change.From.Compound != change.To.Compound
+}
+
+// ContentTypeChanged returns true if the content type of the resource differs.
+func (change ResourceChange) ContentTypeChanged() bool {
+	return change.From.ContentType != change.To.ContentType
+}
+
+// CompressionChanged returns true if the compression flag of the resource differs.
+func (change ResourceChange) CompressionChanged() bool {
+	return change.From.Compressed != change.To.Compressed
+}
+
+// BlockChange describes how a block of a resource differs.
+type BlockChange struct {
+	// Index is the index of the block within the resource.
+	Index int
+	// FromLength is the length of the old block, or -1 if the block was added.
+	FromLength int
+	// ToLength is the length of the new block, or -1 if the block was removed.
+	ToLength int
+	// Ranges lists the ranges of differing bytes, in ascending order.
+	// For added or removed blocks, the range covers the whole block.
+	Ranges []ByteRange
+}
+
+// Added returns true if the block only exists in the new resource.
+func (change BlockChange) Added() bool {
+	return change.FromLength < 0
+}
+
+// Removed returns true if the block only exists in the old resource.
+func (change BlockChange) Removed() bool {
+	return change.ToLength < 0
+}
+
+// DifferingBytes returns the total amount of bytes covered by the ranges.
+func (change BlockChange) DifferingBytes() int {
+	total := 0
+	for _, r := range change.Ranges {
+		total += r.Length()
+	}
+	return total
+}
+
+// ByteRange describes a range of bytes within a block.
+type ByteRange struct {
+	// Start is the offset of the first byte of the range (inclusive).
+	Start int
+	// End is the offset after the last byte of the range (exclusive).
+	End int
+}
+
+// Length returns the amount of bytes in the range.
+func (r ByteRange) Length() int {
+	return r.End - r.Start
+}
@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// rangeMergeGap is the amount of equal bytes that may separate two ranges that are still reported as one.
+// This keeps the reports of scattered changes, such as in tables, short.
+const rangeMergeGap = 4
+
+// Resources compares the resources of the two viewers.
+// Resources are reported in ascending order of their identifier.
+func Resources(from, to resource.Viewer) (Report, error) {
+	var report Report
+	fromIDs := idSet(from.IDs())
+	toIDs := idSet(to.IDs())
+	for _, id := range sortedIDs(fromIDs, toIDs) {
+		switch {
+		case !toIDs[id]:
+			report.Removed = append(report.Removed, id)
+		case !fromIDs[id]:
+			report.Added = append(report.Added, id)
+		default:
+			change, changed, err := compareResource(id, from, to)
+			if err != nil {
+				return Report{}, err
+			}
+			if changed {
+				report.Changed = append(report.Changed, change)
+			}
+		}
+	}
+	return report, nil
+}
+
+func idSet(ids []resource.ID) map[resource.ID]bool {
+	set := make(map[resource.ID]bool)
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func sortedIDs(sets ...map[resource.ID]bool) []resource.ID {
+	unique := make(map[resource.ID]bool)
+	for _, set := range sets {
+		for id := range set {
+			unique[id] = true
+		}
+	}
+	list := make([]resource.ID, 0, len(unique))
+	for id := range unique {
+		list = append(list, id)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a] < list[b] })
+	return list
+}
+
+func compareResource(id resource.ID, from, to resource.Viewer) (ResourceChange, bool, error) {
+	change := ResourceChange{ID: id}
+	fromView, err := from.View(id)
+	if err != nil {
+		return change, false, err
+	}
+	toView, err := to.View(id)
+	if err != nil {
+		return change, false, err
+	}
+	change.From = propertiesOf(fromView)
+	change.To = propertiesOf(toView)
+	change.FromBlockCount = fromView.BlockCount()
+	change.ToBlockCount = toView.BlockCount()
+
+	maxBlocks := change.FromBlockCount
+	if change.ToBlockCount > maxBlocks {
+		maxBlocks = change.ToBlockCount
+	}
+	for index := 0; index < maxBlocks; index++ {
+		fromData, err := blockData(fromView, index)
+		if err != nil {
+			return change, false, err
+		}
+		toData, err := blockData(toView, index)
+		if err != nil {
+			return change, false, err
+		}
+		if blockChange, changed := compareBlock(index, fromData, toData); changed {
+			change.Blocks = append(change.Blocks, blockChange)
+		}
+	}
+	changed := (change.From != change.To) || (len(change.Blocks) > 0)
+	return change, changed, nil
+}
+
+func propertiesOf(view resource.View) resource.Properties {
+	return resource.Properties{
+		Compound:    view.Compound(),
+		ContentType: view.ContentType(),
+		Compressed:  view.Compressed(),
+	}
+}
+
+// blockData returns the data of the identified block, or nil if the block does not exist.
+func blockData(view resource.View, index int) ([]byte, error) {
+	if index >= view.BlockCount() {
+		return nil, nil
+	}
+	reader, err := view.Block(index)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+func compareBlock(index int, fromData, toData []byte) (BlockChange, bool) {
+	change := BlockChange{Index: index, FromLength: len(fromData), ToLength: len(toData)}
+	switch {
+	case fromData == nil:
+		change.FromLength = -1
+		change.Ranges = wholeRange(len(toData))
+	case toData == nil:
+		change.ToLength = -1
+		change.Ranges = wholeRange(len(fromData))
+	case bytes.Equal(fromData, toData):
+		return change, false
+	default:
+		change.Ranges = differingRanges(fromData, toData)
+	}
+	return change, true
+}
+
+func wholeRange(length int) []ByteRange {
+	if length == 0 {
+		return nil
+	}
+	return []ByteRange{{Start: 0, End: length}}
+}
+
+func differingRanges(fromData, toData []byte) []ByteRange {
+	var ranges []ByteRange
+	add := func(r ByteRange) {
+		last := len(ranges) - 1
+		if (last >= 0) && (r.Start-ranges[last].End < rangeMergeGap) {
+			ranges[last].End = r.End
+		} else {
+			ranges = append(ranges, r)
+		}
+	}
+	commonLength := len(fromData)
+	maxLength := len(toData)
+	if commonLength > maxLength {
+		commonLength, maxLength = maxLength, commonLength
+	}
+	start := -1
+	for offset := 0; offset < commonLength; offset++ {
+		equal := fromData[offset] == toData[offset]
+		if !equal && (start < 0) {
+			start = offset
+		} else if equal && (start >= 0) {
+			add(ByteRange{Start: start, End: offset})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		add(ByteRange{Start: start, End: commonLength})
+	}
+	if maxLength > commonLength {
+		add(ByteRange{Start: commonLength, End: maxLength})
+	}
+	return ranges
+}
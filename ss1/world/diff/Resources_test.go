@@ -0,0 +1,102 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/diff"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcesReportsNothingForEqualResources(t *testing.T) {
+	from := aStore(map[resource.ID][][]byte{0x0100: {{0x01, 0x02}}})
+	to := aStore(map[resource.ID][][]byte{0x0100: {{0x01, 0x02}}})
+
+	report, err := diff.Resources(from, to)
+	require.Nil(t, err, "no error expected")
+	assert.True(t, report.IsEmpty(), "no differences expected")
+}
+
+func TestResourcesReportsAddedAndRemovedResources(t *testing.T) {
+	from := aStore(map[resource.ID][][]byte{0x0200: {{0x01}}, 0x0100: {{0x01}}})
+	to := aStore(map[resource.ID][][]byte{0x0300: {{0x01}}, 0x0100: {{0x01}}, 0x0050: {{0x01}}})
+
+	report, err := diff.Resources(from, to)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, []resource.ID{0x0050, 0x0300}, report.Added, "added mismatch")
+	assert.Equal(t, []resource.ID{0x0200}, report.Removed, "removed mismatch")
+	assert.Empty(t, report.Changed, "no changes expected")
+}
+
+func TestResourcesReportsChangedProperties(t *testing.T) {
+	from := aStore(map[resource.ID][][]byte{0x0100: {{0x01}}})
+	to := resource.Store{}
+	_ = to.Put(0x0100, resource.Resource{
+		Properties: resource.Properties{Compound: true, Compressed: true, ContentType: resource.Text},
+		Blocks:     resource.BlocksFrom([][]byte{{0x01}}),
+	})
+
+	report, err := diff.Resources(from, to)
+	require.Nil(t, err, "no error expected")
+	require.Len(t, report.Changed, 1, "one change expected")
+	change := report.Changed[0]
+	assert.True(t, change.CompressionChanged(), "compression should be changed")
+	assert.True(t, change.ContentTypeChanged(), "content type should be changed")
+	assert.False(t, change.CompoundChanged(), "compound should not be changed")
+	assert.Empty(t, change.Blocks, "no block changes expected")
+}
+
+func TestResourcesReportsChangedBlocks(t *testing.T) {
+	from := aStore(map[resource.ID][][]byte{0x0100: {{0x00, 0x00}, {0x01, 0x02, 0x03}, {0x05}}})
+	to := aStore(map[resource.ID][][]byte{0x0100: {{0x00, 0x00}, {0x01, 0xFF, 0x03, 0x04}}})
+
+	report, err := diff.Resources(from, to)
+	require.Nil(t, err, "no error expected")
+	require.Len(t, report.Changed, 1, "one change expected")
+	change := report.Changed[0]
+	assert.Equal(t, 3, change.FromBlockCount)
+	assert.Equal(t, 2, change.ToBlockCount)
+	assert.Equal(t, []diff.BlockChange{
+		{Index: 1, FromLength: 3, ToLength: 4, Ranges: []diff.ByteRange{{Start: 1, End: 4}}},
+		{Index: 2, FromLength: 1, ToLength: -1, Ranges: []diff.ByteRange{{Start: 0, End: 1}}},
+	}, change.Blocks)
+	assert.True(t, change.Blocks[1].Removed(), "last block should be removed")
+}
+
+func TestResourcesReportsSeparateRangesForDistantChanges(t *testing.T) {
+	fromData := make([]byte, 32)
+	toData := make([]byte, 32)
+	toData[2] = 0x01
+	toData[3] = 0x01
+	toData[20] = 0x01
+	from := aStore(map[resource.ID][][]byte{0x0100: {fromData}})
+	to := aStore(map[resource.ID][][]byte{0x0100: {toData}})
+
+	report, err := diff.Resources(from, to)
+	require.Nil(t, err, "no error expected")
+	require.Len(t, report.Changed, 1, "one change expected")
+	require.Len(t, report.Changed[0].Blocks, 1, "one block change expected")
+	block := report.Changed[0].Blocks[0]
+	assert.Equal(t, []diff.ByteRange{{Start: 2, End: 4}, {Start: 20, End: 21}}, block.Ranges)
+	assert.Equal(t, 3, block.DifferingBytes())
+}
+
+func TestDescribeNamesLevelResources(t *testing.T) {
+	assert.Equal(t, "level 1 TileMap", diff.Describe(4105))
+	assert.Equal(t, "level 0 ObjectClassTable3", diff.Describe(4013))
+	assert.Equal(t, "", diff.Describe(0x0FA0))
+	assert.Equal(t, "", diff.Describe(0x0100))
+}
+
+func aStore(resources map[resource.ID][][]byte) resource.Store {
+	var store resource.Store
+	for id, blocks := range resources {
+		_ = store.Put(id, resource.Resource{
+			Properties: resource.Properties{Compound: true},
+			Blocks:     resource.BlocksFrom(blocks),
+		})
+	}
+	return store
+}
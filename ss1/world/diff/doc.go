@@ -0,0 +1,4 @@
+// Package diff compares resources and reports their differences.
+//
+// This can be used to determine what a mod changed in relation to the original data.
+package diff
@@ -45,6 +45,12 @@ const (
 	MovieEnd   resource.ID = 0x0BD8
 )
 
+// Font identifier are listed below.
+const (
+	CitadelFont  resource.ID = 0x025A
+	TinyTechFont resource.ID = 0x025C
+)
+
 // Text identifier are listed below.
 const (
 	PaperTextsStart      resource.ID = 0x003C
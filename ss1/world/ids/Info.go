@@ -36,6 +36,18 @@ func Info(id resource.ID) (ResourceInfo, bool) {
 	return info, existing
 }
 
+// InfosOf returns the groups of resources that contain given content type and are stored in given file.
+// A nil file matches any file. The resources of archive levels are not considered.
+func InfosOf(contentType resource.ContentType, file Filename) []ResourceInfo {
+	var result []ResourceInfo
+	for _, info := range infoList {
+		if (info.ContentType == contentType) && ((file == nil) || (info.ResFile == file)) {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
 func init() {
 	register := func(info ResourceInfo) {
 		count := info.EndID.Value() - info.StartID.Value()
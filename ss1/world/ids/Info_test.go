@@ -0,0 +1,31 @@
+package ids_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfosOf(t *testing.T) {
+	tt := []struct {
+		contentType resource.ContentType
+		file        ids.Filename
+		expected    []resource.ID
+	}{
+		{resource.Sound, nil, []resource.ID{ids.SoundEffectsAudioStart}},
+		{resource.Movie, ids.CitALog, []resource.ID{ids.MailsAudioStart, ids.LogsAudioStart}},
+		{resource.Bitmap, ids.CitMat, []resource.ID{ids.ObjectTextureBitmaps, ids.ObjectMaterialBitmaps}},
+		{resource.Palette, ids.CitMat, nil},
+	}
+
+	for _, tc := range tt {
+		var startIDs []resource.ID
+		for _, info := range ids.InfosOf(tc.contentType, tc.file) {
+			startIDs = append(startIDs, info.StartID)
+		}
+		assert.Equal(t, tc.expected, startIDs, "Wrong infos for <"+tc.contentType.String()+">")
+	}
+}
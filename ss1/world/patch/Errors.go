@@ -0,0 +1,34 @@
+package patch
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1"
+)
+
+const (
+	errSourceMismatch   ss1.StringError = "source file does not match the patch"
+	errTargetMismatch   ss1.StringError = "patched file does not match the patch"
+	errUnknownFormat    ss1.StringError = "unknown patch format"
+	errLengthOutOfRange ss1.StringError = "length out of range"
+)
+
+// FileError describes a problem with a specific file of a patch.
+type FileError struct {
+	Name string
+	Err  error
+}
+
+// Error implements the error interface.
+func (err FileError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Name, err.Err)
+}
+
+// Unwrap returns the underlying error.
+func (err FileError) Unwrap() error {
+	return err.Err
+}
+
+func fileError(name string, err error) error {
+	return FileError{Name: name, Err: err}
+}
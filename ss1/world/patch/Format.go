@@ -0,0 +1,171 @@
+package patch
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// formatMagic identifies serialized patches.
+var formatMagic = [4]byte{'H', 'P', 'A', 'T'}
+
+const (
+	formatVersion = 2
+
+	// maxDataLength limits the length of data fields while decoding.
+	// Resource files can not store blocks larger than this either.
+	maxDataLength = 0x00FFFFFF
+
+	propertyCompound   = 0x01
+	propertyCompressed = 0x02
+)
+
+// Encode writes the patch in its serialized form.
+func (patch Patch) Encode(writer io.Writer) error {
+	enc := encoder{writer: writer}
+	enc.write(formatMagic)
+	enc.write(uint16(formatVersion))
+	enc.write(uint16(len(patch.Files)))
+	for _, file := range patch.Files {
+		enc.writeData([]byte(file.Name))
+		enc.write(file.SourceHash)
+		enc.write(file.TargetHash)
+		enc.write(uint32(len(file.Resources)))
+		for _, res := range file.Resources {
+			enc.write(uint16(res.ID))
+			enc.writeProperties(res.Properties)
+			enc.write(uint16(len(res.Blocks)))
+			for _, block := range res.Blocks {
+				enc.write(uint32(block.TargetLength))
+				enc.writeData(block.Forward)
+			}
+		}
+	}
+	return enc.err
+}
+
+// Decode reads a patch from its serialized form, as written by Encode().
+func Decode(reader io.Reader) (Patch, error) {
+	dec := decoder{reader: reader}
+	var magic [4]byte
+	var version uint16
+	dec.read(&magic)
+	dec.read(&version)
+	if dec.err != nil {
+		return Patch{}, dec.err
+	}
+	if (magic != formatMagic) || (version != formatVersion) {
+		return Patch{}, errUnknownFormat
+	}
+	var patch Patch
+	patch.Files = make([]File, dec.readUint16())
+	for fileIndex := range patch.Files {
+		file := &patch.Files[fileIndex]
+		file.Name = string(dec.readData())
+		dec.read(&file.SourceHash)
+		dec.read(&file.TargetHash)
+		resourceCount := int(dec.readUint32())
+		for resIndex := 0; (resIndex < resourceCount) && (dec.err == nil); resIndex++ {
+			var res Resource
+			res.ID = resource.ID(dec.readUint16())
+			res.Properties = dec.readProperties()
+			res.Blocks = make([]Block, dec.readUint16())
+			for blockIndex := range res.Blocks {
+				block := &res.Blocks[blockIndex]
+				block.TargetLength = int(dec.readUint32())
+				if block.TargetLength > maxDataLength {
+					dec.err = errLengthOutOfRange
+				}
+				block.Forward = dec.readData()
+			}
+			file.Resources = append(file.Resources, res)
+		}
+		if dec.err != nil {
+			return Patch{}, dec.err
+		}
+	}
+	if dec.err != nil {
+		return Patch{}, dec.err
+	}
+	return patch, nil
+}
+
+type encoder struct {
+	writer io.Writer
+	err    error
+}
+
+func (enc *encoder) write(value interface{}) {
+	if enc.err != nil {
+		return
+	}
+	enc.err = binary.Write(enc.writer, binary.LittleEndian, value)
+}
+
+func (enc *encoder) writeData(data []byte) {
+	enc.write(uint32(len(data)))
+	enc.write(data)
+}
+
+func (enc *encoder) writeProperties(properties resource.Properties) {
+	var flags byte
+	if properties.Compound {
+		flags |= propertyCompound
+	}
+	if properties.Compressed {
+		flags |= propertyCompressed
+	}
+	enc.write(flags)
+	enc.write(byte(properties.ContentType))
+}
+
+type decoder struct {
+	reader io.Reader
+	err    error
+}
+
+func (dec *decoder) read(value interface{}) {
+	if dec.err != nil {
+		return
+	}
+	dec.err = binary.Read(dec.reader, binary.LittleEndian, value)
+}
+
+func (dec *decoder) readUint16() uint16 {
+	var value uint16
+	dec.read(&value)
+	return value
+}
+
+func (dec *decoder) readUint32() uint32 {
+	var value uint32
+	dec.read(&value)
+	return value
+}
+
+func (dec *decoder) readData() []byte {
+	length := dec.readUint32()
+	if dec.err != nil {
+		return nil
+	}
+	if length > maxDataLength {
+		dec.err = errLengthOutOfRange
+		return nil
+	}
+	data := make([]byte, length)
+	dec.read(data)
+	return data
+}
+
+func (dec *decoder) readProperties() resource.Properties {
+	var flags byte
+	var contentType byte
+	dec.read(&flags)
+	dec.read(&contentType)
+	return resource.Properties{
+		Compound:    (flags & propertyCompound) != 0,
+		Compressed:  (flags & propertyCompressed) != 0,
+		ContentType: resource.ContentType(contentType),
+	}
+}
@@ -0,0 +1,60 @@
+package patch
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// Hash identifies the content of a resource file.
+type Hash [sha256.Size]byte
+
+// String returns the hexadecimal representation of the hash.
+func (hash Hash) String() string {
+	return fmt.Sprintf("%x", [sha256.Size]byte(hash))
+}
+
+// IsZero returns true for the hash of no file.
+func (hash Hash) IsZero() bool {
+	return hash == Hash{}
+}
+
+// HashOf calculates the hash of the resources in given viewer.
+// The hash covers the identifiers, properties, and blocks of all resources. It does not depend on the
+// serialized form of the resources, so a repacked file has the same hash.
+func HashOf(viewer resource.Viewer) (Hash, error) {
+	hasher := sha256.New()
+	ids := viewer.IDs()
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	for _, id := range ids {
+		view, err := viewer.View(id)
+		if err != nil {
+			return Hash{}, err
+		}
+		header := []interface{}{
+			uint16(id), view.Compound(), view.ContentType(), view.Compressed(), uint32(view.BlockCount()),
+		}
+		for _, value := range header {
+			_ = binary.Write(hasher, binary.LittleEndian, value)
+		}
+		for index := 0; index < view.BlockCount(); index++ {
+			reader, err := view.Block(index)
+			if err != nil {
+				return Hash{}, err
+			}
+			written, err := io.Copy(ioutil.Discard, io.TeeReader(reader, hasher))
+			if err != nil {
+				return Hash{}, err
+			}
+			_ = binary.Write(hasher, binary.LittleEndian, written)
+		}
+	}
+	var hash Hash
+	copy(hash[:], hasher.Sum(nil))
+	return hash, nil
+}
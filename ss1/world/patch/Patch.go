@@ -0,0 +1,270 @@
+package patch
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/serial/rle"
+)
+
+// Files maps the names of resource files to their content.
+// Names are the plain filenames, such as "archive.dat", in lower case.
+type Files map[string]resource.Viewer
+
+// Patch captures the resources of a mod as deltas against the resources of a known world.
+type Patch struct {
+	Files []File
+}
+
+// File contains the deltas for the resources of one file.
+type File struct {
+	// Name is the plain filename, in lower case.
+	Name string
+	// SourceHash identifies the file of the world the deltas are based on.
+	// It is zero if the world has no such file.
+	SourceHash Hash
+	// TargetHash identifies the file of the mod the deltas produce.
+	TargetHash Hash
+
+	Resources []Resource
+}
+
+// Resource contains the deltas of all the blocks of one resource.
+type Resource struct {
+	ID         resource.ID
+	Properties resource.Properties
+
+	Blocks []Block
+}
+
+// Block contains the delta for one block.
+// The delta is created with the rle package, using the block of the world as reference.
+type Block struct {
+	// TargetLength is the length of the block in the mod.
+	TargetLength int
+
+	// Forward is the delta to create the block of the mod from the block of the world.
+	Forward []byte
+}
+
+// Create produces a patch that contains the resources of the mod as deltas against the sources.
+// Resources of the mod that do not exist in the sources are fully contained in the patch.
+func Create(sources Files, mod Files) (Patch, error) {
+	var patch Patch
+	for _, name := range sortedNames(mod) {
+		target := mod[name]
+		file := File{Name: name}
+		source := sources[name]
+		var err error
+		if source != nil {
+			file.SourceHash, err = HashOf(source)
+			if err != nil {
+				return Patch{}, err
+			}
+		}
+		file.TargetHash, err = HashOf(target)
+		if err != nil {
+			return Patch{}, err
+		}
+		for _, id := range sortedIDs(target) {
+			res, err := createResource(id, source, target)
+			if err != nil {
+				return Patch{}, err
+			}
+			file.Resources = append(file.Resources, res)
+		}
+		patch.Files = append(patch.Files, file)
+	}
+	return patch, nil
+}
+
+func createResource(id resource.ID, source, target resource.Viewer) (Resource, error) {
+	targetView, err := target.View(id)
+	if err != nil {
+		return Resource{}, err
+	}
+	targetBlocks, err := blocksOf(targetView)
+	if err != nil {
+		return Resource{}, err
+	}
+	sourceBlocks, err := optionalBlocksOf(source, id)
+	if err != nil {
+		return Resource{}, err
+	}
+	res := Resource{
+		ID:         id,
+		Properties: propertiesOf(targetView),
+	}
+	for index, targetData := range targetBlocks {
+		var sourceData []byte
+		if index < len(sourceBlocks) {
+			sourceData = sourceBlocks[index]
+		}
+		forward := bytes.NewBuffer(nil)
+		err = rle.CompressOptimal(forward, targetData, sourceData)
+		if err != nil {
+			return Resource{}, err
+		}
+		res.Blocks = append(res.Blocks, Block{
+			TargetLength: len(targetData),
+			Forward:      forward.Bytes(),
+		})
+	}
+	return res, nil
+}
+
+// Apply creates the files of the mod from the given sources.
+// Each source file must match the one the patch was created for, and so must each created file match
+// the one of the mod. The returned files contain only the resources of the mod.
+func (patch Patch) Apply(sources Files) (map[string]*resource.Store, error) {
+	result := make(map[string]*resource.Store)
+	for _, file := range patch.Files {
+		source := sources[file.Name]
+		err := verifyHash(source, file.SourceHash, errSourceMismatch)
+		if err != nil {
+			return nil, fileError(file.Name, err)
+		}
+		store := &resource.Store{}
+		for _, res := range file.Resources {
+			sourceBlocks, err := optionalBlocksOf(source, res.ID)
+			if err != nil {
+				return nil, fileError(file.Name, err)
+			}
+			blocks := make([][]byte, len(res.Blocks))
+			for index, block := range res.Blocks {
+				var sourceData []byte
+				if index < len(sourceBlocks) {
+					sourceData = sourceBlocks[index]
+				}
+				blocks[index], err = applyDelta(block.Forward, sourceData, block.TargetLength)
+				if err != nil {
+					return nil, fileError(file.Name, err)
+				}
+			}
+			_ = store.Put(res.ID, resource.Resource{
+				Properties: res.Properties,
+				Blocks:     resource.BlocksFrom(blocks),
+			})
+		}
+		err = verifyHash(store, file.TargetHash, errTargetMismatch)
+		if err != nil {
+			return nil, fileError(file.Name, err)
+		}
+		result[file.Name] = store
+	}
+	return result, nil
+}
+
+// Revert returns the resources of the world that the mod replaces, as they were before it was applied.
+// The patch contains no original data, so the resources are taken from the given sources.
+// Each source file must match the one the patch was created for, and so must each file of the mod,
+// as created by Apply(). Resources that the mod added are not contained.
+func (patch Patch) Revert(sources Files, mod Files) (map[string]*resource.Store, error) {
+	result := make(map[string]*resource.Store)
+	for _, file := range patch.Files {
+		err := verifyHash(mod[file.Name], file.TargetHash, errTargetMismatch)
+		if err != nil {
+			return nil, fileError(file.Name, err)
+		}
+		source := sources[file.Name]
+		err = verifyHash(source, file.SourceHash, errSourceMismatch)
+		if err != nil {
+			return nil, fileError(file.Name, err)
+		}
+		if source == nil {
+			continue
+		}
+		store := &resource.Store{}
+		for _, res := range file.Resources {
+			sourceView, err := source.View(res.ID)
+			if err != nil {
+				continue
+			}
+			blocks, err := blocksOf(sourceView)
+			if err != nil {
+				return nil, fileError(file.Name, err)
+			}
+			_ = store.Put(res.ID, resource.Resource{
+				Properties: propertiesOf(sourceView),
+				Blocks:     resource.BlocksFrom(blocks),
+			})
+		}
+		result[file.Name] = store
+	}
+	return result, nil
+}
+
+func propertiesOf(view resource.View) resource.Properties {
+	return resource.Properties{
+		Compound:    view.Compound(),
+		ContentType: view.ContentType(),
+		Compressed:  view.Compressed(),
+	}
+}
+
+func applyDelta(delta []byte, reference []byte, length int) ([]byte, error) {
+	data := make([]byte, length)
+	copy(data, reference)
+	err := rle.Decompress(bytes.NewReader(delta), data)
+	return data, err
+}
+
+func verifyHash(viewer resource.Viewer, expected Hash, mismatch error) error {
+	if viewer == nil {
+		if expected.IsZero() {
+			return nil
+		}
+		return mismatch
+	}
+	hash, err := HashOf(viewer)
+	if err != nil {
+		return err
+	}
+	if hash != expected {
+		return mismatch
+	}
+	return nil
+}
+
+func optionalBlocksOf(viewer resource.Viewer, id resource.ID) ([][]byte, error) {
+	if viewer == nil {
+		return nil, nil
+	}
+	view, err := viewer.View(id)
+	if err != nil {
+		return nil, nil
+	}
+	return blocksOf(view)
+}
+
+func blocksOf(view resource.View) ([][]byte, error) {
+	blocks := make([][]byte, view.BlockCount())
+	for index := range blocks {
+		reader, err := view.Block(index)
+		if err != nil {
+			return nil, err
+		}
+		blocks[index], err = ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+func sortedNames(files Files) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedIDs(viewer resource.Viewer) []resource.ID {
+	ids := viewer.IDs()
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
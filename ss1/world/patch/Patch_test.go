@@ -0,0 +1,189 @@
+package patch_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/patch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCreatesModFromSources(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+	applied, err := created.Apply(sources)
+	require.Nil(t, err, "no error expected applying patch")
+
+	require.Contains(t, applied, "archive.dat")
+	assertSameResources(t, mod["archive.dat"], applied["archive.dat"])
+	require.Contains(t, applied, "cybstrng.res")
+	assertSameResources(t, mod["cybstrng.res"], applied["cybstrng.res"])
+}
+
+func TestRevertRestoresChangedSourceResources(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+	reverted, err := created.Revert(sources, mod)
+	require.Nil(t, err, "no error expected reverting patch")
+
+	require.Contains(t, reverted, "archive.dat")
+	assert.Equal(t, []resource.ID{0x0FA0, 0x0FA1}, sortedIDs(reverted["archive.dat"]), "only changed resources expected")
+	assertSameResource(t, sources["archive.dat"], reverted["archive.dat"], 0x0FA0)
+	assertSameResource(t, sources["archive.dat"], reverted["archive.dat"], 0x0FA1)
+	assert.NotContains(t, reverted, "cybstrng.res", "files without source should not be reverted")
+}
+
+func TestRevertFailsForOtherSources(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+
+	otherSources := patch.Files{"archive.dat": aStore(0x0FA0, []byte("other data"))}
+	_, err = created.Revert(otherSources, mod)
+
+	var fileErr patch.FileError
+	require.True(t, errors.As(err, &fileErr), "file error expected")
+	assert.Equal(t, "archive.dat", fileErr.Name)
+}
+
+func TestRevertFailsForOtherMod(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+
+	otherMod := patch.Files{"archive.dat": aStore(0x0FA0, []byte("other data")), "cybstrng.res": mod["cybstrng.res"]}
+	_, err = created.Revert(sources, otherMod)
+
+	var fileErr patch.FileError
+	require.True(t, errors.As(err, &fileErr), "file error expected")
+	assert.Equal(t, "archive.dat", fileErr.Name)
+}
+
+func TestPatchDoesNotContainUnchangedData(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+
+	buffer := bytes.NewBuffer(nil)
+	err = created.Encode(buffer)
+	require.Nil(t, err, "no error expected encoding patch")
+	assert.False(t, bytes.Contains(buffer.Bytes(), []byte("original secret data")), "source data should not be in patch")
+	assert.False(t, bytes.Contains(buffer.Bytes(), []byte("replaced secret")), "replaced data should not be in patch")
+}
+
+func TestApplyFailsForOtherSources(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+
+	otherSources := patch.Files{"archive.dat": aStore(0x0FA0, []byte("other data"))}
+	_, err = created.Apply(otherSources)
+
+	var fileErr patch.FileError
+	require.True(t, errors.As(err, &fileErr), "file error expected")
+	assert.Equal(t, "archive.dat", fileErr.Name)
+}
+
+func TestApplyFailsForDamagedPatch(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+
+	created.Files[0].Resources[0].Properties.ContentType++
+	_, err = created.Apply(sources)
+
+	var fileErr patch.FileError
+	require.True(t, errors.As(err, &fileErr), "file error expected")
+	assert.Equal(t, created.Files[0].Name, fileErr.Name)
+}
+
+func TestEncodedPatchCanBeDecoded(t *testing.T) {
+	sources, mod := someSourcesAndMod()
+	created, err := patch.Create(sources, mod)
+	require.Nil(t, err, "no error expected creating patch")
+
+	buffer := bytes.NewBuffer(nil)
+	err = created.Encode(buffer)
+	require.Nil(t, err, "no error expected encoding patch")
+	decoded, err := patch.Decode(buffer)
+	require.Nil(t, err, "no error expected decoding patch")
+
+	assert.Equal(t, created, decoded)
+}
+
+func TestDecodeRejectsUnknownFormat(t *testing.T) {
+	_, err := patch.Decode(bytes.NewReader([]byte("PK\x03\x04\x00\x00\x00\x00")))
+	assert.Error(t, err, "error expected")
+}
+
+func someSourcesAndMod() (sources patch.Files, mod patch.Files) {
+	original := append([]byte("original secret data"), make([]byte, 100)...)
+	changed := make([]byte, len(original))
+	copy(changed, original)
+	changed[len(changed)-10] = 0xAA
+	sources = patch.Files{
+		"archive.dat": aStore(0x0FA0, original, 0x0FA1, []byte("replaced secret"), 0x0FA2, []byte{0x01, 0x02}),
+	}
+	mod = patch.Files{
+		"archive.dat":  aStore(0x0FA0, changed, 0x0FA1, []byte("new")),
+		"cybstrng.res": aStore(0x0869, []byte("new text\x00")),
+	}
+	return
+}
+
+func aStore(args ...interface{}) *resource.Store {
+	store := &resource.Store{}
+	for index := 0; index < len(args); index += 2 {
+		_ = store.Put(resource.ID(args[index].(int)), resource.Resource{
+			Properties: resource.Properties{ContentType: resource.Archive},
+			Blocks:     resource.BlocksFrom([][]byte{args[index+1].([]byte)}),
+		})
+	}
+	return store
+}
+
+func sortedIDs(viewer resource.Viewer) []resource.ID {
+	ids := viewer.IDs()
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
+
+func assertSameResources(t *testing.T, expected resource.Viewer, actual resource.Viewer) {
+	t.Helper()
+	assert.Equal(t, expected.IDs(), actual.IDs(), "IDs differ")
+	for _, id := range expected.IDs() {
+		assertSameResource(t, expected, actual, id)
+	}
+}
+
+func assertSameResource(t *testing.T, expected resource.Viewer, actual resource.Viewer, id resource.ID) {
+	t.Helper()
+	expectedView, err := expected.View(id)
+	require.Nil(t, err, "expected resource missing")
+	actualView, err := actual.View(id)
+	require.Nil(t, err, "actual resource missing")
+	require.Equal(t, expectedView.BlockCount(), actualView.BlockCount(), "block count differs for "+id.String())
+	for index := 0; index < expectedView.BlockCount(); index++ {
+		assert.Equal(t, blockData(t, expectedView, index), blockData(t, actualView, index), "block differs for "+id.String())
+	}
+}
+
+func blockData(t *testing.T, view resource.View, index int) []byte {
+	t.Helper()
+	reader, err := view.Block(index)
+	require.Nil(t, err, "no error expected for block")
+	data, err := ioutil.ReadAll(reader)
+	require.Nil(t, err, "no error expected reading block")
+	return data
+}
@@ -0,0 +1,9 @@
+// Package patch provides a distribution format for mods that contains only the changes
+// of a mod, as deltas against the resources of a known world.
+//
+// The source files of the world are identified by hashes of their content. This way, a patch
+// can only be applied to the exact files it was created for. The patch itself does not contain
+// any of the original data: it only has the deltas that create the resources of the mod from those
+// of the world. Reverting a mod therefore requires the original files as well, and takes the replaced
+// resources from them.
+package patch